@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/txross1993/superman-api/logging"
+)
+
+// logRequest logs each request once it is served with the logger of the
+// request's context. Server errors are logged at LevelError along with the
+// errors recorded while handling them
+func logRequest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		fields := []interface{}{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", status,
+			"duration", time.Since(start),
+			"bytes", c.Writer.Size(),
+			"remote", c.ClientIP(),
+		}
+		if key := client(c); key != nil {
+			fields = append(fields, "client", key.Name)
+		}
+
+		level := logging.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = logging.LevelError
+			if len(c.Errors) > 0 {
+				fields = append(fields, "error", c.Errors.String())
+			}
+		}
+
+		logging.FromContext(c.Request.Context()).Log(level, "request", fields...)
+	}
+}
+
+// recoverPanic answers a request whose handler panicked with an internal
+// error problem, logging the panic and its stack
+func recoverPanic() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				logging.FromContext(c.Request.Context()).Error("panic serving request", "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+				abortWithError(c, fmt.Errorf("panic: %v", r))
+			}
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+
+	"github.com/txross1993/superman-api/errors"
+	"github.com/txross1993/superman-api/logging"
+	"github.com/txross1993/superman-api/superman"
+)
+
+func TestAccessLog(t *testing.T) {
+	sqlDB, cleanup := newTestDB(t)
+	defer cleanup()
+
+	analyzer := superman.NewService(&fakeGeo{}, sqlDB)
+	valid := `{"username": "bob", "unix_timestamp": 1514764800, "event_uuid": "85ad929a-db03-4bf4-9541-8f728fa12e42", "ip_address": "206.81.252.200"}`
+
+	tests := map[string]struct {
+		service  *superman.Service
+		method   string
+		path     string
+		body     string
+		status   int
+		expected []map[string]interface{}
+		// logged is found in the error field of the access log line
+		logged string
+	}{
+		"analyzed": {
+			service: analyzer,
+			method:  "POST",
+			path:    "/v1/",
+			body:    valid,
+			status:  201,
+			expected: []map[string]interface{}{
+				{"level": "info", "msg": "request", "method": "POST", "path": "/v1/", "status": 201.0},
+			},
+		},
+		"rejected": {
+			service: analyzer,
+			method:  "POST",
+			path:    "/v1/",
+			body:    `{"username": "bob",`,
+			status:  400,
+			expected: []map[string]interface{}{
+				{"level": "info", "msg": "request", "status": 400.0},
+			},
+		},
+		"failed": {
+			service: superman.NewService(&failingGeo{}, sqlDB),
+			method:  "POST",
+			path:    "/v1/",
+			body:    valid,
+			status:  500,
+			expected: []map[string]interface{}{
+				{"level": "error", "msg": "analysis failed", "code": string(errors.CodeGeolocationFailed)},
+				{"level": "error", "msg": "request", "status": 500.0},
+			},
+			logged: "corrupt database",
+		},
+		"panicked": {
+			service: analyzer,
+			method:  "GET",
+			path:    "/panic",
+			status:  500,
+			expected: []map[string]interface{}{
+				{"level": "error", "msg": "panic serving request", "panic": "unexpected"},
+				{"level": "error", "msg": "request", "status": 500.0},
+			},
+			logged: "panic: unexpected",
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case %s", name)
+		var out bytes.Buffer
+		api := NewAPI(Config{
+			Superman: test.service,
+			Logger:   logging.New(&out, logging.LevelInfo),
+		})
+		api.router.GET("/panic", func(c *gin.Context) { panic("unexpected") })
+
+		req := newRequest(t, test.method, test.path, strings.NewReader(test.body))
+		req.Header.Set(requestIDHeader, "trace-"+name)
+		resp := makeRequest(api.router, req)
+		assert.Equal(t, test.status, resp.Code)
+
+		// every line of the request carries its id, and each expected line
+		// holds the expected fields
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Equal(t, len(test.expected), len(lines))
+		var fields map[string]interface{}
+		for i, line := range lines {
+			fields = nil
+			if err := json.Unmarshal([]byte(line), &fields); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, "trace-"+name, fields["request_id"])
+			if i < len(test.expected) {
+				for key, value := range test.expected[i] {
+					assert.Equal(t, value, fields[key])
+				}
+			}
+		}
+
+		// the access log line holds the errors of server side failures
+		logged, _ := fields["error"].(string)
+		assert.Equal(t, test.logged != "", logged != "")
+		assert.Equal(t, true, strings.Contains(logged, test.logged))
+	}
+}
@@ -1,31 +1,62 @@
 package api
 
 import (
+	"expvar"
+	"fmt"
+	"net"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/txross1993/superman-api/errors"
+	"github.com/txross1993/superman-api/logging"
 	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/stream"
 	"github.com/txross1993/superman-api/superman"
 )
 
-// Config holds the api configuration for the bind host and port and the
-// superman service
+// Config holds the api configuration for the bind host and port, the
+// superman service, the api key store used to authenticate clients, the
+// per client request limits, the webhook outbox whose dead letters are
+// exposed to administrators, the broker streaming live verdicts, the time
+// allowed to serve each request, the policy events are validated against,
+// which defaults to models.DefaultValidationPolicy, and the logger of
+// requests, which defaults to logging.Default
 type Config struct {
-	Host     string
-	Port     string
-	Superman *superman.Service
+	Host            string
+	Port            string
+	Superman        *superman.Service
+	Keys            keystore
+	RateLimit       RateLimit
+	MaxBodyBytes    int64
+	DeadLetters     deadLetters
+	Stream          *stream.Broker
+	StreamHeartbeat time.Duration
+	RequestTimeout  time.Duration
+	Validation      models.ValidationPolicy
+	Logger          *logging.Logger
 }
 
 // API configures the superman api
 type API struct {
 	Config
-	router *gin.Engine
+	router       *gin.Engine
+	spec         *OpenAPI
+	authFailures *rateLimiter
 }
 
 // NewAPI configures a new instance of the superman api
 func NewAPI(cfg Config) *API {
-	router := gin.Default()
+	if cfg.Logger == nil {
+		cfg.Logger = logging.Default()
+	}
+	if cfg.Validation == (models.ValidationPolicy{}) {
+		cfg.Validation = models.DefaultValidationPolicy()
+	}
+
+	router := gin.New()
+	router.Use(tagRequest(cfg.Logger), traceRequest(), logRequest(), recoverPanic())
 	api := &API{
 		Config: cfg,
 		router: router,
@@ -34,35 +65,80 @@ func NewAPI(cfg Config) *API {
 	return api
 }
 
-// Run starts the API server
+// Run starts the API server listening on the configured host and port
 func (api *API) Run() error {
-	return api.router.Run()
-
+	return api.router.Run(net.JoinHostPort(api.Host, api.Port))
 }
 
 // SetupRoutes declares the routes and handlers for the API server
 func (api *API) SetupRoutes() {
+	limit := api.rateLimit()
+	deadline := api.deadline()
+	api.authFailures = api.authFailureLimiter()
+
+	api.spec = api.openAPI()
+	api.router.GET("/openapi.json", api.GetOpenAPI)
+	api.router.GET("/metrics", api.authenticate(models.ScopeRead), limit, gin.WrapH(expvar.Handler()))
+
 	v1 := api.router.Group("/v1")
 	{
-		v1.POST("/", api.AnalyzeLoginEvent)
+		v1.POST("/", deadline, api.authenticate(models.ScopeIngest), limit, api.limitBody(), api.AnalyzeLoginEvent)
+		v1.GET("/users/:username/profile", deadline, api.authenticate(models.ScopeRead), limit, api.GetUserProfile)
+
+		if api.Stream != nil {
+			v1.GET("/stream", api.authenticate(models.ScopeRead), limit, api.StreamVerdicts)
+		}
+
+		if api.DeadLetters != nil {
+			v1.GET("/webhooks/dead-letters", deadline, api.authenticate(models.ScopeAdmin), limit, api.ListDeadLetters)
+			v1.POST("/webhooks/dead-letters/:id/retry", deadline, api.authenticate(models.ScopeAdmin), limit, api.RetryDeadLetter)
+		}
 	}
 }
 
-// AnalyzeLoginEvent binds the request to the expected format and hands
-// the request to the Superman service for analysis
+// AnalyzeLoginEvent binds the request to the expected format, validates it
+// and hands the request to the Superman service for analysis. Failures are reported
+// as problem details carrying a stable error code
 func (api *API) AnalyzeLoginEvent(c *gin.Context) {
 	var event models.UserIPAccessEvent
 	if err := c.ShouldBindJSON(&event); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		abortWithBindingError(c, err)
+		return
+	}
+	if err := api.Validation.Validate(&event, time.Now()); err != nil {
+		abortWithError(c, err)
 		return
 	}
 
-	resp, err := api.Superman.AnalyzeEvent(&event)
+	if key := client(c); key != nil {
+		event.Client = key.Name
+	}
+	event.Tenant = tenant(c)
+
+	resp, err := api.Superman.AnalyzeEvent(c.Request.Context(), &event)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		abortWithError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, resp)
 	return
 }
+
+// GetUserProfile reports the locations, countries, networks and login hours
+// usually seen for the username of the request's tenant
+func (api *API) GetUserProfile(c *gin.Context) {
+	username := c.Param("username")
+	profile, err := api.Superman.Profile(c.Request.Context(), tenant(c), username)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	if profile == nil {
+		abortWithCode(c, errors.CodeNotFound, fmt.Sprintf("no profile for username %q", username))
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
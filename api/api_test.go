@@ -2,14 +2,18 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/go-playground/assert/v2"
 	"github.com/txross1993/superman-api/db"
@@ -63,7 +67,7 @@ func TestAPISmokeTest(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	currentGeo, _ := geoSvc.GetCoordinatesFromIP(event.IPAddress)
+	currentGeo, _ := geoSvc.GetCoordinatesFromIP(context.Background(), event.IPAddress)
 	want := &models.Superman{
 		CurrentGeo: currentGeo,
 	}
@@ -72,6 +76,55 @@ func TestAPISmokeTest(t *testing.T) {
 	assert.Equal(t, got, want)
 }
 
+// TestRunAddress tests that the server listens on the configured host and
+// port
+func TestRunAddress(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+	lis.Close()
+
+	api := NewAPI(Config{Host: "127.0.0.1", Port: port})
+	go api.Run()
+
+	url := "http://" + net.JoinHostPort("127.0.0.1", port) + "/openapi.json"
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// newTestDB creates a database in a temporary directory, returning a func
+// closing the database and removing the directory
+func newTestDB(t *testing.T, opts ...db.Option) (db.DB, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "superman-api")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sqlDB, err := db.InitDB(path.Join(dir, "test.db"), opts...)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return sqlDB, func() {
+		sqlDB.Close()
+		os.RemoveAll(dir)
+	}
+}
+
 func newRequest(t *testing.T, method string, path string, body io.Reader) *http.Request {
 	t.Helper()
 	req, err := http.NewRequest(method, path, body)
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/txross1993/superman-api/auth"
+	"github.com/txross1993/superman-api/errors"
+	"github.com/txross1993/superman-api/models"
+)
+
+// clientKey is the gin context key holding the authenticated api key
+const clientKey = "superman.client"
+
+// tenantKey is the gin context key holding the tenant the request acts for
+const tenantKey = "superman.tenant"
+
+// tenantHeader selects the tenant a request acts for when its api key is
+// granted the cross tenant scope
+const tenantHeader = "X-Tenant-ID"
+
+type keystore interface {
+	FindAPIKeyByHash(string) (*models.APIKey, error)
+}
+
+// authenticate rejects requests which do not present an active api key
+// granting the provided scope, and records the tenant the request acts for.
+// Authentication is disabled when the api is configured without a keystore.
+// Each missing or invalid key spends a token of the source ip address's
+// bucket, and addresses which have exhausted it are rejected before their
+// key is looked up
+func (api *API) authenticate(scope models.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if api.Keys == nil {
+			if setTenant(c, nil) {
+				c.Next()
+			}
+			return
+		}
+
+		if api.authFailures != nil {
+			if delay := api.authFailures.wait("ip:"+c.ClientIP(), time.Now()); delay > 0 {
+				metrics.Add("rate_limited_auth_failures", 1)
+				abortRateLimited(c, delay)
+				return
+			}
+		}
+
+		plaintext := requestKey(c.Request)
+		if plaintext == "" {
+			api.authFailed(c)
+			c.Header("WWW-Authenticate", `Bearer realm="superman-api"`)
+			abortWithCode(c, errors.CodeUnauthorized, "missing api key")
+			return
+		}
+
+		key, err := api.Keys.FindAPIKeyByHash(auth.Hash(plaintext))
+		if err != nil {
+			abortWithError(c, &errors.StorageUnavailable{Op: "find api key", Err: err})
+			return
+		}
+
+		if key == nil || !key.Active(time.Now()) {
+			api.authFailed(c)
+			c.Header("WWW-Authenticate", `Bearer realm="superman-api", error="invalid_token"`)
+			abortWithCode(c, errors.CodeUnauthorized, "invalid api key")
+			return
+		}
+
+		if !key.HasScope(scope) {
+			abortWithCode(c, errors.CodeForbidden, "api key lacks the "+string(scope)+" scope")
+			return
+		}
+
+		c.Set(clientKey, key)
+		if setTenant(c, key) {
+			c.Next()
+		}
+	}
+}
+
+// authFailed spends a token of the request's source ip address bucket of
+// failed authentications
+func (api *API) authFailed(c *gin.Context) {
+	if api.authFailures != nil {
+		api.authFailures.reserve("ip:"+c.ClientIP(), time.Now())
+	}
+}
+
+// setTenant records the tenant the request acts for: the tenant the key is
+// bound to, or else the tenant named by the tenant header. It rejects a
+// tenant the key may not act for and reports whether the request may
+// proceed
+func setTenant(c *gin.Context, key *models.APIKey) bool {
+	requested := strings.TrimSpace(c.GetHeader(tenantHeader))
+	if !models.ValidTenant(requested) {
+		abortWithCode(c, errors.CodeInvalidField, "invalid "+tenantHeader+" header")
+		return false
+	}
+
+	t, ok := key.TenantFor(requested)
+	if !ok {
+		abortWithCode(c, errors.CodeForbidden, "api key may not act for tenant "+requested)
+		return false
+	}
+
+	c.Set(tenantKey, t)
+	return true
+}
+
+// tenant returns the tenant the request acts for
+func tenant(c *gin.Context) string {
+	return c.GetString(tenantKey)
+}
+
+// requestKey extracts the plaintext api key from either the Authorization
+// bearer token or the X-API-Key header
+func requestKey(req *http.Request) string {
+	if header := req.Header.Get("Authorization"); header != "" {
+		const bearer = "bearer "
+		if len(header) > len(bearer) && strings.ToLower(header[:len(bearer)]) == bearer {
+			return strings.TrimSpace(header[len(bearer):])
+		}
+	}
+
+	return strings.TrimSpace(req.Header.Get("X-API-Key"))
+}
+
+// client returns the authenticated api key for the request if any
+func client(c *gin.Context) *models.APIKey {
+	if v, ok := c.Get(clientKey); ok {
+		if key, ok := v.(*models.APIKey); ok {
+			return key
+		}
+	}
+	return nil
+}
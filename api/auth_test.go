@@ -0,0 +1,214 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/txross1993/superman-api/auth"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/superman"
+	"github.com/txross1993/superman-api/testdata"
+)
+
+func TestAuthentication(t *testing.T) {
+	sqlDB, cleanup := newTestDB(t)
+	defer cleanup()
+
+	expired := time.Now().Add(-time.Hour)
+	keys := map[string]*models.APIKey{
+		"ingest":  {Name: "collector", Scopes: "ingest"},
+		"read":    {Name: "dashboard", Scopes: "read"},
+		"admin":   {Name: "operator", Scopes: "admin"},
+		"expired": {Name: "old", Scopes: "ingest", ExpiresAt: &expired},
+		"revoked": {Name: "leaked", Scopes: "ingest"},
+	}
+	plaintext := map[string]string{}
+	for label, key := range keys {
+		pt, err := auth.NewKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		key.KeyHash = auth.Hash(pt)
+		key.Prefix = auth.Prefix(pt)
+		if err := sqlDB.CreateAPIKey(key); err != nil {
+			t.Fatal(err)
+		}
+		plaintext[label] = pt
+	}
+	if err := sqlDB.RevokeAPIKey("leaked", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	api := NewAPI(Config{
+		Superman: superman.NewService(&fakeGeo{}, sqlDB),
+		Keys:     sqlDB,
+	})
+
+	tests := map[string]struct {
+		header string
+		value  string
+		want   int
+	}{
+		"no key":         {want: 401},
+		"unknown key":    {header: "X-API-Key", value: "sk_unknown", want: 401},
+		"expired key":    {header: "X-API-Key", value: plaintext["expired"], want: 401},
+		"revoked key":    {header: "X-API-Key", value: plaintext["revoked"], want: 401},
+		"missing scope":  {header: "X-API-Key", value: plaintext["read"], want: 403},
+		"ingest key":     {header: "X-API-Key", value: plaintext["ingest"], want: 201},
+		"bearer token":   {header: "Authorization", value: "Bearer " + plaintext["ingest"], want: 201},
+		"admin implicit": {header: "Authorization", value: "bearer " + plaintext["admin"], want: 201},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case %s", name)
+		event := testdata.GenerateCurrentEvent()
+		b, err := json.Marshal(event)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := newRequest(t, "POST", "/v1/", bytes.NewReader(b))
+		if test.header != "" {
+			req.Header.Set(test.header, test.value)
+		}
+		resp := makeRequest(api.router, req)
+		assert.Equal(t, test.want, resp.Code)
+	}
+}
+
+func TestClientRecordedOnEvent(t *testing.T) {
+	sqlDB, cleanup := newTestDB(t)
+	defer cleanup()
+
+	pt, _ := auth.NewKey()
+	key := &models.APIKey{Name: "collector", Scopes: "ingest", KeyHash: auth.Hash(pt), Prefix: auth.Prefix(pt)}
+	if err := sqlDB.CreateAPIKey(key); err != nil {
+		t.Fatal(err)
+	}
+
+	api := NewAPI(Config{
+		Superman: superman.NewService(&fakeGeo{}, sqlDB),
+		Keys:     sqlDB,
+	})
+
+	current := testdata.GenerateCurrentEvent()
+	subsequent := testdata.GenerateSubsequentEvent(false, false)
+	for _, event := range []*models.UserIPAccessEvent{current, subsequent} {
+		b, _ := json.Marshal(event)
+		req := newRequest(t, "POST", "/v1/", bytes.NewReader(b))
+		req.Header.Set("X-API-Key", pt)
+		resp := makeRequest(api.router, req)
+		assert.Equal(t, 201, resp.Code)
+	}
+
+	stored, err := sqlDB.FindPrecedingIPAccessEvent(context.Background(), subsequent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, current.EventUUID, stored.EventUUID)
+	assert.Equal(t, "collector", stored.Client)
+}
+
+func TestTenants(t *testing.T) {
+	sqlDB, cleanup := newTestDB(t)
+	defer cleanup()
+
+	plaintext := map[string]string{}
+	for _, key := range []*models.APIKey{
+		{Name: "acme", Scopes: "admin", Tenant: "acme"},
+		{Name: "shared", Scopes: "ingest,read,cross-tenant"},
+		{Name: "unbound", Scopes: "ingest,read"},
+	} {
+		pt, _ := auth.NewKey()
+		key.KeyHash = auth.Hash(pt)
+		key.Prefix = auth.Prefix(pt)
+		if err := sqlDB.CreateAPIKey(key); err != nil {
+			t.Fatal(err)
+		}
+		plaintext[key.Name] = pt
+	}
+
+	api := NewAPI(Config{
+		Superman: superman.NewService(&fakeGeo{}, sqlDB),
+		Keys:     sqlDB,
+	})
+
+	current := testdata.GenerateCurrentEvent()
+	subsequent := testdata.GenerateSubsequentEvent(false, false)
+	other := *subsequent
+	other.EventUUID = "7b2d3f4e-1c5a-4e8b-9d0f-2a6c8e1b3d5f"
+
+	// steps run in order as each builds on the events stored before it
+	steps := []struct {
+		name   string
+		key    string
+		tenant string
+		event  *models.UserIPAccessEvent
+		want   int
+	}{
+		{name: "bound key", key: "acme", event: current, want: 201},
+		{name: "bound key naming its tenant", key: "acme", tenant: "acme", event: subsequent, want: 201},
+		{name: "bound key naming another tenant", key: "acme", tenant: "globex", event: subsequent, want: 403},
+		{name: "invalid tenant", key: "shared", tenant: "no spaces", event: subsequent, want: 400},
+		{name: "uuid of another tenant", key: "shared", tenant: "globex", event: current, want: 201},
+		{name: "unbound key naming a tenant", key: "unbound", tenant: "globex", event: &other, want: 403},
+		{name: "cross tenant key naming a tenant", key: "shared", tenant: "globex", event: &other, want: 201},
+	}
+
+	for _, step := range steps {
+		t.Logf("Running test case %s", step.name)
+		b, _ := json.Marshal(step.event)
+		req := newRequest(t, "POST", "/v1/", bytes.NewReader(b))
+		req.Header.Set("X-API-Key", plaintext[step.key])
+		if step.tenant != "" {
+			req.Header.Set(tenantHeader, step.tenant)
+		}
+		resp := makeRequest(api.router, req)
+		assert.Equal(t, step.want, resp.Code)
+	}
+
+	// the username's profile is kept apart in each tenant
+	profiles := map[string]struct {
+		key           string
+		tenant        string
+		want          int
+		expectedCount int64
+	}{
+		"bound tenant":   {key: "acme", want: 200, expectedCount: 2},
+		"named tenant":   {key: "shared", tenant: "globex", want: 200, expectedCount: 2},
+		"default tenant": {key: "shared", want: 404},
+		"unbound key":    {key: "unbound", tenant: "globex", want: 403},
+	}
+
+	for name, test := range profiles {
+		t.Logf("Running test case %s", name)
+		req := newRequest(t, "GET", "/v1/users/"+testdata.TestUser+"/profile", nil)
+		req.Header.Set("X-API-Key", plaintext[test.key])
+		if test.tenant != "" {
+			req.Header.Set(tenantHeader, test.tenant)
+		}
+		resp := makeRequest(api.router, req)
+		assert.Equal(t, test.want, resp.Code)
+
+		if test.want != 200 {
+			continue
+		}
+
+		var profile models.UserProfile
+		if err := json.Unmarshal(resp.Body.Bytes(), &profile); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, test.expectedCount, profile.LoginCount)
+	}
+}
+
+// fakeGeo geoencodes every IP address to the same coordinates
+type fakeGeo struct{}
+
+func (f *fakeGeo) GetCoordinatesFromIP(ctx context.Context, ip string) (*models.Geography, error) {
+	return &models.Geography{Latitude: 34.7725, Longitude: 113.7266, Radius: 50}, nil
+}
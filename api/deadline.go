@@ -0,0 +1,25 @@
+package api
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// deadline bounds the time spent serving a request by cancelling its
+// context once the request timeout passes, interrupting the storage query
+// or geolocation in flight. A zero timeout leaves requests unbounded, and
+// long lived streams are not routed through it
+func (api *API) deadline() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if api.RequestTimeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), api.RequestTimeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
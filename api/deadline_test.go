@@ -0,0 +1,73 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+
+	"github.com/txross1993/superman-api/errors"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/superman"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	sqlDB, cleanup := newTestDB(t)
+	defer cleanup()
+
+	tests := map[string]struct {
+		service *superman.Service
+		timeout time.Duration
+		uuid    string
+		status  int
+		code    errors.Code
+	}{
+		"served within the timeout": {
+			service: superman.NewService(&fakeGeo{}, sqlDB),
+			timeout: 10 * time.Second,
+			uuid:    "85ad929a-db03-4bf4-9541-8f728fa12e42",
+			status:  201,
+		},
+		"geolocation interrupted": {
+			service: superman.NewService(&blockingGeo{}, sqlDB),
+			timeout: 20 * time.Millisecond,
+			uuid:    "85ad929a-db03-4bf4-9541-8f728fa12e43",
+			status:  503,
+			code:    errors.CodeTimeout,
+		},
+		"unbounded": {
+			service: superman.NewService(&fakeGeo{}, sqlDB),
+			uuid:    "85ad929a-db03-4bf4-9541-8f728fa12e44",
+			status:  201,
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case %s", name)
+		api := NewAPI(Config{Superman: test.service, RequestTimeout: test.timeout})
+
+		body := `{"username": "bob", "unix_timestamp": 1514764800, "event_uuid": "` + test.uuid + `", "ip_address": "206.81.252.200"}`
+		resp := makeRequest(api.router, newRequest(t, "POST", "/v1/", bytes.NewReader([]byte(body))))
+		assert.Equal(t, test.status, resp.Code)
+
+		if test.code != "" {
+			var got Problem
+			if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.code, got.Code)
+			assert.Equal(t, "", got.Detail)
+		}
+	}
+}
+
+// blockingGeo answers no geoencoding request before its context is done
+type blockingGeo struct{}
+
+func (b *blockingGeo) GetCoordinatesFromIP(ctx context.Context, ip string) (*models.Geography, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+
+	"github.com/txross1993/superman-api/db"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/superman"
+)
+
+// TestConcurrentIngestion posts a burst of events of one username from many
+// clients at once, each event twice, and checks that every post is served
+// and every event is stored exactly once
+func TestConcurrentIngestion(t *testing.T) {
+	const (
+		clients = 16
+		events  = 200
+	)
+
+	sqlDB, cleanup := newTestDB(t, db.WithPool(db.Pool{MaxReaders: 4, MaxIdleReaders: 4}))
+	defer cleanup()
+
+	api := NewAPI(Config{Superman: superman.NewService(&fakeGeo{}, sqlDB)})
+
+	reqs := make(chan *http.Request, 2*events)
+	for i := 0; i < events; i++ {
+		body := fmt.Sprintf(`{"username": "bob", "unix_timestamp": %d, "event_uuid": "85ad929a-db03-4bf4-9541-%012d", "ip_address": "206.81.252.200"}`, 1514764800+i/4, i)
+		for replay := 0; replay < 2; replay++ {
+			reqs <- newRequest(t, "POST", "/v1/", strings.NewReader(body))
+		}
+	}
+	close(reqs)
+
+	statuses := make(chan int, 2*events)
+	var wg sync.WaitGroup
+	wg.Add(clients)
+	for c := 0; c < clients; c++ {
+		go func() {
+			defer wg.Done()
+			for req := range reqs {
+				statuses <- makeRequest(api.router, req).Code
+			}
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+
+	served := map[int]int{}
+	for status := range statuses {
+		served[status]++
+	}
+	assert.Equal(t, map[int]int{201: 2 * events}, served)
+
+	stored, err := sqlDB.ListUserIPAccessEvents(context.Background(), models.DefaultTenant, "bob", 0, 2*events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, events, len(stored))
+}
@@ -0,0 +1,543 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/txross1993/superman-api/errors"
+	"github.com/txross1993/superman-api/models"
+)
+
+// openAPIVersion is the version of the OpenAPI specification the document
+// follows
+const openAPIVersion = "3.0.3"
+
+// schemaRefBase prefixes the name of a component schema to form its
+// reference
+const schemaRefBase = "#/components/schemas/"
+
+// OpenAPI is an OpenAPI 3 document describing the api
+type OpenAPI struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       OpenAPIInfo                      `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+// OpenAPIInfo is the title and version of the api
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Components holds the schemas of the models the api exchanges and the
+// ways clients present api keys
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme describes one way of presenting an api key
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme,omitempty"`
+	Name   string `json:"name,omitempty"`
+	In     string `json:"in,omitempty"`
+}
+
+// Operation describes a single route
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter describes a path or query parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the body an operation accepts
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response describes one status an operation may answer with
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body of one content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is an OpenAPI schema object, limited to the keywords needed to
+// describe the api's models
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+// operation documents a route. The request is a value of the model whose
+// json encoding the request body carries
+type operation struct {
+	method      string
+	path        string
+	id          string
+	summary     string
+	description string
+	scope       models.Scope
+	parameters  []*Parameter
+	request     interface{}
+	responses   []response
+}
+
+// response documents one status of an operation. The body is a value of
+// the model whose encoding the response carries, or nil for no content
+type response struct {
+	status      int
+	description string
+	body        interface{}
+	contentType string
+}
+
+// problemResponse documents a status answered with problem details
+func problemResponse(status int, description string) response {
+	return response{status: status, description: description, body: Problem{}, contentType: problemContentType}
+}
+
+// operations lists the routes SetupRoutes declares for the configuration
+func (api *API) operations() []operation {
+	ops := []operation{
+		{
+			method:  http.MethodGet,
+			path:    "/openapi.json",
+			id:      "getOpenAPI",
+			summary: "Describe the api",
+			responses: []response{
+				{status: http.StatusOK, description: "This OpenAPI document", body: map[string]interface{}{}},
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/metrics",
+			id:      "getMetrics",
+			summary: "Report runtime and request metrics",
+			scope:   models.ScopeRead,
+			responses: []response{
+				{status: http.StatusOK, description: "The published expvar metrics", body: map[string]interface{}{}},
+			},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/v1/",
+			id:          "analyzeLoginEvent",
+			summary:     "Analyze a login event",
+			description: "Stores the login event and compares it to the user's neighboring logins and profile. Submitting an event again returns its analysis without counting it twice.",
+			scope:       models.ScopeIngest,
+			request:     models.UserIPAccessEvent{},
+			responses: []response{
+				{status: http.StatusCreated, description: "The analysis of the login event", body: models.Superman{}},
+				problemResponse(http.StatusBadRequest, "The event is malformed or invalid"),
+				problemResponse(http.StatusRequestEntityTooLarge, "The request body is over the size limit"),
+				problemResponse(http.StatusInternalServerError, "An ip address could not be geolocated"),
+				problemResponse(http.StatusBadGateway, "The event was stored but its verdicts could not be published"),
+			},
+		},
+		{
+			method:  http.MethodGet,
+			path:    "/v1/users/:username/profile",
+			id:      "getUserProfile",
+			summary: "Report where and when a user usually logs in",
+			scope:   models.ScopeRead,
+			parameters: []*Parameter{
+				{Name: "username", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+			},
+			responses: []response{
+				{status: http.StatusOK, description: "The user's profile", body: models.UserProfile{}},
+				problemResponse(http.StatusNotFound, "No events have been analyzed for the user"),
+			},
+		},
+	}
+
+	if api.Stream != nil {
+		ops = append(ops, operation{
+			method:      http.MethodGet,
+			path:        "/v1/stream",
+			id:          "streamVerdicts",
+			summary:     "Stream live verdicts",
+			description: "Pushes the verdict of every matching analysis as server-sent events. Each verdict event carries a Verdict as its data. A client which falls behind is sent a dropped event and disconnected.",
+			scope:       models.ScopeRead,
+			parameters: []*Parameter{
+				{Name: "username", In: "query", Description: "Only verdicts on the username", Schema: &Schema{Type: "string"}},
+				{Name: "suspicious", In: "query", Description: "Only suspicious verdicts", Schema: &Schema{Type: "boolean"}},
+				{Name: "min_score", In: "query", Description: "Only verdicts scoring at least this", Schema: &Schema{Type: "number", Minimum: float(0), Maximum: float(1)}},
+			},
+			responses: []response{
+				{status: http.StatusOK, description: "A stream of verdict events", body: "", contentType: "text/event-stream"},
+				problemResponse(http.StatusBadRequest, "A query parameter is invalid"),
+			},
+		})
+	}
+
+	if api.DeadLetters != nil {
+		ops = append(ops,
+			operation{
+				method:  http.MethodGet,
+				path:    "/v1/webhooks/dead-letters",
+				id:      "listDeadLetters",
+				summary: "List the tenant's webhook notifications which exhausted their attempts",
+				scope:   models.ScopeAdmin,
+				responses: []response{
+					{status: http.StatusOK, description: "The dead webhook deliveries", body: []models.WebhookDelivery{}},
+				},
+			},
+			operation{
+				method:  http.MethodPost,
+				path:    "/v1/webhooks/dead-letters/:id/retry",
+				id:      "retryDeadLetter",
+				summary: "Schedule a dead webhook notification for a fresh round of attempts",
+				scope:   models.ScopeAdmin,
+				parameters: []*Parameter{
+					{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer", Minimum: float(0)}},
+				},
+				responses: []response{
+					{status: http.StatusAccepted, description: "The delivery was scheduled"},
+					problemResponse(http.StatusNotFound, "No dead letter of the tenant has the id"),
+				},
+			},
+		)
+	}
+
+	return ops
+}
+
+// openAPI builds the document describing the configured routes from the
+// operations and the json encoding of the models they exchange
+func (api *API) openAPI() *OpenAPI {
+	schemas := newSchemaBuilder()
+	doc := &OpenAPI{
+		OpenAPI: openAPIVersion,
+		Info: OpenAPIInfo{
+			Title:       "superman-api",
+			Description: "Detects suspicious logins such as impossible travel between a user's login events.",
+			Version:     "v1",
+		},
+		Paths: map[string]map[string]*Operation{},
+		Components: Components{
+			SecuritySchemes: map[string]*SecurityScheme{
+				"bearer": {Type: "http", Scheme: "bearer"},
+				"apiKey": {Type: "apiKey", Name: "X-API-Key", In: "header"},
+			},
+		},
+	}
+
+	// verdicts are only carried inside server-sent events so are described
+	// by name
+	schemas.of(reflect.TypeOf(models.Verdict{}))
+
+	for _, op := range api.operations() {
+		out := &Operation{
+			OperationID: op.id,
+			Summary:     op.summary,
+			Description: op.description,
+			Parameters:  append([]*Parameter{}, op.parameters...),
+			Responses:   map[string]*Response{},
+		}
+
+		if op.request != nil {
+			out.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]*MediaType{"application/json": {Schema: schemas.of(reflect.TypeOf(op.request))}},
+			}
+		}
+
+		responses := op.responses
+		if op.scope != "" {
+			out.Security = []map[string][]string{{"bearer": {}}, {"apiKey": {}}}
+			out.Parameters = append(out.Parameters, &Parameter{
+				Name:        tenantHeader,
+				In:          "header",
+				Description: "The tenant to act for when the api key is granted the cross-tenant scope",
+				Schema:      &Schema{Type: "string"},
+			})
+			out.Description = strings.TrimSpace(out.Description + " Requires an api key with the " + string(op.scope) + " scope.")
+			for _, resp := range []response{
+				problemResponse(http.StatusBadRequest, "The "+tenantHeader+" header is invalid"),
+				problemResponse(http.StatusUnauthorized, "The api key is missing or invalid"),
+				problemResponse(http.StatusForbidden, "The api key lacks the scope or may not act for the tenant"),
+				problemResponse(http.StatusTooManyRequests, "The client exceeded its request rate"),
+				problemResponse(http.StatusServiceUnavailable, "Storage is unavailable or the request timed out"),
+			} {
+				if !documents(responses, resp.status) {
+					responses = append(responses, resp)
+				}
+			}
+		}
+
+		for _, resp := range responses {
+			r := &Response{Description: resp.description}
+			if resp.body != nil {
+				contentType := resp.contentType
+				if contentType == "" {
+					contentType = "application/json"
+				}
+				r.Content = map[string]*MediaType{contentType: {Schema: schemas.of(reflect.TypeOf(resp.body))}}
+			}
+			out.Responses[strconv.Itoa(resp.status)] = r
+		}
+
+		path := openAPIPath(op.path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*Operation{}
+		}
+		doc.Paths[path][strings.ToLower(op.method)] = out
+	}
+
+	doc.Components.Schemas = schemas.components
+	return doc
+}
+
+// documents reports whether the responses include the status
+func documents(responses []response, status int) bool {
+	for _, resp := range responses {
+		if resp.status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// GetOpenAPI serves the OpenAPI document describing the api
+func (api *API) GetOpenAPI(c *gin.Context) {
+	c.JSON(http.StatusOK, api.spec)
+}
+
+// openAPIPath converts a gin route path to an OpenAPI path template
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// schemaBuilder derives schemas from the json encoding of go types, naming
+// each struct as a component schema
+type schemaBuilder struct {
+	components map[string]*Schema
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{components: map[string]*Schema{}}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaOverrides holds the schemas of types whose json decoding accepts
+// more than their encoding produces
+var schemaOverrides = map[reflect.Type]*Schema{
+	reflect.TypeOf(models.UserIPAccessEvent{}): {
+		Type: "object",
+		Properties: map[string]*Schema{
+			"event_uuid": {Type: "string", Format: "uuid"},
+			"username":   {Type: "string"},
+			"unix_timestamp": {
+				Description: "An RFC 3339 string, epoch seconds, fractional epoch seconds, or epoch milliseconds",
+				OneOf: []*Schema{
+					{Type: "integer", Format: "int64"},
+					{Type: "number", Format: "double"},
+					{Type: "string", Format: "date-time"},
+				},
+			},
+			"ip_address": {Type: "string", Description: "An IPv4 or IPv6 address"},
+		},
+		Required: []string{"event_uuid", "username", "unix_timestamp", "ip_address"},
+	},
+}
+
+// enumValues holds the values of the string types clients may rely on
+var enumValues = map[reflect.Type][]string{
+	reflect.TypeOf(models.TravelAssessment("")): {
+		string(models.AssessmentCoLocated),
+		string(models.AssessmentDistance),
+		string(models.AssessmentSpeed),
+		string(models.AssessmentUnknown),
+		string(models.AssessmentAllowed),
+	},
+	reflect.TypeOf(models.Reason("")): {
+		string(models.ReasonTravelTo),
+		string(models.ReasonTravelFrom),
+		string(models.ReasonSuspiciousPath),
+		string(models.ReasonConcurrentSessions),
+		string(models.ReasonUnfamiliarLocation),
+		string(models.ReasonUnusualHour),
+	},
+	reflect.TypeOf(errors.Code("")): errorCodes(),
+}
+
+// errorCodes lists every code answered in problem details
+func errorCodes() []string {
+	codes := make([]string, 0, len(problemTitles))
+	for code := range problemTitles {
+		codes = append(codes, string(code))
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// of returns the schema of the type's json encoding, referring to structs
+// by their component schema
+func (b *schemaBuilder) of(t reflect.Type) *Schema {
+	if override, ok := schemaOverrides[t]; ok {
+		b.components[t.Name()] = override
+		return &Schema{Ref: schemaRefBase + t.Name()}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return b.of(t.Elem())
+	case reflect.Struct:
+		if t == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		if _, ok := b.components[t.Name()]; !ok {
+			// claim the name before descending so recursive types terminate
+			b.components[t.Name()] = &Schema{}
+			b.components[t.Name()] = b.object(t)
+		}
+		return &Schema{Ref: schemaRefBase + t.Name()}
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: b.of(t.Elem())}
+	case reflect.Array:
+		n := t.Len()
+		return &Schema{Type: "array", Items: b.of(t.Elem()), MinItems: &n, MaxItems: &n}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.of(t.Elem())}
+	case reflect.String:
+		return &Schema{Type: "string", Enum: enumValues[t]}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: float(0)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	}
+
+	return &Schema{}
+}
+
+// object returns the schema of a struct's json encoding
+func (b *schemaBuilder) object(t reflect.Type) *Schema {
+	obj := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	b.addFields(obj, t, true)
+	return obj
+}
+
+// addFields adds the json encoded fields of the struct to the object.
+// Fields promoted from an embedded struct pointer are optional as they are
+// omitted while the pointer is nil
+func (b *schemaBuilder) addFields(obj *Schema, t reflect.Type, required bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options := tag, ""
+		if comma := strings.Index(tag, ","); comma >= 0 {
+			name, options = tag[:comma], tag[comma+1:]
+		}
+		omitempty := strings.Contains(options, "omitempty")
+
+		if field.Anonymous && name == "" {
+			embedded, promotedRequired := field.Type, required
+			if embedded.Kind() == reflect.Ptr {
+				embedded, promotedRequired = embedded.Elem(), false
+			}
+			if embedded.Kind() == reflect.Struct {
+				b.addFields(obj, embedded, promotedRequired)
+				continue
+			}
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := b.of(field.Type)
+		if !omitempty && nullable(field.Type) {
+			prop = nullableSchema(prop)
+		}
+		obj.Properties[name] = prop
+
+		if required && !omitempty {
+			obj.Required = append(obj.Required, name)
+		}
+	}
+}
+
+// nullable reports whether the zero value of the type encodes as null
+func nullable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+	return false
+}
+
+// nullableSchema allows null in place of the schema
+func nullableSchema(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{AllOf: []*Schema{s}, Nullable: true}
+	}
+	copied := *s
+	copied.Nullable = true
+	return &copied
+}
+
+func float(f float64) *float64 {
+	return &f
+}
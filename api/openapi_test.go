@@ -0,0 +1,369 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/txross1993/superman-api/auth"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/stream"
+	"github.com/txross1993/superman-api/superman"
+	"github.com/txross1993/superman-api/testdata"
+)
+
+func TestOpenAPIRoutes(t *testing.T) {
+	api, _, cleanup := contractAPI(t)
+	defer cleanup()
+
+	doc := fetchOpenAPI(t, api)
+	paths, _ := doc["paths"].(map[string]interface{})
+
+	var routed []string
+	for _, route := range api.router.Routes() {
+		routed = append(routed, route.Method+" "+openAPIPath(route.Path))
+	}
+
+	var documented []string
+	for p, item := range paths {
+		for method := range item.(map[string]interface{}) {
+			documented = append(documented, strings.ToUpper(method)+" "+p)
+		}
+	}
+
+	sort.Strings(routed)
+	sort.Strings(documented)
+	assert.Equal(t, routed, documented)
+}
+
+func TestOpenAPIContract(t *testing.T) {
+	api, deadID, cleanup := contractAPI(t)
+	defer cleanup()
+
+	doc := fetchOpenAPI(t, api)
+
+	current, _ := json.Marshal(testdata.GenerateCurrentEvent())
+	subsequent, _ := json.Marshal(testdata.GenerateSubsequentEvent(true, false))
+	invalid := []byte(`{"event_uuid":"nope","unix_timestamp":"yesterday"}`)
+
+	tests := []struct {
+		name   string
+		method string
+		route  string
+		path   string
+		body   []byte
+		noKey  bool
+		want   int
+	}{
+		{name: "analyze", method: "POST", route: "/v1/", path: "/v1/", body: current, want: 201},
+		{name: "analyze neighbor", method: "POST", route: "/v1/", path: "/v1/", body: subsequent, want: 201},
+		{name: "analyze invalid", method: "POST", route: "/v1/", path: "/v1/", body: invalid, want: 400},
+		{name: "analyze oversize", method: "POST", route: "/v1/", path: "/v1/", body: bytes.Repeat([]byte(" "), 4096), want: 413},
+		{name: "analyze without key", method: "POST", route: "/v1/", path: "/v1/", body: current, noKey: true, want: 401},
+		{name: "profile", method: "GET", route: "/v1/users/{username}/profile", path: "/v1/users/" + testdata.TestUser + "/profile", want: 200},
+		{name: "unknown profile", method: "GET", route: "/v1/users/{username}/profile", path: "/v1/users/nobody/profile", want: 404},
+		{name: "stream invalid filter", method: "GET", route: "/v1/stream", path: "/v1/stream?min_score=2", want: 400},
+		{name: "dead letters", method: "GET", route: "/v1/webhooks/dead-letters", path: "/v1/webhooks/dead-letters", want: 200},
+		{name: "retry unknown", method: "POST", route: "/v1/webhooks/dead-letters/{id}/retry", path: "/v1/webhooks/dead-letters/999/retry", want: 404},
+		{name: "retry", method: "POST", route: "/v1/webhooks/dead-letters/{id}/retry", path: fmt.Sprintf("/v1/webhooks/dead-letters/%d/retry", deadID), want: 202},
+		{name: "metrics", method: "GET", route: "/metrics", path: "/metrics", want: 200},
+		{name: "openapi", method: "GET", route: "/openapi.json", path: "/openapi.json", noKey: true, want: 200},
+	}
+
+	for _, test := range tests {
+		t.Logf("Running test case %s", test.name)
+		op := lookupOperation(doc, test.route, test.method)
+		if op == nil {
+			t.Errorf("%s %s is not documented", test.method, test.route)
+			continue
+		}
+
+		if test.want < 300 && test.body != nil {
+			schema := mediaSchema(op["requestBody"], "application/json")
+			for _, violation := range validateJSON(doc, schema, test.body) {
+				t.Errorf("request %s", violation)
+			}
+		}
+
+		req := newRequest(t, test.method, test.path, bytes.NewReader(test.body))
+		if !test.noKey {
+			req.Header.Set("X-API-Key", contractKey)
+		}
+		resp := makeRequest(api.router, req)
+		assert.Equal(t, test.want, resp.Code)
+
+		responses, _ := op["responses"].(map[string]interface{})
+		documented, ok := responses[strconv.Itoa(resp.Code)]
+		if !ok {
+			t.Errorf("%s %s answered undocumented status %d", test.method, test.route, resp.Code)
+			continue
+		}
+
+		content, _ := documented.(map[string]interface{})["content"].(map[string]interface{})
+		if len(content) == 0 {
+			assert.Equal(t, 0, resp.Body.Len())
+			continue
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(resp.Header().Get("Content-Type"))
+		schema := mediaSchema(documented, mediaType)
+		if schema == nil {
+			t.Errorf("%s %s answered %d with undocumented content type %q", test.method, test.route, resp.Code, mediaType)
+			continue
+		}
+		for _, violation := range validateJSON(doc, schema, resp.Body.Bytes()) {
+			t.Errorf("response %s", violation)
+		}
+	}
+}
+
+func TestOpenAPIDescribesModels(t *testing.T) {
+	api, _, cleanup := contractAPI(t)
+	defer cleanup()
+
+	doc := fetchOpenAPI(t, api)
+	ref := map[string]interface{}{"$ref": schemaRefBase + "Verdict"}
+
+	verdict := &models.Verdict{
+		EventUUID:        "85ad929a-db03-4bf4-9541-8f728fa12e42",
+		Username:         "bob",
+		IP:               "206.81.252.6",
+		TimestampMillis:  1514764800000,
+		Suspicious:       true,
+		Score:            0.84,
+		Reasons:          []models.Reason{models.ReasonTravelTo, models.ReasonUnusualHour},
+		TriggerEventUUID: "85ad929a-db03-4bf4-9541-8f728fa12e42",
+		Analysis: &models.Superman{
+			CurrentGeo:         &models.Geography{Latitude: 1, Longitude: 2, Radius: 3, Country: "US", TimeZone: "America/Chicago", ASN: 7922},
+			TravelToSuspicious: true,
+			PrecedingIPAccess:  &models.IPAccess{Geography: &models.Geography{}, IP: "10.0.0.1", Assessment: models.AssessmentSpeed},
+			SuspiciousPaths:    []*models.TravelPath{{Hops: []*models.IPAccess{{IP: "10.0.0.1"}}, Assessment: models.AssessmentDistance}},
+			ConcurrentSessions: &models.ConcurrentSessions{Locations: []*models.Geography{{}}, Events: []*models.IPAccess{{IP: "10.0.0.2"}}},
+			UnfamiliarLocation: &models.UnfamiliarLocation{Nearest: &models.ProfileLocation{Country: "US"}},
+			UnusualHour:        &models.UnusualHour{LocalHour: 3, TimeZone: "America/Chicago"},
+		},
+	}
+
+	b, _ := json.Marshal(verdict)
+	assert.Equal(t, 0, len(validateJSON(doc, ref, b)))
+
+	// the schema rejects what the model could never encode
+	b = []byte(`{"eventUuid":"a","username":"bob","ip":"10.0.0.1","timestampMillis":1,"suspicious":true,"score":1,"reasons":["teleport"],"extra":true}`)
+	assert.Equal(t, 2, len(validateJSON(doc, ref, b)))
+}
+
+// contractKey is the admin api key of the contract test api
+var contractKey string
+
+// contractAPI creates an api with every optional route configured and a
+// dead webhook delivery to retry
+func contractAPI(t *testing.T) (*API, uint, func()) {
+	sqlDB, cleanup := newTestDB(t)
+
+	contractKey, _ = auth.NewKey()
+	key := &models.APIKey{Name: "operator", Scopes: "admin", KeyHash: auth.Hash(contractKey), Prefix: auth.Prefix(contractKey)}
+	if err := sqlDB.CreateAPIKey(key); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	dead := time.Now().UTC()
+	delivery := &models.WebhookDelivery{
+		Destination:   "http://127.0.0.1:1/hook",
+		DedupeKey:     "a",
+		EventUUID:     "a",
+		Payload:       `{"id":"a"}`,
+		NextAttemptAt: dead,
+	}
+	if err := sqlDB.EnqueueDelivery(context.Background(), delivery); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	delivery.DeadAt = &dead
+	if err := sqlDB.SaveDelivery(context.Background(), delivery); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	broker := stream.NewBroker(stream.DefaultBuffer)
+	api := NewAPI(Config{
+		Superman:     superman.NewService(&fakeGeo{}, sqlDB, superman.WithNotifier(broker)),
+		Keys:         sqlDB,
+		MaxBodyBytes: 1024,
+		DeadLetters:  sqlDB,
+		Stream:       broker,
+	})
+
+	return api, delivery.ID, cleanup
+}
+
+// fetchOpenAPI requests the document the api serves
+func fetchOpenAPI(t *testing.T, api *API) map[string]interface{} {
+	t.Helper()
+	resp := makeRequest(api.router, newRequest(t, "GET", "/openapi.json", nil))
+	assert.Equal(t, 200, resp.Code)
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(resp.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, openAPIVersion, doc["openapi"])
+	return doc
+}
+
+// lookupOperation finds the documented operation of the route
+func lookupOperation(doc map[string]interface{}, route, method string) map[string]interface{} {
+	paths, _ := doc["paths"].(map[string]interface{})
+	item, _ := paths[route].(map[string]interface{})
+	op, _ := item[strings.ToLower(method)].(map[string]interface{})
+	return op
+}
+
+// mediaSchema returns the schema of the content type of a request body or
+// response
+func mediaSchema(body interface{}, contentType string) interface{} {
+	b, _ := body.(map[string]interface{})
+	content, _ := b["content"].(map[string]interface{})
+	media, _ := content[contentType].(map[string]interface{})
+	if media == nil {
+		return nil
+	}
+	return media["schema"]
+}
+
+// validateJSON decodes the data and reports every way it violates the
+// schema
+func validateJSON(doc map[string]interface{}, schema interface{}, data []byte) []string {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return []string{err.Error()}
+	}
+	return validate(doc, schema, value, "")
+}
+
+// validate checks the value against the schema keywords the document uses.
+// Objects may only carry declared properties so that fields added to a
+// model without reaching the document are caught
+func validate(doc map[string]interface{}, schema interface{}, value interface{}, at string) []string {
+	s, _ := schema.(map[string]interface{})
+	if s == nil {
+		return []string{at + ": no schema"}
+	}
+
+	if ref, ok := s["$ref"].(string); ok {
+		components, _ := doc["components"].(map[string]interface{})
+		schemas, _ := components["schemas"].(map[string]interface{})
+		return validate(doc, schemas[strings.TrimPrefix(ref, schemaRefBase)], value, at)
+	}
+
+	if value == nil {
+		if s["nullable"] == true || len(s) == 0 {
+			return nil
+		}
+		return []string{at + ": null is not allowed"}
+	}
+
+	var violations []string
+	if allOf, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			violations = append(violations, validate(doc, sub, value, at)...)
+		}
+	}
+
+	if oneOf, ok := s["oneOf"].([]interface{}); ok {
+		matched := 0
+		for _, sub := range oneOf {
+			if len(validate(doc, sub, value, at)) == 0 {
+				matched++
+			}
+		}
+		if matched == 0 {
+			violations = append(violations, at+": matches none of oneOf")
+		}
+	}
+
+	switch s["type"] {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return append(violations, fmt.Sprintf("%s: %v is not an object", at, value))
+		}
+
+		properties, _ := s["properties"].(map[string]interface{})
+		required, _ := s["required"].([]interface{})
+		for _, name := range required {
+			if _, ok := obj[name.(string)]; !ok {
+				violations = append(violations, fmt.Sprintf("%s: missing required %s", at, name))
+			}
+		}
+
+		for name, v := range obj {
+			if prop, ok := properties[name]; ok {
+				violations = append(violations, validate(doc, prop, v, at+"/"+name)...)
+				continue
+			}
+			if additional, ok := s["additionalProperties"]; ok {
+				violations = append(violations, validate(doc, additional, v, at+"/"+name)...)
+				continue
+			}
+			violations = append(violations, fmt.Sprintf("%s: undocumented property %s", at, name))
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return append(violations, fmt.Sprintf("%s: %v is not an array", at, value))
+		}
+		if min, ok := s["minItems"].(float64); ok && float64(len(items)) < min {
+			violations = append(violations, fmt.Sprintf("%s: fewer than %v items", at, min))
+		}
+		if max, ok := s["maxItems"].(float64); ok && float64(len(items)) > max {
+			violations = append(violations, fmt.Sprintf("%s: more than %v items", at, max))
+		}
+		for i, item := range items {
+			violations = append(violations, validate(doc, s["items"], item, fmt.Sprintf("%s/%d", at, i))...)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return append(violations, fmt.Sprintf("%s: %v is not a string", at, value))
+		}
+		if enum, ok := s["enum"].([]interface{}); ok {
+			allowed := false
+			for _, e := range enum {
+				allowed = allowed || e == str
+			}
+			if !allowed {
+				violations = append(violations, fmt.Sprintf("%s: %q is not one of %v", at, str, enum))
+			}
+		}
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			return append(violations, fmt.Sprintf("%s: %v is not a number", at, value))
+		}
+		if s["type"] == "integer" && n != math.Trunc(n) {
+			violations = append(violations, fmt.Sprintf("%s: %v is not an integer", at, n))
+		}
+		if min, ok := s["minimum"].(float64); ok && n < min {
+			violations = append(violations, fmt.Sprintf("%s: %v is below %v", at, n, min))
+		}
+		if max, ok := s["maximum"].(float64); ok && n > max {
+			violations = append(violations, fmt.Sprintf("%s: %v is above %v", at, n, max))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			violations = append(violations, fmt.Sprintf("%s: %v is not a boolean", at, value))
+		}
+	}
+
+	return violations
+}
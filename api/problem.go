@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/txross1993/superman-api/errors"
+)
+
+// problemContentType is the RFC 7807 media type for problem details
+const problemContentType = "application/problem+json"
+
+// problemTypeBase prefixes the error code to form the problem type uri
+const problemTypeBase = "urn:superman-api:problem:"
+
+// Problem is an RFC 7807 problem details response body extended with a
+// stable error code, the request id, and per field validation errors
+type Problem struct {
+	Type      string         `json:"type"`
+	Title     string         `json:"title"`
+	Status    int            `json:"status"`
+	Detail    string         `json:"detail,omitempty"`
+	Instance  string         `json:"instance,omitempty"`
+	Code      errors.Code    `json:"code"`
+	RequestID string         `json:"request_id,omitempty"`
+	Errors    []FieldProblem `json:"errors,omitempty"`
+}
+
+// FieldProblem describes a problem with a single request field identified
+// by a JSON pointer into the request body
+type FieldProblem struct {
+	Pointer string      `json:"pointer"`
+	Code    errors.Code `json:"code"`
+	Detail  string      `json:"detail"`
+}
+
+// problemTitles holds the short human readable summary for each code
+var problemTitles = map[errors.Code]string{
+	errors.CodeInvalidIP:          "Invalid IP address",
+	errors.CodeMissingField:       "Missing required field",
+	errors.CodeInvalidField:       "Invalid field",
+	errors.CodeInvalidTimestamp:   "Invalid timestamp",
+	errors.CodeMalformedRequest:   "Malformed request",
+	errors.CodeGeolocationFailed:  "Geolocation failed",
+	errors.CodeStorageUnavailable: "Storage unavailable",
+	errors.CodePublishFailed:      "Verdicts not published",
+	errors.CodeUnauthorized:       "Unauthorized",
+	errors.CodeForbidden:          "Forbidden",
+	errors.CodeRateLimited:        "Rate limit exceeded",
+	errors.CodeRequestTooLarge:    "Request body too large",
+	errors.CodeTimeout:            "Request timed out",
+	errors.CodeNotFound:           "Not found",
+	errors.CodeInternal:           "Internal server error",
+}
+
+// problemStatuses holds the http status for each code
+var problemStatuses = map[errors.Code]int{
+	errors.CodeInvalidIP:          http.StatusBadRequest,
+	errors.CodeMissingField:       http.StatusBadRequest,
+	errors.CodeInvalidField:       http.StatusBadRequest,
+	errors.CodeInvalidTimestamp:   http.StatusBadRequest,
+	errors.CodeMalformedRequest:   http.StatusBadRequest,
+	errors.CodeGeolocationFailed:  http.StatusInternalServerError,
+	errors.CodeStorageUnavailable: http.StatusServiceUnavailable,
+	errors.CodePublishFailed:      http.StatusBadGateway,
+	errors.CodeUnauthorized:       http.StatusUnauthorized,
+	errors.CodeForbidden:          http.StatusForbidden,
+	errors.CodeRateLimited:        http.StatusTooManyRequests,
+	errors.CodeRequestTooLarge:    http.StatusRequestEntityTooLarge,
+	errors.CodeTimeout:            http.StatusServiceUnavailable,
+	errors.CodeNotFound:           http.StatusNotFound,
+	errors.CodeInternal:           http.StatusInternalServerError,
+}
+
+// newProblem builds the problem details for a code and detail message
+func newProblem(c *gin.Context, code errors.Code, detail string) *Problem {
+	status, ok := problemStatuses[code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	return &Problem{
+		Type:      problemTypeBase + string(code),
+		Title:     problemTitles[code],
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		Code:      code,
+		RequestID: requestID(c),
+	}
+}
+
+// abortWithProblem writes the problem details response and stops the
+// handler chain
+func abortWithProblem(c *gin.Context, problem *Problem) {
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// abortWithCode writes a problem details response for the code
+func abortWithCode(c *gin.Context, code errors.Code, detail string) {
+	abortWithProblem(c, newProblem(c, code, detail))
+}
+
+// abortWithError maps the error to its stable code and writes the problem
+// details response. Details of uncoded errors are withheld from the client
+// and recorded for the access log instead
+func abortWithError(c *gin.Context, err error) {
+	code := errors.CodeOf(err)
+
+	detail := err.Error()
+	switch code {
+	case errors.CodeInternal, errors.CodeStorageUnavailable, errors.CodePublishFailed, errors.CodeGeolocationFailed, errors.CodeTimeout:
+		detail = ""
+		c.Error(err)
+	}
+
+	problem := newProblem(c, code, detail)
+	for _, fieldErr := range errors.FieldsOf(err) {
+		problem.Errors = append(problem.Errors, FieldProblem{
+			Pointer: "/" + fieldErr.Field(),
+			Code:    fieldErr.Code(),
+			Detail:  fieldErr.Error(),
+		})
+	}
+
+	abortWithProblem(c, problem)
+}
+
+// abortWithBindingError maps a request decoding error to its problem details
+func abortWithBindingError(c *gin.Context, err error) {
+	switch e := err.(type) {
+	case errors.Coded:
+		abortWithError(c, e)
+	case *json.SyntaxError:
+		abortWithCode(c, errors.CodeMalformedRequest, e.Error())
+	case *json.UnmarshalTypeError:
+		problem := newProblem(c, errors.CodeMalformedRequest, e.Error())
+		problem.Errors = []FieldProblem{{
+			Pointer: "/" + e.Field,
+			Code:    errors.CodeMalformedRequest,
+			Detail:  "expected " + e.Type.String(),
+		}}
+		abortWithProblem(c, problem)
+	default:
+		abortWithCode(c, errors.CodeMalformedRequest, err.Error())
+	}
+}
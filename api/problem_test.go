@@ -0,0 +1,123 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/txross1993/superman-api/errors"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/superman"
+)
+
+func TestProblemResponses(t *testing.T) {
+	sqlDB, cleanup := newTestDB(t)
+	defer cleanup()
+
+	closedDB, closeDB := newTestDB(t)
+	closeDB()
+
+	valid := `{"username": "bob", "unix_timestamp": 1514764800, "event_uuid": "85ad929a-db03-4bf4-9541-8f728fa12e42", "ip_address": "206.81.252.200"}`
+
+	tests := map[string]struct {
+		service  *superman.Service
+		body     string
+		status   int
+		code     errors.Code
+		pointers []string
+	}{
+		"invalid ip": {
+			body:     `{"username": "bob", "unix_timestamp": 1514764800, "event_uuid": "85ad929a-db03-4bf4-9541-8f728fa12e42", "ip_address": "206.81.252.432"}`,
+			status:   400,
+			code:     errors.CodeInvalidIP,
+			pointers: []string{"/ip_address"},
+		},
+		"missing field": {
+			body:     `{"unix_timestamp": 1514764800, "event_uuid": "85ad929a-db03-4bf4-9541-8f728fa12e42", "ip_address": "206.81.252.200"}`,
+			status:   400,
+			code:     errors.CodeMissingField,
+			pointers: []string{"/username"},
+		},
+		"invalid timestamp": {
+			body:     `{"username": "bob", "unix_timestamp": "yesterday", "event_uuid": "85ad929a-db03-4bf4-9541-8f728fa12e42", "ip_address": "206.81.252.200"}`,
+			status:   400,
+			code:     errors.CodeInvalidTimestamp,
+			pointers: []string{"/unix_timestamp"},
+		},
+		"every violation reported": {
+			body:     `{"username": "", "unix_timestamp": 1514764800, "event_uuid": "abc", "ip_address": "206.81.252.432"}`,
+			status:   400,
+			code:     errors.CodeInvalidField,
+			pointers: []string{"/event_uuid", "/username", "/ip_address"},
+		},
+		"malformed json": {
+			body:   `{"username": "bob",`,
+			status: 400,
+			code:   errors.CodeMalformedRequest,
+		},
+		"geolocation failed": {
+			service: superman.NewService(&failingGeo{}, sqlDB),
+			body:    valid,
+			status:  500,
+			code:    errors.CodeGeolocationFailed,
+		},
+		"storage unavailable": {
+			service: superman.NewService(&fakeGeo{}, closedDB),
+			body:    valid,
+			status:  503,
+			code:    errors.CodeStorageUnavailable,
+		},
+		"publish failed": {
+			service: superman.NewService(&fakeGeo{}, sqlDB, superman.WithNotifier(failingNotifier{})),
+			body:    valid,
+			status:  502,
+			code:    errors.CodePublishFailed,
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case %s", name)
+		service := test.service
+		if service == nil {
+			service = superman.NewService(&fakeGeo{}, sqlDB)
+		}
+		api := NewAPI(Config{Superman: service})
+
+		req := newRequest(t, "POST", "/v1/", bytes.NewReader([]byte(test.body)))
+		req.Header.Set(requestIDHeader, "test-"+name[:4])
+		resp := makeRequest(api.router, req)
+
+		var got Problem
+		if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, test.status, resp.Code)
+		assert.Equal(t, problemContentType, resp.Header().Get("Content-Type"))
+		assert.Equal(t, test.code, got.Code)
+		assert.Equal(t, test.status, got.Status)
+		assert.Equal(t, "test-"+name[:4], got.RequestID)
+		var pointers []string
+		for _, fieldProblem := range got.Errors {
+			pointers = append(pointers, fieldProblem.Pointer)
+		}
+		assert.Equal(t, test.pointers, pointers)
+	}
+}
+
+// failingNotifier fails to publish every verdict
+type failingNotifier struct{}
+
+func (failingNotifier) Notify(ctx context.Context, verdicts []*models.Verdict) error {
+	return fmt.Errorf("broker unreachable")
+}
+
+// failingGeo fails every geoencoding request
+type failingGeo struct{}
+
+func (f *failingGeo) GetCoordinatesFromIP(ctx context.Context, ip string) (*models.Geography, error) {
+	return nil, fmt.Errorf("lookup %s: corrupt database", ip)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/txross1993/superman-api/auth"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/superman"
+	"github.com/txross1993/superman-api/testdata"
+)
+
+func TestUserProfile(t *testing.T) {
+	sqlDB, cleanup := newTestDB(t)
+	defer cleanup()
+
+	plaintext := map[string]string{}
+	for _, key := range []*models.APIKey{
+		{Name: "collector", Scopes: "ingest"},
+		{Name: "dashboard", Scopes: "read"},
+	} {
+		pt, _ := auth.NewKey()
+		key.KeyHash = auth.Hash(pt)
+		key.Prefix = auth.Prefix(pt)
+		if err := sqlDB.CreateAPIKey(key); err != nil {
+			t.Fatal(err)
+		}
+		plaintext[key.Name] = pt
+	}
+
+	api := NewAPI(Config{
+		Superman: superman.NewService(&fakeGeo{}, sqlDB),
+		Keys:     sqlDB,
+	})
+
+	current := testdata.GenerateCurrentEvent()
+	subsequent := testdata.GenerateSubsequentEvent(false, false)
+	for _, event := range []*models.UserIPAccessEvent{current, subsequent, current} {
+		b, _ := json.Marshal(event)
+		req := newRequest(t, "POST", "/v1/", bytes.NewReader(b))
+		req.Header.Set("X-API-Key", plaintext["collector"])
+		resp := makeRequest(api.router, req)
+		assert.Equal(t, 201, resp.Code)
+	}
+
+	tests := map[string]struct {
+		username      string
+		key           string
+		want          int
+		expectedCount int64
+	}{
+		"known user":    {username: testdata.TestUser, key: "dashboard", want: 200, expectedCount: 2},
+		"unknown user":  {username: "nobody", key: "dashboard", want: 404},
+		"missing scope": {username: testdata.TestUser, key: "collector", want: 403},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case %s", name)
+		req := newRequest(t, "GET", "/v1/users/"+test.username+"/profile", nil)
+		req.Header.Set("X-API-Key", plaintext[test.key])
+		resp := makeRequest(api.router, req)
+		assert.Equal(t, test.want, resp.Code)
+
+		if test.want != 200 {
+			continue
+		}
+
+		var profile models.UserProfile
+		if err := json.Unmarshal(resp.Body.Bytes(), &profile); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, test.expectedCount, profile.LoginCount)
+		assert.Equal(t, 1, len(profile.Locations))
+		assert.Equal(t, test.expectedCount, profile.Locations[0].Count)
+	}
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"bytes"
+	"expvar"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+
+	"github.com/txross1993/superman-api/errors"
+)
+
+// idleLimiterTTL is how long a client's token bucket is retained after its
+// last request before it is discarded
+const idleLimiterTTL = 10 * time.Minute
+
+// metrics reports the configured limits and how often they are enforced
+var metrics = expvar.NewMap("superman")
+
+// RateLimit configures the token bucket applied to each api client. A zero
+// RequestsPerSecond disables rate limiting
+type RateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// rateLimiter holds a token bucket per api client or source ip address
+type rateLimiter struct {
+	RateLimit
+	mu        sync.Mutex
+	clients   map[string]*clientLimiter
+	lastSweep time.Time
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func newRateLimiter(cfg RateLimit) *rateLimiter {
+	if cfg.Burst < 1 {
+		cfg.Burst = int(math.Ceil(cfg.RequestsPerSecond))
+	}
+
+	return &rateLimiter{
+		RateLimit: cfg,
+		clients:   map[string]*clientLimiter{},
+		lastSweep: time.Now(),
+	}
+}
+
+// reserve takes a token from the client's bucket and returns how long the
+// client must wait before the request would be allowed
+func (r *rateLimiter) reserve(client string, now time.Time) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reservation := r.bucket(client, now).ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		reservation.CancelAt(now)
+	}
+	return delay
+}
+
+// wait returns how long the client must wait before its bucket holds a
+// token, without taking one
+func (r *rateLimiter) wait(client string, now time.Time) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reservation := r.bucket(client, now).ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	reservation.CancelAt(now)
+	return delay
+}
+
+// bucket returns the client's token bucket, discarding the buckets of
+// clients idle for longer than idleLimiterTTL. The caller holds r.mu
+func (r *rateLimiter) bucket(client string, now time.Time) *rate.Limiter {
+	if now.Sub(r.lastSweep) > idleLimiterTTL {
+		for name, cl := range r.clients {
+			if now.Sub(cl.lastSeen) > idleLimiterTTL {
+				delete(r.clients, name)
+			}
+		}
+		r.lastSweep = now
+	}
+
+	cl, ok := r.clients[client]
+	if !ok {
+		cl = &clientLimiter{limiter: rate.NewLimiter(rate.Limit(r.RequestsPerSecond), r.Burst)}
+		r.clients[client] = cl
+	}
+	cl.lastSeen = now
+	return cl.limiter
+}
+
+// rateLimit rejects requests from clients which have exhausted their token
+// bucket. Clients are identified by api key name, falling back to the
+// source ip address when the request is unauthenticated
+func (api *API) rateLimit() gin.HandlerFunc {
+	if api.RateLimit.RequestsPerSecond <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	limiter := newRateLimiter(api.RateLimit)
+	metrics.Set("rate_limit_requests_per_second", expvarFloat(limiter.RequestsPerSecond))
+	metrics.Set("rate_limit_burst", expvarInt(int64(limiter.Burst)))
+
+	return func(c *gin.Context) {
+		key := client(c)
+		id := "ip:" + c.ClientIP()
+		if key != nil {
+			id = "client:" + key.Name
+		}
+
+		if delay := limiter.reserve(id, time.Now()); delay > 0 {
+			metrics.Add("rate_limited_requests", 1)
+			if key != nil {
+				// source ips are unbounded so only named clients are broken out
+				metrics.Add("rate_limited_requests."+key.Name, 1)
+			}
+			abortRateLimited(c, delay)
+			return
+		}
+
+		metrics.Add("rate_allowed_requests", 1)
+		c.Next()
+	}
+}
+
+// authFailureLimiter returns the token buckets spent by each source ip
+// address presenting a missing or invalid api key, or nil when rate limiting
+// or authentication is disabled. Failed authentications happen before a
+// client is known, so without it keys could be guessed at any rate
+func (api *API) authFailureLimiter() *rateLimiter {
+	if api.RateLimit.RequestsPerSecond <= 0 || api.Keys == nil {
+		return nil
+	}
+	return newRateLimiter(api.RateLimit)
+}
+
+// abortRateLimited rejects a request which may be retried after the delay
+func abortRateLimited(c *gin.Context, delay time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+	abortWithCode(c, errors.CodeRateLimited, "retry after "+delay.Round(time.Second).String())
+}
+
+// limitBody rejects requests whose body exceeds the configured maximum size.
+// A zero MaxBodyBytes disables the limit
+func (api *API) limitBody() gin.HandlerFunc {
+	if api.MaxBodyBytes <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	max := api.MaxBodyBytes
+	metrics.Set("max_body_bytes", expvarInt(max))
+
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > max {
+			api.rejectOversize(c)
+			return
+		}
+
+		body, err := ioutil.ReadAll(io.LimitReader(c.Request.Body, max+1))
+		if err != nil || int64(len(body)) > max {
+			api.rejectOversize(c)
+			return
+		}
+
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
+
+func (api *API) rejectOversize(c *gin.Context) {
+	metrics.Add("oversize_requests", 1)
+	abortWithCode(c, errors.CodeRequestTooLarge, "request body exceeds "+strconv.FormatInt(api.MaxBodyBytes, 10)+" bytes")
+}
+
+func expvarInt(v int64) *expvar.Int {
+	i := new(expvar.Int)
+	i.Set(v)
+	return i
+}
+
+func expvarFloat(v float64) *expvar.Float {
+	f := new(expvar.Float)
+	f.Set(v)
+	return f
+}
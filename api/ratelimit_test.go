@@ -0,0 +1,107 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/txross1993/superman-api/auth"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/superman"
+	"github.com/txross1993/superman-api/testdata"
+)
+
+func TestRateLimit(t *testing.T) {
+	sqlDB, cleanup := newTestDB(t)
+	defer cleanup()
+
+	api := NewAPI(Config{
+		Superman:     superman.NewService(&fakeGeo{}, sqlDB),
+		RateLimit:    RateLimit{RequestsPerSecond: 0.01, Burst: 2},
+		MaxBodyBytes: 512,
+	})
+
+	post := func(remoteAddr string, body []byte) (int, string) {
+		req := newRequest(t, "POST", "/v1/", bytes.NewReader(body))
+		req.RemoteAddr = remoteAddr
+		resp := makeRequest(api.router, req)
+		return resp.Code, resp.Header().Get("Retry-After")
+	}
+
+	event, _ := json.Marshal(testdata.GenerateCurrentEvent())
+
+	t.Run("token bucket per source ip", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			code, _ := post("10.0.0.1:1234", event)
+			assert.Equal(t, 201, code)
+		}
+
+		code, retryAfter := post("10.0.0.1:1234", event)
+		assert.Equal(t, 429, code)
+		assert.NotEqual(t, "", retryAfter)
+
+		code, _ = post("10.0.0.2:1234", event)
+		assert.Equal(t, 201, code)
+	})
+
+	t.Run("request body size", func(t *testing.T) {
+		oversize := []byte(`{"username": "` + strings.Repeat("a", 1024) + `"}`)
+		code, _ := post("10.0.0.3:1234", oversize)
+		assert.Equal(t, 413, code)
+	})
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	limiter := newRateLimiter(RateLimit{RequestsPerSecond: 1, Burst: 1})
+	now := time.Now()
+
+	assert.Equal(t, time.Duration(0), limiter.reserve("client:a", now))
+	assert.NotEqual(t, time.Duration(0), limiter.reserve("client:a", now))
+	assert.Equal(t, time.Duration(0), limiter.reserve("client:a", now.Add(time.Second)))
+
+	limiter.reserve("client:b", now)
+	limiter.reserve("client:c", now.Add(2*idleLimiterTTL))
+	_, ok := limiter.clients["client:b"]
+	assert.Equal(t, false, ok)
+}
+
+// TestRateLimitAuthFailures tests that missing and invalid api keys spend
+// the source ip address's bucket ahead of authentication, so an address
+// guessing keys is throttled even once it presents a valid one
+func TestRateLimitAuthFailures(t *testing.T) {
+	sqlDB, cleanup := newTestDB(t)
+	defer cleanup()
+
+	plaintext, err := auth.NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sqlDB.CreateAPIKey(&models.APIKey{Name: "collector", Scopes: "ingest", KeyHash: auth.Hash(plaintext), Prefix: auth.Prefix(plaintext)}); err != nil {
+		t.Fatal(err)
+	}
+
+	api := NewAPI(Config{
+		Superman:  superman.NewService(&fakeGeo{}, sqlDB),
+		Keys:      sqlDB,
+		RateLimit: RateLimit{RequestsPerSecond: 0.01, Burst: 2},
+	})
+
+	post := func(remoteAddr, key string) int {
+		event, _ := json.Marshal(testdata.GenerateCurrentEvent())
+		req := newRequest(t, "POST", "/v1/", bytes.NewReader(event))
+		req.RemoteAddr = remoteAddr
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		return makeRequest(api.router, req).Code
+	}
+
+	assert.Equal(t, 401, post("10.0.0.4:1234", ""))
+	assert.Equal(t, 401, post("10.0.0.4:1234", "guessed"))
+	assert.Equal(t, 429, post("10.0.0.4:1234", "guessed"))
+	assert.Equal(t, 429, post("10.0.0.4:1234", plaintext))
+	assert.Equal(t, 201, post("10.0.0.5:1234", plaintext))
+}
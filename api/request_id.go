@@ -0,0 +1,37 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/txross1993/superman-api/logging"
+)
+
+const (
+	// requestIDHeader carries the request id in both directions
+	requestIDHeader = "X-Request-ID"
+	// requestIDKey is the gin context key holding the request id
+	requestIDKey = "superman.request_id"
+)
+
+// tagRequest assigns each request an id, accepting a well formed caller
+// provided X-Request-ID or generating one, and echoes it in the response.
+// The request's context carries a logger adding the id to every line
+func tagRequest(logger *logging.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !logging.ValidRequestID(id) {
+			id = logging.NewRequestID()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(requestIDHeader, id)
+		ctx := logging.NewContext(c.Request.Context(), logger.With("request_id", id))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// requestID returns the id assigned to the request
+func requestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/txross1993/superman-api/errors"
+	"github.com/txross1993/superman-api/stream"
+)
+
+// defaultHeartbeat is how often an idle stream sends a comment to keep
+// proxies from closing it, unless configured
+const defaultHeartbeat = 15 * time.Second
+
+// StreamVerdicts pushes the verdicts matching the query filters to the
+// client as server-sent events until the client disconnects. A client
+// which falls too far behind is sent a dropped event and disconnected
+func (api *API) StreamVerdicts(c *gin.Context) {
+	filter, err := streamFilter(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	heartbeat := api.StreamHeartbeat
+	if heartbeat <= 0 {
+		heartbeat = defaultHeartbeat
+	}
+
+	sub := api.Stream.Subscribe(filter)
+	defer api.Stream.Unsubscribe(sub)
+
+	w := c.Writer
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	w.Flush()
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			w.Flush()
+		case verdict, ok := <-sub.C:
+			if !ok {
+				if sub.Dropped() {
+					fmt.Fprint(w, "event: dropped\ndata: {\"reason\":\"subscriber fell behind\"}\n\n")
+					w.Flush()
+				}
+				return
+			}
+
+			data, err := json.Marshal(verdict)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(w, "id: %s\nevent: verdict\ndata: %s\n\n", verdict.EventUUID, data)
+			w.Flush()
+		}
+	}
+}
+
+// streamFilter reads the username, suspicious and min_score query
+// parameters, limited to the verdicts of the request's tenant
+func streamFilter(c *gin.Context) (stream.Filter, error) {
+	filter := stream.Filter{Tenant: tenant(c)}
+	var violations errors.Validation
+
+	filter.Username = c.Query("username")
+
+	if value := c.Query("suspicious"); value != "" {
+		suspicious, err := strconv.ParseBool(value)
+		if err != nil {
+			violations.Add(&errors.InvalidField{Name: "suspicious", Reason: "must be true or false"})
+		}
+		filter.SuspiciousOnly = suspicious
+	}
+
+	if value := c.Query("min_score"); value != "" {
+		score, err := strconv.ParseFloat(value, 64)
+		if err != nil || score < 0 || score > 1 {
+			violations.Add(&errors.InvalidField{Name: "min_score", Reason: "must be a number between 0 and 1"})
+		}
+		filter.MinScore = score
+	}
+
+	return filter, violations.ErrorOrNil()
+}
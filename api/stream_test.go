@@ -0,0 +1,111 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/stream"
+	"github.com/txross1993/superman-api/superman"
+	"github.com/txross1993/superman-api/testdata"
+)
+
+func TestStreamVerdicts(t *testing.T) {
+	sqlDB, cleanup := newTestDB(t)
+	defer cleanup()
+
+	broker := stream.NewBroker(stream.DefaultBuffer)
+	api := NewAPI(Config{
+		Superman:        superman.NewService(&fakeGeo{}, sqlDB, superman.WithNotifier(broker)),
+		Stream:          broker,
+		StreamHeartbeat: 20 * time.Millisecond,
+	})
+	server := httptest.NewServer(api.router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/v1/stream?min_score=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, 400, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/v1/stream?username=" + testdata.TestUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	events := readEvents(resp)
+
+	other := testdata.GenerateCurrentEvent()
+	other.Username = "alice"
+	bob := testdata.GenerateCurrentEvent()
+	for _, event := range []*models.UserIPAccessEvent{other, bob} {
+		b, _ := json.Marshal(event)
+		posted, err := http.Post(server.URL+"/v1/", "application/json", bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		posted.Body.Close()
+		assert.Equal(t, 201, posted.StatusCode)
+	}
+
+	heartbeats := 0
+	var verdicts []models.Verdict
+	timeout := time.After(5 * time.Second)
+	for heartbeats < 2 || len(verdicts) < 1 {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatal("stream closed")
+			}
+			if event == ": heartbeat" {
+				heartbeats++
+				continue
+			}
+			if strings.HasPrefix(event, "id: ") {
+				data := event[strings.Index(event, "data: ")+len("data: "):]
+				var verdict models.Verdict
+				if err := json.Unmarshal([]byte(data), &verdict); err != nil {
+					t.Fatal(err)
+				}
+				verdicts = append(verdicts, verdict)
+			}
+		case <-timeout:
+			t.Fatalf("received %d heartbeats and %d verdicts", heartbeats, len(verdicts))
+		}
+	}
+
+	assert.Equal(t, 1, len(verdicts))
+	assert.Equal(t, bob.EventUUID, verdicts[0].EventUUID)
+	assert.Equal(t, testdata.TestUser, verdicts[0].Username)
+}
+
+// readEvents splits the server-sent event stream into its blank line
+// separated events
+func readEvents(resp *http.Response) <-chan string {
+	events := make(chan string, 64)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		var lines []string
+		for scanner.Scan() {
+			if line := scanner.Text(); line != "" {
+				lines = append(lines, line)
+				continue
+			}
+			events <- strings.Join(lines, "\n")
+			lines = nil
+		}
+	}()
+	return events
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/txross1993/superman-api/logging"
+	"github.com/txross1993/superman-api/tracing"
+)
+
+// traceRequest serves each request within a span continuing any trace
+// context sent by the caller, and adds the trace id to the request's log
+// lines when the trace is sampled
+func traceRequest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		name := "HTTP " + c.Request.Method
+		if route != "" {
+			name = c.Request.Method + " " + route
+		}
+
+		ctx, span := tracing.StartServer(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header), name,
+			attribute.String("http.method", c.Request.Method),
+			attribute.String("http.route", route),
+			attribute.String("http.request_id", requestID(c)),
+		)
+		defer span.End()
+
+		if id := tracing.TraceID(ctx); id != "" {
+			ctx = logging.NewContext(ctx, logging.FromContext(ctx).With("trace_id", id))
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.status_code", status))
+		if key := client(c); key != nil {
+			span.SetAttributes(attribute.String("http.client", key.Name))
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
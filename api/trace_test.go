@@ -0,0 +1,84 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/txross1993/superman-api/logging"
+	"github.com/txross1993/superman-api/superman"
+	"github.com/txross1993/superman-api/tracing"
+)
+
+func TestTracing(t *testing.T) {
+	sqlDB, cleanup := newTestDB(t)
+	defer cleanup()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer provider.Shutdown(context.Background())
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	defer otel.SetTextMapPropagator(otel.GetTextMapPropagator())
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var out bytes.Buffer
+	api := NewAPI(Config{
+		Superman: superman.NewService(&fakeGeo{}, sqlDB),
+		Logger:   logging.New(&out, logging.LevelInfo),
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	body := `{"username": "bob", "unix_timestamp": 1514764800, "event_uuid": "85ad929a-db03-4bf4-9541-8f728fa12e42", "ip_address": "206.81.252.200"}`
+	req := newRequest(t, "POST", "/v1/", bytes.NewReader([]byte(body)))
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	resp := makeRequest(api.router, req)
+	assert.Equal(t, 201, resp.Code)
+
+	// every span continues the caller's trace, with the analysis nested in
+	// the request and the lookups nested in the analysis
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		assert.Equal(t, traceID, span.SpanContext().TraceID().String())
+		spans[span.Name()] = span
+	}
+
+	request, analysis := spans["POST /v1/"], spans["superman.AnalyzeEvent"]
+	if request == nil || analysis == nil {
+		t.Fatalf("missing spans, got %v", spans)
+	}
+	assert.Equal(t, request.SpanContext().SpanID(), analysis.Parent().SpanID())
+	for _, name := range []string{"db find or create event", "db find user profile", "db save user profile", "db find neighboring events"} {
+		span, ok := spans[name]
+		if !ok {
+			t.Fatalf("missing span %s", name)
+		}
+		assert.Equal(t, analysis.SpanContext().SpanID(), span.Parent().SpanID())
+	}
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range append(request.Attributes(), analysis.Attributes()...) {
+		attrs[kv.Key] = kv.Value
+	}
+	assert.Equal(t, int64(201), attrs["http.status_code"].AsInt64())
+	assert.Equal(t, "85ad929a-db03-4bf4-9541-8f728fa12e42", attrs["event.uuid"].AsString())
+	assert.Equal(t, tracing.HashUsername("", "bob"), attrs["user.hash"].AsString())
+	assert.Equal(t, false, attrs["verdict.suspicious"].AsBool())
+
+	// the request's log lines carry the trace id
+	var line map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out.String())), &line); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, traceID, line["trace_id"])
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/txross1993/superman-api/errors"
+	"github.com/txross1993/superman-api/models"
+)
+
+type deadLetters interface {
+	ListDeadDeliveries(string) ([]models.WebhookDelivery, error)
+	RetryDeadDelivery(string, uint, time.Time) (bool, error)
+}
+
+// ListDeadLetters reports the webhook deliveries of the request's tenant
+// which exhausted their attempts
+func (api *API) ListDeadLetters(c *gin.Context) {
+	deliveries, err := api.DeadLetters.ListDeadDeliveries(tenant(c))
+	if err != nil {
+		abortWithError(c, &errors.StorageUnavailable{Op: "list dead letters", Err: err})
+		return
+	}
+
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// RetryDeadLetter schedules a dead webhook delivery of the request's tenant
+// for a fresh round of attempts. Deliveries of other tenants are not found
+func (api *API) RetryDeadLetter(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		abortWithCode(c, errors.CodeNotFound, fmt.Sprintf("no dead letter with id %q", c.Param("id")))
+		return
+	}
+
+	found, err := api.DeadLetters.RetryDeadDelivery(tenant(c), uint(id), time.Now())
+	if err != nil {
+		abortWithError(c, &errors.StorageUnavailable{Op: "retry dead letter", Err: err})
+		return
+	}
+
+	if !found {
+		abortWithCode(c, errors.CodeNotFound, fmt.Sprintf("no dead letter with id %d", id))
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/txross1993/superman-api/auth"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/superman"
+)
+
+func TestDeadLetters(t *testing.T) {
+	sqlDB, cleanup := newTestDB(t)
+	defer cleanup()
+
+	plaintext := map[string]string{}
+	for _, key := range []*models.APIKey{
+		{Name: "operator", Scopes: "admin"},
+		{Name: "dashboard", Scopes: "read"},
+		{Name: "globex-operator", Scopes: "admin", Tenant: "globex"},
+	} {
+		pt, _ := auth.NewKey()
+		key.KeyHash = auth.Hash(pt)
+		key.Prefix = auth.Prefix(pt)
+		if err := sqlDB.CreateAPIKey(key); err != nil {
+			t.Fatal(err)
+		}
+		plaintext[key.Name] = pt
+	}
+
+	deadLetter := func(tenant, uuid string) *models.WebhookDelivery {
+		dead := time.Now().UTC()
+		delivery := &models.WebhookDelivery{
+			Destination:   "http://127.0.0.1:1/hook",
+			DedupeKey:     tenant + ":" + uuid,
+			EventUUID:     uuid,
+			Tenant:        tenant,
+			Payload:       `{"id":"` + uuid + `"}`,
+			NextAttemptAt: dead,
+		}
+		if err := sqlDB.EnqueueDelivery(context.Background(), delivery); err != nil {
+			t.Fatal(err)
+		}
+		delivery.Attempts = 15
+		delivery.DeadAt = &dead
+		if err := sqlDB.SaveDelivery(context.Background(), delivery); err != nil {
+			t.Fatal(err)
+		}
+		return delivery
+	}
+	delivery := deadLetter(models.DefaultTenant, "a")
+	globex := deadLetter("globex", "b")
+
+	api := NewAPI(Config{
+		Superman:    superman.NewService(&fakeGeo{}, sqlDB),
+		Keys:        sqlDB,
+		DeadLetters: sqlDB,
+	})
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		key          string
+		want         int
+		expectedDead int
+	}{
+		{name: "missing scope", method: "GET", path: "/v1/webhooks/dead-letters", key: "dashboard", want: 403},
+		{name: "list", method: "GET", path: "/v1/webhooks/dead-letters", key: "operator", want: 200, expectedDead: 1},
+		{name: "list another tenant", method: "GET", path: "/v1/webhooks/dead-letters", key: "globex-operator", want: 200, expectedDead: 1},
+		{name: "retry unknown", method: "POST", path: "/v1/webhooks/dead-letters/999/retry", key: "operator", want: 404},
+		{name: "retry of another tenant", method: "POST", path: fmt.Sprintf("/v1/webhooks/dead-letters/%d/retry", globex.ID), key: "operator", want: 404},
+		{name: "retry by another tenant", method: "POST", path: fmt.Sprintf("/v1/webhooks/dead-letters/%d/retry", delivery.ID), key: "globex-operator", want: 404},
+		{name: "retry", method: "POST", path: fmt.Sprintf("/v1/webhooks/dead-letters/%d/retry", delivery.ID), key: "operator", want: 202},
+		{name: "list after retry", method: "GET", path: "/v1/webhooks/dead-letters", key: "operator", want: 200},
+		{name: "retry again", method: "POST", path: fmt.Sprintf("/v1/webhooks/dead-letters/%d/retry", delivery.ID), key: "operator", want: 404},
+	}
+
+	tenantOf := map[string]string{"globex-operator": "globex"}
+	for _, test := range tests {
+		t.Logf("Running test case %s", test.name)
+		req := newRequest(t, test.method, test.path, nil)
+		req.Header.Set("X-API-Key", plaintext[test.key])
+		resp := makeRequest(api.router, req)
+		assert.Equal(t, test.want, resp.Code)
+
+		if test.method == "GET" && test.want == 200 {
+			var deliveries []models.WebhookDelivery
+			if err := json.Unmarshal(resp.Body.Bytes(), &deliveries); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expectedDead, len(deliveries))
+			for _, d := range deliveries {
+				assert.Equal(t, tenantOf[test.key], d.Tenant)
+			}
+		}
+	}
+
+	due, err := sqlDB.DueDeliveries(context.Background(), time.Now(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(due))
+	assert.Equal(t, delivery.ID, due[0].ID)
+	assert.Equal(t, 0, due[0].Attempts)
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// keyPrefix marks plaintext api keys so they are recognizable in config
+// files and secret scanners
+const keyPrefix = "sk_"
+
+// prefixLen is the number of plaintext characters kept to identify a key
+// in listings without revealing it
+const prefixLen = 8
+
+// NewKey generates a random plaintext api key
+func NewKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return keyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Hash returns the digest of a plaintext api key as stored in the database.
+// Keys are high entropy random values, so a fast digest is sufficient
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Prefix returns the identifying leading characters of a plaintext key
+func Prefix(key string) string {
+	key = strings.TrimPrefix(key, keyPrefix)
+	if len(key) > prefixLen {
+		key = key[:prefixLen]
+	}
+	return keyPrefix + key
+}
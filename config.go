@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+
+	"github.com/txross1993/superman-api/config"
+	"github.com/txross1993/superman-api/logging"
+	"github.com/txross1993/superman-api/superman"
+)
+
+const configUsage = `usage: superman-api config <command> [flags]
+
+commands:
+  print  validate and print the resolved configuration with secrets redacted
+`
+
+// runConfig dispatches the configuration subcommands
+func runConfig(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(configUsage)
+	}
+
+	switch args[0] {
+	case "print":
+		cfg, err := config.Load(flag.NewFlagSet("config print", flag.ExitOnError), args[1:], os.Getenv)
+		if err != nil {
+			return err
+		}
+		return cfg.Print(out)
+	}
+
+	return fmt.Errorf("unknown config command %q\n%s", args[0], configUsage)
+}
+
+// reloadOnHangup resolves the configuration again whenever the process
+// receives SIGHUP and applies its policy settings to the service. Other
+// settings take effect on restart, and an invalid configuration leaves the
+// running policies in place
+func reloadOnHangup(args []string, current *config.Config, service *superman.Service) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+
+	logger := logging.Default()
+	for range hangups {
+		fs := flag.NewFlagSet("reload", flag.ContinueOnError)
+		fs.SetOutput(ioutil.Discard)
+		reloaded, err := config.Load(fs, args, os.Getenv)
+		if err != nil {
+			logger.Error("keeping the running policy", "error", err)
+			continue
+		}
+
+		base, tenants, err := reloaded.Policies()
+		if err != nil {
+			logger.Error("keeping the running policy", "error", err)
+			continue
+		}
+		service.SetPolicies(base, tenants)
+		logger.Info("reloaded the policy", "tenant_policies", len(tenants))
+
+		current.Policy = reloaded.Policy
+		if !reflect.DeepEqual(current, reloaded) {
+			logger.Warn("settings other than the policy changed and take effect on restart")
+		}
+	}
+}
@@ -0,0 +1,420 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/txross1993/superman-api/db"
+	"github.com/txross1993/superman-api/logging"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/notify"
+	"github.com/txross1993/superman-api/stream"
+	"github.com/txross1993/superman-api/superman"
+	"github.com/txross1993/superman-api/tracing"
+)
+
+// redacted replaces secrets when a configuration is printed
+const redacted = "REDACTED"
+
+// Config is the complete configuration of the api and consume mode
+type Config struct {
+	Server     Server     `yaml:"server"`
+	Storage    Storage    `yaml:"storage"`
+	Limits     Limits     `yaml:"limits"`
+	Validation Validation `yaml:"validation"`
+	Policy     Policy     `yaml:"policy"`
+	Webhooks   Webhooks   `yaml:"webhooks"`
+	Stream     Stream     `yaml:"stream"`
+	Kafka      Kafka      `yaml:"kafka"`
+	Log        Log        `yaml:"log"`
+	Tracing    Tracing    `yaml:"tracing"`
+}
+
+// Server configures the addresses the http and grpc apis are served on and
+// the time allowed to serve each request
+type Server struct {
+	Host           string        `yaml:"host"`
+	Port           string        `yaml:"port"`
+	GRPCPort       string        `yaml:"grpcPort"`
+	RequestTimeout time.Duration `yaml:"requestTimeout"`
+}
+
+// Storage locates the sqlite database and the GeoLite2 databases, and sizes
+// the connections to the sqlite database
+type Storage struct {
+	DBPath          string        `yaml:"dbPath"`
+	GeoDB           string        `yaml:"geoDB"`
+	ASNDB           string        `yaml:"asnDB"`
+	MaxReaders      int           `yaml:"maxReaders"`
+	MaxIdleReaders  int           `yaml:"maxIdleReaders"`
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime"`
+	BusyTimeout     time.Duration `yaml:"busyTimeout"`
+}
+
+// Limits bounds the requests of each api client
+type Limits struct {
+	RateLimit    float64 `yaml:"rateLimit"`
+	RateBurst    int     `yaml:"rateBurst"`
+	MaxBodyBytes int64   `yaml:"maxBodyBytes"`
+}
+
+// Validation bounds the login events accepted
+type Validation struct {
+	MaxUsernameLength int           `yaml:"maxUsernameLength"`
+	MaxPastSkew       time.Duration `yaml:"maxPastSkew"`
+	MaxFutureSkew     time.Duration `yaml:"maxFutureSkew"`
+}
+
+// Policy configures how logins are judged, and is reloaded on SIGHUP.
+// TenantPolicies names a json file of the fields each tenant overrides
+type Policy struct {
+	MaxSpeedMPH             int64         `yaml:"maxSpeedMph"`
+	MinTimeWindow           time.Duration `yaml:"minTimeWindow"`
+	MaxWindowDistanceMiles  float64       `yaml:"maxWindowDistanceMiles"`
+	Window                  int           `yaml:"window"`
+	ConcurrentSessionPeriod time.Duration `yaml:"concurrentSessionPeriod"`
+	ClusterRadiusMiles      float64       `yaml:"clusterRadiusMiles"`
+	MinAlternations         int           `yaml:"minAlternations"`
+	MinProfileLogins        int64         `yaml:"minProfileLogins"`
+	FamiliarRadiusMiles     float64       `yaml:"familiarRadiusMiles"`
+	MinHourHistory          int64         `yaml:"minHourHistory"`
+	MinHourConfidence       float64       `yaml:"minHourConfidence"`
+	AllowedNetworks         []string      `yaml:"allowedNetworks"`
+	TenantPolicies          string        `yaml:"tenantPolicies"`
+}
+
+// Webhooks configures the urls notified of suspicious logins
+type Webhooks struct {
+	URLs           []string      `yaml:"urls"`
+	Secret         string        `yaml:"secret"`
+	MaxAttempts    int           `yaml:"maxAttempts"`
+	InitialBackoff time.Duration `yaml:"initialBackoff"`
+	MaxBackoff     time.Duration `yaml:"maxBackoff"`
+}
+
+// Stream configures the live verdict streams
+type Stream struct {
+	Heartbeat time.Duration `yaml:"heartbeat"`
+	Buffer    int           `yaml:"buffer"`
+}
+
+// Kafka configures the topics and consumer group of consume mode
+type Kafka struct {
+	Brokers      []string `yaml:"brokers"`
+	Topic        string   `yaml:"topic"`
+	Group        string   `yaml:"group"`
+	VerdictTopic string   `yaml:"verdictTopic"`
+}
+
+// Log configures the structured log written to stderr
+type Log struct {
+	Level string `yaml:"level"`
+}
+
+// Tracing configures where spans are exported and the ratio of new traces
+// sampled
+type Tracing struct {
+	Exporter    string  `yaml:"exporter"`
+	Endpoint    string  `yaml:"endpoint"`
+	Insecure    bool    `yaml:"insecure"`
+	SampleRatio float64 `yaml:"sampleRatio"`
+}
+
+// Default returns the configuration applied where nothing is configured
+func Default() *Config {
+	policy := superman.DefaultPolicy()
+	validation := models.DefaultValidationPolicy()
+	backoff := notify.DefaultBackoff()
+	pool := db.DefaultPool()
+
+	return &Config{
+		Server: Server{
+			Host:           "0.0.0.0",
+			Port:           "8080",
+			GRPCPort:       "9090",
+			RequestTimeout: 10 * time.Second,
+		},
+		Storage: Storage{
+			DBPath:          "local-db",
+			GeoDB:           "GeoLite2-City_20200602/GeoLite2-City.mmdb",
+			MaxReaders:      pool.MaxReaders,
+			MaxIdleReaders:  pool.MaxIdleReaders,
+			ConnMaxLifetime: pool.ConnMaxLifetime,
+			BusyTimeout:     pool.BusyTimeout,
+		},
+		Limits: Limits{
+			RateLimit:    20,
+			RateBurst:    40,
+			MaxBodyBytes: 64 << 10,
+		},
+		Validation: Validation{
+			MaxUsernameLength: validation.MaxUsernameLength,
+			MaxPastSkew:       validation.MaxPastSkew,
+			MaxFutureSkew:     validation.MaxFutureSkew,
+		},
+		Policy: Policy{
+			MaxSpeedMPH:             policy.MaxSpeedMPH,
+			MinTimeWindow:           policy.MinTimeWindow,
+			MaxWindowDistanceMiles:  policy.MaxWindowDistanceMiles,
+			Window:                  policy.Window,
+			ConcurrentSessionPeriod: policy.ConcurrentSessionPeriod,
+			ClusterRadiusMiles:      policy.ClusterRadiusMiles,
+			MinAlternations:         policy.MinAlternations,
+			MinProfileLogins:        policy.MinProfileLogins,
+			FamiliarRadiusMiles:     policy.FamiliarRadiusMiles,
+			MinHourHistory:          policy.MinHourHistory,
+			MinHourConfidence:       policy.MinHourConfidence,
+		},
+		Webhooks: Webhooks{
+			MaxAttempts:    backoff.MaxAttempts,
+			InitialBackoff: backoff.Initial,
+			MaxBackoff:     backoff.Max,
+		},
+		Stream: Stream{
+			Heartbeat: 15 * time.Second,
+			Buffer:    stream.DefaultBuffer,
+		},
+		Kafka: Kafka{
+			Brokers:      []string{"localhost:9092"},
+			Topic:        "logins",
+			Group:        "superman-api",
+			VerdictTopic: "verdicts",
+		},
+		Log: Log{
+			Level: "info",
+		},
+		Tracing: Tracing{
+			Exporter:    tracing.ExporterNone,
+			Endpoint:    "localhost:4317",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// ValidationPolicy returns the event validation policy
+func (c *Config) ValidationPolicy() models.ValidationPolicy {
+	return models.ValidationPolicy{
+		MaxUsernameLength: c.Validation.MaxUsernameLength,
+		MaxPastSkew:       c.Validation.MaxPastSkew,
+		MaxFutureSkew:     c.Validation.MaxFutureSkew,
+	}
+}
+
+// LogLevel returns the level of the lines logged
+func (c *Config) LogLevel() logging.Level {
+	level, _ := logging.ParseLevel(c.Log.Level)
+	return level
+}
+
+// TracingOptions returns the options of the exporter of spans
+func (c *Config) TracingOptions() tracing.Options {
+	return tracing.Options{
+		Exporter:    c.Tracing.Exporter,
+		Endpoint:    c.Tracing.Endpoint,
+		Insecure:    c.Tracing.Insecure,
+		SampleRatio: c.Tracing.SampleRatio,
+		ServiceName: "superman-api",
+	}
+}
+
+// Pool returns the sizing of the connections to the sqlite database
+func (c *Config) Pool() db.Pool {
+	return db.Pool{
+		MaxReaders:      c.Storage.MaxReaders,
+		MaxIdleReaders:  c.Storage.MaxIdleReaders,
+		ConnMaxLifetime: c.Storage.ConnMaxLifetime,
+		BusyTimeout:     c.Storage.BusyTimeout,
+	}
+}
+
+// Backoff returns the webhook retry schedule
+func (c *Config) Backoff() notify.Backoff {
+	return notify.Backoff{
+		Initial:     c.Webhooks.InitialBackoff,
+		Max:         c.Webhooks.MaxBackoff,
+		MaxAttempts: c.Webhooks.MaxAttempts,
+	}
+}
+
+// Policies returns the base travel policy and the policies of the tenants
+// overriding it, read from the tenant policies file
+func (c *Config) Policies() (superman.Policy, map[string]superman.Policy, error) {
+	p := c.Policy
+	networks, err := superman.ParseNetworks(p.AllowedNetworks)
+	if err != nil {
+		return superman.Policy{}, nil, err
+	}
+
+	base := c.basePolicy()
+	base.AllowedNetworks = networks
+	if p.TenantPolicies == "" {
+		return base, nil, nil
+	}
+
+	f, err := os.Open(p.TenantPolicies)
+	if err != nil {
+		return superman.Policy{}, nil, err
+	}
+	defer f.Close()
+
+	tenants, err := superman.LoadTenantPolicies(f, base)
+	if err != nil {
+		return superman.Policy{}, nil, fmt.Errorf("%s: %v", p.TenantPolicies, err)
+	}
+	return base, tenants, nil
+}
+
+// basePolicy returns the travel policy of the settings besides the allowed
+// networks
+func (c *Config) basePolicy() superman.Policy {
+	p := c.Policy
+	return superman.Policy{
+		MaxSpeedMPH:             p.MaxSpeedMPH,
+		MinTimeWindow:           p.MinTimeWindow,
+		MaxWindowDistanceMiles:  p.MaxWindowDistanceMiles,
+		Window:                  p.Window,
+		ConcurrentSessionPeriod: p.ConcurrentSessionPeriod,
+		ClusterRadiusMiles:      p.ClusterRadiusMiles,
+		MinAlternations:         p.MinAlternations,
+		MinProfileLogins:        p.MinProfileLogins,
+		FamiliarRadiusMiles:     p.FamiliarRadiusMiles,
+		MinHourHistory:          p.MinHourHistory,
+		MinHourConfidence:       p.MinHourConfidence,
+	}
+}
+
+// Redacted returns a copy of the configuration with its secrets replaced
+func (c *Config) Redacted() *Config {
+	copied := *c
+	if copied.Webhooks.Secret != "" {
+		copied.Webhooks.Secret = redacted
+	}
+
+	copied.Webhooks.URLs = make([]string, len(c.Webhooks.URLs))
+	for i, raw := range c.Webhooks.URLs {
+		copied.Webhooks.URLs[i] = redactURL(raw)
+	}
+	return &copied
+}
+
+// redactURL replaces the password of a url carrying credentials
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+	return u.String()
+}
+
+// Print writes the configuration as yaml with its secrets redacted
+func (c *Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// Invalid reports every problem found validating a configuration
+type Invalid struct {
+	Problems []string
+}
+
+func (e *Invalid) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// Validate reports every setting which is out of range or malformed, named
+// by its path in the configuration file
+func (c *Config) Validate() error {
+	v := &Invalid{}
+	check := func(ok bool, setting, problem string) {
+		if !ok {
+			v.Problems = append(v.Problems, setting+": "+problem)
+		}
+	}
+
+	check(validPort(c.Server.Port), "server.port", "must be a port number")
+	check(c.Server.GRPCPort == "" || validPort(c.Server.GRPCPort), "server.grpcPort", "must be a port number or empty")
+	check(c.Server.GRPCPort == "" || c.Server.GRPCPort != c.Server.Port, "server.grpcPort", "must differ from server.port")
+	check(c.Server.RequestTimeout >= 0, "server.requestTimeout", "must not be negative")
+
+	check(c.Storage.DBPath != "", "storage.dbPath", "is required")
+	check(c.Storage.GeoDB != "", "storage.geoDB", "is required")
+	check(c.Storage.MaxReaders >= 1, "storage.maxReaders", "must be at least 1")
+	check(c.Storage.MaxIdleReaders >= 0, "storage.maxIdleReaders", "must not be negative")
+	check(c.Storage.ConnMaxLifetime >= 0, "storage.connMaxLifetime", "must not be negative")
+	check(c.Storage.BusyTimeout >= 0, "storage.busyTimeout", "must not be negative")
+
+	check(c.Limits.RateLimit >= 0, "limits.rateLimit", "must not be negative")
+	check(c.Limits.RateLimit == 0 || c.Limits.RateBurst >= 1, "limits.rateBurst", "must be at least 1")
+	check(c.Limits.MaxBodyBytes >= 0, "limits.maxBodyBytes", "must not be negative")
+
+	check(c.Validation.MaxUsernameLength >= 1, "validation.maxUsernameLength", "must be at least 1")
+	check(c.Validation.MaxPastSkew >= 0, "validation.maxPastSkew", "must not be negative")
+	check(c.Validation.MaxFutureSkew >= 0, "validation.maxFutureSkew", "must not be negative")
+
+	// tenant policies are checked over a valid base so that the problems
+	// of the base are not repeated for every tenant
+	problems := c.basePolicy().Problems()
+	for _, problem := range problems {
+		v.Problems = append(v.Problems, "policy."+problem)
+	}
+	if _, err := superman.ParseNetworks(c.Policy.AllowedNetworks); err != nil {
+		check(false, "policy.allowedNetworks", err.Error())
+	} else if len(problems) == 0 {
+		if _, _, err := c.Policies(); err != nil {
+			check(false, "policy.tenantPolicies", err.Error())
+		}
+	}
+
+	for _, raw := range c.Webhooks.URLs {
+		u, err := url.Parse(raw)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "webhooks.urls", "invalid url "+redactURL(raw))
+	}
+	check(c.Webhooks.MaxAttempts >= 1, "webhooks.maxAttempts", "must be at least 1")
+	check(c.Webhooks.InitialBackoff > 0, "webhooks.initialBackoff", "must be positive")
+	check(c.Webhooks.MaxBackoff >= c.Webhooks.InitialBackoff, "webhooks.maxBackoff", "must not be less than webhooks.initialBackoff")
+
+	check(c.Stream.Heartbeat > 0, "stream.heartbeat", "must be positive")
+	check(c.Stream.Buffer >= 1, "stream.buffer", "must be at least 1")
+
+	check(len(c.Kafka.Brokers) > 0, "kafka.brokers", "is required")
+	check(c.Kafka.Topic != "", "kafka.topic", "is required")
+	check(c.Kafka.Group != "", "kafka.group", "is required")
+	check(c.Kafka.VerdictTopic != "", "kafka.verdictTopic", "is required")
+
+	_, err := logging.ParseLevel(c.Log.Level)
+	check(err == nil, "log.level", "must be one of debug, info, warn or error")
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout:
+	case tracing.ExporterOTLP:
+		check(c.Tracing.Endpoint != "", "tracing.endpoint", "is required by the otlp exporter")
+	default:
+		check(false, "tracing.exporter", "must be one of none, otlp or stdout")
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sampleRatio", "must be between 0 and 1")
+
+	if len(v.Problems) > 0 {
+		return v
+	}
+	return nil
+}
+
+// validPort reports whether the port is a tcp port number
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
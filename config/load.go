@@ -0,0 +1,188 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Load resolves the configuration from, in increasing precedence, the
+// defaults, the yaml file named by -config or CONFIG, the environment and
+// the command line flags, then validates it
+func Load(fs *flag.FlagSet, args []string, getenv func(string) string) (*Config, error) {
+	c := Default()
+	var file string
+	fs.StringVar(&file, "config", getenv("CONFIG"), "Provide the path to a yaml configuration file, overridden by the environment and flags")
+	env := bindFlags(fs, c)
+
+	// the flags are parsed once to find the file, then again over the file
+	// and environment so that they take precedence
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if file != "" {
+		*c = *Default()
+		if err := c.readFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, key := range env {
+		value := getenv(key)
+		if value == "" {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			return nil, fmt.Errorf("invalid %s=%q: %v", key, value, err)
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// readFile decodes the yaml file over the configuration, rejecting settings
+// it does not know
+func (c *Config) readFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && err != io.EOF {
+		return fmt.Errorf("%s: %v", file, err)
+	}
+	return nil
+}
+
+// bindFlags registers a flag for each setting, returning the environment
+// variable read for each flag
+func bindFlags(fs *flag.FlagSet, c *Config) map[string]string {
+	b := binder{fs: fs, env: map[string]string{}}
+
+	b.string(&c.Server.Host, "host", "HOST", "Provide the bind address for hosting the api")
+	b.string(&c.Server.Port, "port", "PORT", "Provide the bind port for hosting the api")
+	b.string(&c.Server.GRPCPort, "grpc-port", "GRPC_PORT", "Provide the bind port for hosting the grpc api, empty disables it")
+	b.duration(&c.Server.RequestTimeout, "request-timeout", "REQUEST_TIMEOUT", "Provide the time allowed to serve each api request, zero leaves requests unbounded")
+	b.string(&c.Storage.GeoDB, "geodb", "GEODB", "Provide the fully qualified path to the GeoLite2 database *.mmdb file")
+	b.string(&c.Storage.ASNDB, "asndb", "ASNDB", "Provide the fully qualified path to the optional GeoLite2 ASN database *.mmdb file")
+	b.string(&c.Storage.DBPath, "dbpath", "DBPATH", "Provide the fully qualified path to the sqlite database host directory")
+	b.int(&c.Storage.MaxReaders, "db-max-readers", "DB_MAX_READERS", "Provide the number of connections reading the sqlite database at once, writes use one connection of their own")
+	b.int(&c.Storage.MaxIdleReaders, "db-max-idle-readers", "DB_MAX_IDLE_READERS", "Provide the number of reading connections kept open while idle")
+	b.duration(&c.Storage.ConnMaxLifetime, "db-conn-max-lifetime", "DB_CONN_MAX_LIFETIME", "Provide how long a sqlite connection is reused, zero reuses connections indefinitely")
+	b.duration(&c.Storage.BusyTimeout, "db-busy-timeout", "DB_BUSY_TIMEOUT", "Provide how long a sqlite statement waits for a lock held by another process before failing")
+	b.float64(&c.Limits.RateLimit, "rate-limit", "RATE_LIMIT", "Provide the sustained requests per second allowed for each api client, zero disables rate limiting")
+	b.int(&c.Limits.RateBurst, "rate-burst", "RATE_BURST", "Provide the number of requests an api client may burst above the sustained rate")
+	b.int64(&c.Limits.MaxBodyBytes, "max-body-bytes", "MAX_BODY_BYTES", "Provide the maximum request body size in bytes, zero disables the limit")
+	b.int(&c.Validation.MaxUsernameLength, "max-username-length", "MAX_USERNAME_LENGTH", "Provide the maximum accepted username length in characters")
+	b.duration(&c.Validation.MaxPastSkew, "max-past-skew", "MAX_PAST_SKEW", "Provide how far before the server clock an event timestamp may be, zero accepts any past timestamp")
+	b.duration(&c.Validation.MaxFutureSkew, "max-future-skew", "MAX_FUTURE_SKEW", "Provide how far after the server clock an event timestamp may be")
+	b.int64(&c.Policy.MaxSpeedMPH, "max-speed", "MAX_SPEED_MPH", "Provide the travel speed in miles per hour at or above which logins are suspicious")
+	b.duration(&c.Policy.MinTimeWindow, "min-time-window", "MIN_TIME_WINDOW", "Provide the time between logins below which distance alone decides whether travel is suspicious")
+	b.float64(&c.Policy.MaxWindowDistanceMiles, "max-window-distance", "MAX_WINDOW_DISTANCE_MILES", "Provide the distance in miles beyond which logins within the minimum time window are suspicious")
+	b.int(&c.Policy.Window, "travel-window", "TRAVEL_WINDOW", "Provide the number of preceding and of subsequent logins examined for suspicious travel")
+	b.duration(&c.Policy.ConcurrentSessionPeriod, "concurrent-session-period", "CONCURRENT_SESSION_PERIOD", "Provide the period within which logins alternating between locations indicate concurrent sessions, zero disables detection")
+	b.float64(&c.Policy.ClusterRadiusMiles, "cluster-radius", "CLUSTER_RADIUS_MILES", "Provide the distance in miles within which logins are treated as the same location")
+	b.int(&c.Policy.MinAlternations, "min-alternations", "MIN_ALTERNATIONS", "Provide the number of switches between locations within the period that indicates concurrent sessions")
+	b.int64(&c.Policy.MinProfileLogins, "min-profile-logins", "MIN_PROFILE_LOGINS", "Provide the number of logins a user profile needs before logins far from every known location are flagged")
+	b.float64(&c.Policy.FamiliarRadiusMiles, "familiar-radius", "FAMILIAR_RADIUS_MILES", "Provide the distance in miles from a known location within which a login is familiar")
+	b.int64(&c.Policy.MinHourHistory, "min-hour-history", "MIN_HOUR_HISTORY", "Provide the number of logins a user profile needs before logins at unusual local hours are flagged")
+	b.float64(&c.Policy.MinHourConfidence, "min-hour-confidence", "MIN_HOUR_CONFIDENCE", "Provide the confidence between 0 and 1 that the user does not log in near an hour before a login then is flagged")
+	b.list(&c.Policy.AllowedNetworks, "allowed-networks", "ALLOWED_NETWORKS", "Provide a comma separated list of CIDR blocks or addresses, such as vpn egresses, whose logins are never judged suspicious")
+	b.string(&c.Policy.TenantPolicies, "tenant-policies", "TENANT_POLICIES", "Provide the path to a json file mapping tenants to the policy fields they override")
+	b.list(&c.Webhooks.URLs, "webhook-urls", "WEBHOOK_URLS", "Provide a comma separated list of urls notified of suspicious logins")
+	b.string(&c.Webhooks.Secret, "webhook-secret", "WEBHOOK_SECRET", "Provide the secret used to sign webhook notifications")
+	b.int(&c.Webhooks.MaxAttempts, "webhook-max-attempts", "WEBHOOK_MAX_ATTEMPTS", "Provide the number of failed attempts after which a webhook notification is dead lettered")
+	b.duration(&c.Webhooks.InitialBackoff, "webhook-initial-backoff", "WEBHOOK_INITIAL_BACKOFF", "Provide the delay before the first retry of a failed webhook notification")
+	b.duration(&c.Webhooks.MaxBackoff, "webhook-max-backoff", "WEBHOOK_MAX_BACKOFF", "Provide the longest delay between retries of a failed webhook notification")
+	b.duration(&c.Stream.Heartbeat, "stream-heartbeat", "STREAM_HEARTBEAT", "Provide how often an idle verdict stream sends a heartbeat")
+	b.int(&c.Stream.Buffer, "stream-buffer", "STREAM_BUFFER", "Provide the number of verdicts held for a stream subscriber before it is dropped as too slow")
+	b.list(&c.Kafka.Brokers, "kafka-brokers", "KAFKA_BROKERS", "Provide a comma separated list of kafka broker addresses for consume mode")
+	b.string(&c.Kafka.Topic, "kafka-topic", "KAFKA_TOPIC", "Provide the topic of login events read in consume mode")
+	b.string(&c.Kafka.Group, "kafka-group", "KAFKA_GROUP", "Provide the consumer group which tracks offsets in consume mode")
+	b.string(&c.Kafka.VerdictTopic, "kafka-verdict-topic", "KAFKA_VERDICT_TOPIC", "Provide the topic verdicts are produced to in consume mode")
+	b.string(&c.Log.Level, "log-level", "LOG_LEVEL", "Provide the lowest level logged, one of debug, info, warn or error")
+	b.string(&c.Tracing.Exporter, "trace-exporter", "TRACE_EXPORTER", "Provide the exporter of trace spans, one of none, otlp or stdout")
+	b.string(&c.Tracing.Endpoint, "trace-endpoint", "TRACE_ENDPOINT", "Provide the host and port of the otlp grpc collector receiving spans")
+	b.bool(&c.Tracing.Insecure, "trace-insecure", "TRACE_INSECURE", "Connect to the otlp collector without tls")
+	b.float64(&c.Tracing.SampleRatio, "trace-sample-ratio", "TRACE_SAMPLE_RATIO", "Provide the ratio between 0 and 1 of new traces sampled, traces continued from callers follow the caller's decision")
+
+	return b.env
+}
+
+// binder registers flags bound to settings, recording the environment
+// variable of each
+type binder struct {
+	fs  *flag.FlagSet
+	env map[string]string
+}
+
+func (b binder) string(p *string, name, env, usage string) {
+	b.fs.StringVar(p, name, *p, usage)
+	b.env[name] = env
+}
+
+func (b binder) bool(p *bool, name, env, usage string) {
+	b.fs.BoolVar(p, name, *p, usage)
+	b.env[name] = env
+}
+
+func (b binder) int(p *int, name, env, usage string) {
+	b.fs.IntVar(p, name, *p, usage)
+	b.env[name] = env
+}
+
+func (b binder) int64(p *int64, name, env, usage string) {
+	b.fs.Int64Var(p, name, *p, usage)
+	b.env[name] = env
+}
+
+func (b binder) float64(p *float64, name, env, usage string) {
+	b.fs.Float64Var(p, name, *p, usage)
+	b.env[name] = env
+}
+
+func (b binder) duration(p *time.Duration, name, env, usage string) {
+	b.fs.DurationVar(p, name, *p, usage)
+	b.env[name] = env
+}
+
+func (b binder) list(p *[]string, name, env, usage string) {
+	b.fs.Var((*list)(p), name, usage)
+	b.env[name] = env
+}
+
+// list is a flag holding a comma separated list, replaced whenever set
+type list []string
+
+func (l *list) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *list) Set(value string) error {
+	*l = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
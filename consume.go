@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/txross1993/superman-api/config"
+	"github.com/txross1993/superman-api/consume"
+	"github.com/txross1993/superman-api/logging"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/superman"
+)
+
+// verdictBatchTimeout bounds how long the writer waits for more verdicts
+// before sending a batch. Verdicts are produced synchronously as each event
+// is analyzed, so a longer wait would stall the consumer on every verdict
+const verdictBatchTimeout = 5 * time.Millisecond
+
+// newVerdictWriter creates the writer producing verdicts, hashing usernames
+// to partitions
+func newVerdictWriter(cfg config.Kafka) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Topic:        cfg.VerdictTopic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		BatchTimeout: verdictBatchTimeout,
+	}
+}
+
+// newEventReader creates the consumer group reader of login events. Offsets
+// are committed synchronously so that a commit is durable once acknowledged
+func newEventReader(cfg config.Kafka) *kafka.Reader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  cfg.Brokers,
+		GroupID:  cfg.Group,
+		Topic:    cfg.Topic,
+		MinBytes: 1,
+		MaxBytes: 10e6,
+	})
+}
+
+// runConsumer analyzes login events from the topic, validated against the
+// policy, with the service until interrupted
+func runConsumer(cfg config.Kafka, validation models.ValidationPolicy, service *superman.Service) error {
+	reader := newEventReader(cfg)
+	defer reader.Close()
+	consumer := consume.NewConsumer(reader, service, consume.WithValidationPolicy(validation))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	logging.Default().Info("consuming", "topic", cfg.Topic, "group", cfg.Group, "brokers", strings.Join(cfg.Brokers, ","))
+	return consumer.Run(ctx)
+}
@@ -0,0 +1,205 @@
+package consume
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/txross1993/superman-api/errors"
+	"github.com/txross1993/superman-api/logging"
+	"github.com/txross1993/superman-api/models"
+)
+
+var metrics = expvar.NewMap("consumer")
+
+// tenantHeader is the message header naming the tenant of a login event;
+// events without it belong to the default tenant
+const tenantHeader = "tenant"
+
+// reader is the subset of a kafka consumer group reader used to receive
+// login events
+type reader interface {
+	FetchMessage(context.Context) (kafka.Message, error)
+	CommitMessages(context.Context, ...kafka.Message) error
+}
+
+// writer is the subset of a kafka writer used to produce verdicts
+type writer interface {
+	WriteMessages(context.Context, ...kafka.Message) error
+}
+
+type analyzer interface {
+	AnalyzeEvent(context.Context, *models.UserIPAccessEvent) (*models.Superman, error)
+}
+
+// Consumer analyzes login events read from a topic. A message's offset is
+// committed only once its event is stored and its verdicts produced, so a
+// restart resumes from the first event not fully handled. Messages are
+// handled one at a time in the order received, which preserves the order
+// of each partition and so of each username keyed to it
+type Consumer struct {
+	reader     reader
+	service    analyzer
+	validation models.ValidationPolicy
+	retry      time.Duration
+	maxRetry   time.Duration
+	sleepUntil func(context.Context, time.Duration) error
+}
+
+// Option represents a functional option for configuring a Consumer
+type Option func(c *Consumer)
+
+// WithRetryBackoff provides the functional option for the delay before
+// retrying an event whose analysis failed for a transient reason, doubling
+// up to max
+func WithRetryBackoff(initial, max time.Duration) Option {
+	return func(c *Consumer) {
+		c.retry = initial
+		c.maxRetry = max
+	}
+}
+
+// WithValidationPolicy provides the functional option for the policy events
+// are validated against, which defaults to models.DefaultValidationPolicy
+func WithValidationPolicy(p models.ValidationPolicy) Option {
+	return func(c *Consumer) {
+		c.validation = p
+	}
+}
+
+// NewConsumer creates a consumer analyzing events from the reader with the
+// service
+func NewConsumer(r reader, service analyzer, opts ...Option) *Consumer {
+	c := &Consumer{
+		reader:     r,
+		service:    service,
+		validation: models.DefaultValidationPolicy(),
+		retry:      100 * time.Millisecond,
+		maxRetry:   30 * time.Second,
+		sleepUntil: sleep,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Run handles messages until the context is cancelled, which is not
+// reported as an error
+func (c *Consumer) Run(ctx context.Context) error {
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		if err := c.handle(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// handle analyzes the event in the message, retrying while the analysis
+// fails for a transient reason, then commits the message. Messages which
+// can never be analyzed are logged and committed so they do not block their
+// partition. The message's position is logged with everything done for it
+func (c *Consumer) handle(ctx context.Context, msg kafka.Message) error {
+	logger := logging.FromContext(ctx).With("topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
+	ctx = logging.NewContext(ctx, logger)
+
+	var event models.UserIPAccessEvent
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		logger.Warn("rejecting message", "error", err)
+		metrics.Add("rejected", 1)
+		return c.reader.CommitMessages(ctx, msg)
+	}
+	if err := c.validation.Validate(&event, time.Now()); err != nil {
+		logger.Warn("rejecting event", "event_uuid", event.EventUUID, "error", err)
+		metrics.Add("rejected", 1)
+		return c.reader.CommitMessages(ctx, msg)
+	}
+
+	event.Tenant = messageTenant(msg)
+	if !models.ValidTenant(event.Tenant) {
+		logger.Warn("rejecting message", "error", "invalid tenant", "tenant", event.Tenant)
+		metrics.Add("rejected", 1)
+		return c.reader.CommitMessages(ctx, msg)
+	}
+
+	delay := c.retry
+	for {
+		_, err := c.service.AnalyzeEvent(ctx, &event)
+		if err == nil {
+			metrics.Add("analyzed", 1)
+			break
+		}
+
+		// an analysis interrupted by shutdown is left uncommitted to be
+		// handled again on restart
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if rejected(err) {
+			logger.Warn("rejecting event", "event_uuid", event.EventUUID, "error", err)
+			metrics.Add("rejected", 1)
+			break
+		}
+
+		logger.Warn("retrying event", "event_uuid", event.EventUUID, "delay", delay, "error", err)
+		metrics.Add("retries", 1)
+		if err := c.sleepUntil(ctx, delay); err != nil {
+			return err
+		}
+		if delay *= 2; delay > c.maxRetry {
+			delay = c.maxRetry
+		}
+	}
+
+	return c.reader.CommitMessages(ctx, msg)
+}
+
+// rejected reports whether the analysis failed because the event itself is
+// unacceptable, so that analyzing it again would fail the same way. Every
+// other failure, such as unavailable storage, a timeout or a failed
+// geolocation, is transient
+func rejected(err error) bool {
+	switch errors.CodeOf(err) {
+	case errors.CodeInvalidIP, errors.CodeMissingField, errors.CodeInvalidField, errors.CodeInvalidTimestamp, errors.CodeMalformedRequest:
+		return true
+	}
+	return false
+}
+
+// messageTenant returns the tenant named by the message's headers
+func messageTenant(msg kafka.Message) string {
+	for _, header := range msg.Headers {
+		if header.Key == tenantHeader {
+			return string(header.Value)
+		}
+	}
+	return models.DefaultTenant
+}
+
+// sleep waits for the duration or until the context is cancelled
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,454 @@
+package consume
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/txross1993/superman-api/db"
+	"github.com/txross1993/superman-api/errors"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/superman"
+)
+
+const (
+	loginTopic   = "logins"
+	verdictTopic = "verdicts"
+	partitions   = 3
+)
+
+func TestConsumer(t *testing.T) {
+	start := int64(1514764800000)
+	users := []string{"alice", "bob", "carol", "dave"}
+
+	var messages []kafka.Message
+	for i := 0; i < 5; i++ {
+		for _, user := range users {
+			messages = append(messages, loginMessage(user, start+int64(i)*3600*1000))
+		}
+	}
+	messages = append(messages, kafka.Message{Key: []byte("mallory"), Value: []byte(`{"username":`)})
+
+	broker := newFakeBroker()
+	broker.produce(loginTopic, messages...)
+
+	service, cleanup := testService(t, broker)
+	defer cleanup()
+
+	consumer := NewConsumer(broker.reader(loginTopic), service)
+	drain(t, broker, consumer)
+
+	assert.Equal(t, len(messages), broker.committedCount(loginTopic))
+
+	verdicts := broker.messages(verdictTopic)
+	assert.Equal(t, 5*len(users), len(verdicts))
+
+	// verdicts on each user share a partition and arrive in event order
+	partitionOf := map[string]int{}
+	lastMillis := map[string]int64{}
+	for _, msg := range verdicts {
+		var verdict models.Verdict
+		if err := json.Unmarshal(msg.Value, &verdict); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, verdict.Username, string(msg.Key))
+
+		if p, ok := partitionOf[verdict.Username]; ok {
+			assert.Equal(t, p, msg.Partition)
+		}
+		partitionOf[verdict.Username] = msg.Partition
+
+		assert.True(t, verdict.TimestampMillis > lastMillis[verdict.Username])
+		lastMillis[verdict.Username] = verdict.TimestampMillis
+	}
+}
+
+// TestConsumerRetries tests that events failing for transient reasons are
+// retried until analyzed while rejected events are committed unanalyzed
+func TestConsumerRetries(t *testing.T) {
+	tests := map[string]struct {
+		err              error
+		expectedCalls    int
+		expectedVerdicts int
+	}{
+		"Storage Unavailable": {
+			err:              &errors.StorageUnavailable{Op: "store event", Err: fmt.Errorf("disk full")},
+			expectedCalls:    4,
+			expectedVerdicts: 1,
+		},
+		"Timeout": {
+			err:              &errors.Timeout{Op: "find neighboring events", Err: context.DeadlineExceeded},
+			expectedCalls:    4,
+			expectedVerdicts: 1,
+		},
+		"Geolocation Failed": {
+			err:              &errors.GeolocationFailed{IP: "206.81.252.200", Err: fmt.Errorf("lookup timed out")},
+			expectedCalls:    4,
+			expectedVerdicts: 1,
+		},
+		"Publish Failed": {
+			err:              &errors.PublishFailed{Op: "publish verdicts", Err: fmt.Errorf("broker unreachable")},
+			expectedCalls:    4,
+			expectedVerdicts: 1,
+		},
+		"Rejected": {
+			err:           &errors.InvalidField{Name: "event_uuid", Reason: "must be a uuid"},
+			expectedCalls: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		broker := newFakeBroker()
+		broker.produce(loginTopic, loginMessage("bob", 1514764800000))
+
+		service, cleanup := testService(t, broker)
+		flaky := &flakyAnalyzer{analyzer: service, failures: 3, err: test.err}
+		consumer := NewConsumer(broker.reader(loginTopic), flaky, WithRetryBackoff(time.Millisecond, 2*time.Millisecond))
+		drain(t, broker, consumer)
+		cleanup()
+
+		assert.Equal(t, test.expectedCalls, flaky.calls)
+		assert.Equal(t, 1, broker.committedCount(loginTopic))
+		assert.Equal(t, test.expectedVerdicts, len(broker.messages(verdictTopic)))
+	}
+}
+
+// TestConsumerValidation tests that events failing the consumer's
+// validation policy are committed without being analyzed
+func TestConsumerValidation(t *testing.T) {
+	invalidIP := loginMessage("carol", time.Now().UnixNano()/1e6)
+	invalidIP.Value = []byte(strings.Replace(string(invalidIP.Value), "10.0.0.1", "300.300.300.300", 1))
+
+	broker := newFakeBroker()
+	broker.produce(loginTopic, loginMessage("alice", 1514764800000), loginMessage("bob", time.Now().UnixNano()/1e6), invalidIP)
+
+	service, cleanup := testService(t, broker)
+	defer cleanup()
+
+	counted := &flakyAnalyzer{analyzer: service}
+	policy := models.ValidationPolicy{MaxPastSkew: 24 * time.Hour, MaxFutureSkew: time.Minute}
+	drain(t, broker, NewConsumer(broker.reader(loginTopic), counted, WithValidationPolicy(policy)))
+
+	assert.Equal(t, 1, counted.calls)
+	assert.Equal(t, 3, broker.committedCount(loginTopic))
+	verdicts := broker.messages(verdictTopic)
+	if assert.Equal(t, 1, len(verdicts)) {
+		assert.Equal(t, "bob", string(verdicts[0].Key))
+	}
+}
+
+func TestConsumerResumesFromCommittedOffset(t *testing.T) {
+	broker := newFakeBroker()
+	broker.produce(loginTopic, loginMessage("bob", 1514764800000), loginMessage("bob", 1514768400000))
+
+	service, cleanup := testService(t, broker)
+	defer cleanup()
+
+	// storage fails on the second event until the consumer is stopped
+	down := &flakyAnalyzer{analyzer: service, failures: 1 << 30, after: 1}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- NewConsumer(broker.reader(loginTopic), down, WithRetryBackoff(time.Millisecond, time.Millisecond)).Run(ctx)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for down.callCount() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	assert.NoError(t, <-done)
+	assert.Equal(t, 1, broker.committedCount(loginTopic))
+
+	// a restarted consumer picks up the event which was never stored
+	restarted := &flakyAnalyzer{analyzer: service}
+	drain(t, broker, NewConsumer(broker.reader(loginTopic), restarted))
+	assert.Equal(t, 1, restarted.calls)
+	assert.Equal(t, 2, broker.committedCount(loginTopic))
+	assert.Equal(t, 2, len(broker.messages(verdictTopic)))
+}
+
+func TestConsumerTenants(t *testing.T) {
+	acme := loginMessage("bob", 1514764800000)
+	acme.Headers = []kafka.Header{{Key: tenantHeader, Value: []byte("acme")}}
+	invalid := loginMessage("bob", 1514768400000)
+	invalid.Headers = []kafka.Header{{Key: tenantHeader, Value: []byte("no spaces")}}
+
+	broker := newFakeBroker()
+	broker.produce(loginTopic, acme, loginMessage("bob", 1514764800000+1000), invalid)
+
+	service, cleanup := testService(t, broker)
+	defer cleanup()
+
+	drain(t, broker, NewConsumer(broker.reader(loginTopic), service))
+	assert.Equal(t, 3, broker.committedCount(loginTopic))
+
+	verdicts := broker.messages(verdictTopic)
+	assert.Equal(t, 2, len(verdicts))
+
+	tenants := map[string]bool{}
+	for _, msg := range verdicts {
+		var verdict models.Verdict
+		if err := json.Unmarshal(msg.Value, &verdict); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, verdict.Tenant, messageTenant(msg))
+		tenants[verdict.Tenant] = true
+	}
+	assert.Equal(t, map[string]bool{"acme": true, models.DefaultTenant: true}, tenants)
+
+	history, err := service.History(context.Background(), "acme", "bob", 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(history))
+}
+
+// loginMessage creates a login event message keyed by username
+func loginMessage(username string, millis int64) kafka.Message {
+	event := models.UserIPAccessEvent{
+		EventUUID: fmt.Sprintf("%08x-0000-4000-8000-%012x", millis/1000, crc32.ChecksumIEEE([]byte(username))),
+		Username:  username,
+		IPAddress: "10.0.0.1",
+	}
+	event.SetMillis(millis)
+	value, _ := json.Marshal(event)
+	return kafka.Message{Key: []byte(username), Value: value}
+}
+
+// testService creates a service storing events in a temporary database and
+// producing verdicts to the broker
+func testService(t *testing.T, broker *fakeBroker) (*superman.Service, func()) {
+	store, cleanup := newTestDB(t)
+	producer := NewProducer(&fakeWriter{broker: broker, topic: verdictTopic})
+	service := superman.NewService(fixedGeo{}, store, superman.WithNotifier(producer))
+	return service, cleanup
+}
+
+// newTestDB opens a database in a temporary directory, returning a func
+// closing the database and removing the directory
+func newTestDB(t *testing.T) (db.DB, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "superman-consume")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := db.InitDB(path.Join(dir, "test.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return store, func() {
+		store.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// drain runs the consumer until every message of the login topic is
+// committed
+func drain(t *testing.T, broker *fakeBroker, consumer *Consumer) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- consumer.Run(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for broker.committedCount(loginTopic) < len(broker.messages(loginTopic)) {
+		if time.Now().After(deadline) {
+			cancel()
+			t.Fatal("timed out waiting for commits")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+// fixedGeo geoencodes every ip address to the same place
+type fixedGeo struct{}
+
+func (fixedGeo) GetCoordinatesFromIP(ctx context.Context, ip string) (*models.Geography, error) {
+	return &models.Geography{Latitude: 40, Longitude: -100}, nil
+}
+
+// flakyAnalyzer fails the configured number of calls once the given number
+// of events succeeded, reporting storage as unavailable unless given another
+// error
+type flakyAnalyzer struct {
+	mu        sync.Mutex
+	analyzer  analyzer
+	after     int
+	failures  int
+	err       error
+	calls     int
+	succeeded int
+}
+
+func (f *flakyAnalyzer) AnalyzeEvent(ctx context.Context, event *models.UserIPAccessEvent) (*models.Superman, error) {
+	f.mu.Lock()
+	f.calls++
+	if f.succeeded >= f.after && f.failures > 0 {
+		f.failures--
+		f.mu.Unlock()
+		if f.err != nil {
+			return nil, f.err
+		}
+		return nil, &errors.StorageUnavailable{Op: "store event", Err: fmt.Errorf("disk full")}
+	}
+	f.succeeded++
+	f.mu.Unlock()
+	return f.analyzer.AnalyzeEvent(ctx, event)
+}
+
+func (f *flakyAnalyzer) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+// fakeBroker is an in-process stand in for a kafka cluster holding
+// partitioned topics and the committed offsets of a single consumer group
+type fakeBroker struct {
+	mu        sync.Mutex
+	topics    map[string][][]kafka.Message
+	committed map[string][]int64
+	arrived   chan struct{}
+}
+
+func newFakeBroker() *fakeBroker {
+	return &fakeBroker{
+		topics:    map[string][][]kafka.Message{},
+		committed: map[string][]int64{},
+		arrived:   make(chan struct{}, 1),
+	}
+}
+
+// produce appends the messages to the partitions their keys hash to
+func (b *fakeBroker) produce(topic string, msgs ...kafka.Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.topics[topic] == nil {
+		b.topics[topic] = make([][]kafka.Message, partitions)
+		b.committed[topic] = make([]int64, partitions)
+	}
+
+	ids := make([]int, partitions)
+	for i := range ids {
+		ids[i] = i
+	}
+	balancer := &kafka.Hash{}
+	for _, msg := range msgs {
+		p := balancer.Balance(msg, ids...)
+		msg.Topic = topic
+		msg.Partition = p
+		msg.Offset = int64(len(b.topics[topic][p]))
+		b.topics[topic][p] = append(b.topics[topic][p], msg)
+	}
+
+	select {
+	case b.arrived <- struct{}{}:
+	default:
+	}
+}
+
+// messages returns every message of the topic, partition by partition
+func (b *fakeBroker) messages(topic string) []kafka.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var all []kafka.Message
+	for _, partition := range b.topics[topic] {
+		all = append(all, partition...)
+	}
+	return all
+}
+
+// committedCount returns the number of messages of the topic committed
+func (b *fakeBroker) committedCount(topic string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	count := 0
+	for _, offset := range b.committed[topic] {
+		count += int(offset)
+	}
+	return count
+}
+
+// reader joins the consumer group, resuming each partition from its
+// committed offset
+func (b *fakeBroker) reader(topic string) *fakeReader {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	next := make([]int64, partitions)
+	copy(next, b.committed[topic])
+	return &fakeReader{broker: b, topic: topic, next: next}
+}
+
+// fakeReader fetches messages from the partitions of a topic in turn
+type fakeReader struct {
+	broker *fakeBroker
+	topic  string
+	next   []int64
+	turn   int
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	for {
+		r.broker.mu.Lock()
+		for i := 0; i < partitions; i++ {
+			p := (r.turn + i) % partitions
+			if r.next[p] < int64(len(r.broker.topics[r.topic][p])) {
+				msg := r.broker.topics[r.topic][p][r.next[p]]
+				r.next[p]++
+				r.turn = p + 1
+				r.broker.mu.Unlock()
+				return msg, nil
+			}
+		}
+		r.broker.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return kafka.Message{}, ctx.Err()
+		case <-r.broker.arrived:
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.broker.mu.Lock()
+	defer r.broker.mu.Unlock()
+
+	for _, msg := range msgs {
+		if msg.Offset+1 > r.broker.committed[msg.Topic][msg.Partition] {
+			r.broker.committed[msg.Topic][msg.Partition] = msg.Offset + 1
+		}
+	}
+	return nil
+}
+
+// fakeWriter produces messages to a topic of the broker
+type fakeWriter struct {
+	broker *fakeBroker
+	topic  string
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	w.broker.produce(w.topic, msgs...)
+	return nil
+}
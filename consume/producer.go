@@ -0,0 +1,56 @@
+package consume
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/txross1993/superman-api/models"
+)
+
+// defaultWriteTimeout bounds how long producing the verdicts of one
+// analysis may take
+const defaultWriteTimeout = 10 * time.Second
+
+// Producer writes verdicts to a topic keyed by username, so that verdicts
+// on the same user land on the same partition in the order produced. The
+// verdicts of a tenant other than the default carry its tenant header
+type Producer struct {
+	writer  writer
+	timeout time.Duration
+}
+
+// NewProducer creates a producer writing verdicts with the writer
+func NewProducer(w writer) *Producer {
+	return &Producer{writer: w, timeout: defaultWriteTimeout}
+}
+
+// Notify writes every verdict, returning once the writer acknowledges them
+func (p *Producer) Notify(ctx context.Context, verdicts []*models.Verdict) error {
+	if len(verdicts) == 0 {
+		return nil
+	}
+
+	msgs := make([]kafka.Message, len(verdicts))
+	for i, verdict := range verdicts {
+		value, err := json.Marshal(verdict)
+		if err != nil {
+			return err
+		}
+		msgs[i] = kafka.Message{Key: []byte(verdict.Username), Value: value}
+		if verdict.Tenant != models.DefaultTenant {
+			msgs[i].Headers = []kafka.Header{{Key: tenantHeader, Value: []byte(verdict.Tenant)}}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	if err := p.writer.WriteMessages(ctx, msgs...); err != nil {
+		return err
+	}
+	metrics.Add("produced", int64(len(msgs)))
+	return nil
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/txross1993/superman-api/models"
+)
+
+// CreateAPIKey saves a new api key record
+func (d DB) CreateAPIKey(key *models.APIKey) error {
+	return d.writer.Create(key).Error
+}
+
+// FindAPIKeyByHash retrieves the api key with the provided key hash if any
+func (d DB) FindAPIKeyByHash(hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := d.db.Where("key_hash = ?", hash).First(&key).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// ListAPIKeys retrieves every api key ordered by creation
+func (d DB) ListAPIKeys() ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := d.db.Order("id ASC").Find(&keys).Error
+	return keys, err
+}
+
+// RevokeAPIKey marks the named api key as revoked at the provided time
+func (d DB) RevokeAPIKey(name string, at time.Time) error {
+	result := d.writer.Model(&models.APIKey{}).Where("name = ? AND revoked_at IS NULL", name).Update("revoked_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("no active api key named %q", name)
+	}
+
+	return nil
+}
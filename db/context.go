@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jinzhu/gorm"
+)
+
+// contextDB issues the statements gorm builds under a context, so that the
+// sqlite driver interrupts a running query once the context is done.
+// Transactions begun on it are rolled back when the context is done
+type contextDB struct {
+	db  *sql.DB
+	ctx context.Context
+}
+
+func (c contextDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return c.db.ExecContext(c.ctx, query, args...)
+}
+
+func (c contextDB) Prepare(query string) (*sql.Stmt, error) {
+	return c.db.PrepareContext(c.ctx, query)
+}
+
+func (c contextDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return c.db.QueryContext(c.ctx, query, args...)
+}
+
+func (c contextDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return c.db.QueryRowContext(c.ctx, query, args...)
+}
+
+func (c contextDB) Begin() (*sql.Tx, error) {
+	return c.db.BeginTx(c.ctx, nil)
+}
+
+// BeginTx begins the transaction under the handle's context. gorm begins
+// every transaction under the background context, which would leave the
+// wait for a connection and the transaction itself unbounded
+func (c contextDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	if ctx.Done() == nil {
+		ctx = c.ctx
+	}
+	return c.db.BeginTx(ctx, opts)
+}
+
+// withContext returns a reading handle whose queries end with the context
+func (d DB) withContext(ctx context.Context) (*gorm.DB, error) {
+	return onContext(ctx, d.db)
+}
+
+// writerWithContext returns a handle on the writer whose statements end with
+// the context, including the wait for the writer to be free
+func (d DB) writerWithContext(ctx context.Context) (*gorm.DB, error) {
+	return onContext(ctx, d.writer)
+}
+
+// onContext returns a handle on the connections of conn whose statements end
+// with the context. Contexts which are never done share conn
+func onContext(ctx context.Context, conn *gorm.DB) (*gorm.DB, error) {
+	if ctx.Done() == nil {
+		return conn, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return gorm.Open("sqlite3", contextDB{db: conn.DB(), ctx: ctx})
+}
@@ -1,84 +1,388 @@
 package db
 
 import (
+	"context"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/jinzhu/gorm"
 	_ "github.com/jinzhu/gorm/dialects/sqlite" // sqlite dialect
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/txross1993/superman-api/logging"
 	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/tracing"
 )
 
 const dbfile = "./gorm.db"
 
-// DB is the concrete implementation of persistence
+// neighborIndex orders each user's events by time, serving neighbor and
+// history queries with a single index seek
+const neighborIndex = "idx_user_ip_access_events_neighbors"
+
+// neighborsQuery selects the nearest preceding events, nearest first, then
+// the nearest subsequent events, nearest first, labelling each by its side.
+// Events are ordered by time then uuid so no event lies on both sides
+const neighborsQuery = `SELECT -1 AS side, * FROM (
+	SELECT * FROM user_ip_access_events
+	WHERE tenant = ? AND username = ? AND (unix_millis, event_uuid) < (?, ?)
+	ORDER BY unix_millis DESC, event_uuid DESC LIMIT ?
+)
+UNION ALL
+SELECT 1 AS side, * FROM (
+	SELECT * FROM user_ip_access_events
+	WHERE tenant = ? AND username = ? AND (unix_millis, event_uuid) > (?, ?)
+	ORDER BY unix_millis ASC, event_uuid ASC LIMIT ?
+)`
+
+// DB is the concrete implementation of persistence. Reads are served by a
+// pool of connections while writes are serialized through the writer
 type DB struct {
 	db       *gorm.DB
+	writer   *gorm.DB
+	pool     Pool
 	filePath string
 }
 
 // InitDB creates a new DB instance provided a local db file path
 // and reflects the API models to the backend storage layer
-func InitDB(dbFile string) (DB, error) {
-	var repo DB
-	db, err := gorm.Open("sqlite3", dbFile)
+func InitDB(dbFile string, opts ...Option) (DB, error) {
+	repo := DB{pool: DefaultPool(), filePath: dbFile}
+	for _, opt := range opts {
+		opt(&repo)
+	}
+
+	writer, readers, err := repo.pool.open(dbFile)
 	if err != nil {
 		return repo, err
 	}
+	repo.writer, repo.db = writer, readers
 
-	repo.db = db
-	repo.filePath = dbFile
+	if err := repo.migrateProfileTenants(); err != nil {
+		return repo, err
+	}
+
+	if err := repo.migrateEventTenants(); err != nil {
+		return repo, err
+	}
 
-	if err := repo.db.AutoMigrate(&models.UserIPAccessEvent{}).Error; err != nil {
+	if err := repo.writer.AutoMigrate(&models.UserIPAccessEvent{}, &models.APIKey{}, &models.UserProfile{}, &models.WebhookDelivery{}).Error; err != nil {
+		return repo, err
+	}
+
+	if err := repo.migrateEventIndexes(); err != nil {
+		return repo, err
+	}
+
+	// events stored before millisecond precision was introduced take the
+	// start of their second
+	if err := repo.writer.Exec("UPDATE user_ip_access_events SET unix_millis = unix_timestamp * 1000 WHERE unix_millis = 0").Error; err != nil {
 		return repo, err
 	}
 
 	return repo, nil
 }
 
-// Cleanup deletes the db storage file, deleting all data
+// Cleanup deletes the db storage file and its write-ahead log, deleting all
+// data
 func (d DB) Cleanup() error {
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(d.filePath + suffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
 	return os.Remove(d.filePath)
 }
 
-// Close ends the database connection
+// Close ends the database connections
 func (d DB) Close() error {
-	return d.db.Close()
+	if err := d.db.Close(); err != nil {
+		d.writer.Close()
+		return err
+	}
+	return d.writer.Close()
 }
 
-// FindOrCreateUserIPAccessEvent will save the ip access event record if new
-func (d DB) FindOrCreateUserIPAccessEvent(event *models.UserIPAccessEvent) error {
-	return d.db.FirstOrCreate(&event).Error
+// FindOrCreateUserIPAccessEvent will save the ip access event record if new,
+// reporting whether it was created. An event already stored under the same
+// tenant and uuid is loaded into the input event. The events of other
+// tenants are never consulted, so the same uuid may be stored by each
+func (d DB) FindOrCreateUserIPAccessEvent(ctx context.Context, event *models.UserIPAccessEvent) (bool, error) {
+	q := startQuery(ctx, "find or create event", "event_uuid", event.EventUUID)
+	created, err := d.findOrCreateUserIPAccessEvent(ctx, event)
+	q.end(err, "created", created)
+	return created, err
 }
 
-// FindPrecedingIPAccessEvent retrieves the ip access event that occurred most
-// recently before the input event if any
-func (d DB) FindPrecedingIPAccessEvent(event *models.UserIPAccessEvent) (*models.UserIPAccessEvent, error) {
-	var priorEvent models.UserIPAccessEvent
-	err := d.db.Limit(1).Where("username = ?", event.Username).Where("unix_timestamp  <= ?", event.UnixTimestamp).Where("event_uuid != ?", event.EventUUID).Order("unix_timestamp DESC").Find(&priorEvent).Error
-
+// findOrCreateUserIPAccessEvent looks the event up and creates it in one
+// transaction of the writer so that concurrent submissions of the same uuid
+// create it once
+func (d DB) findOrCreateUserIPAccessEvent(ctx context.Context, event *models.UserIPAccessEvent) (created bool, err error) {
+	conn, err := d.writerWithContext(ctx)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, nil
+		return false, err
+	}
+
+	err = conn.Transaction(func(tx *gorm.DB) error {
+		var existing models.UserIPAccessEvent
+		err := tx.Where("tenant = ? AND event_uuid = ?", event.Tenant, event.EventUUID).First(&existing).Error
+		if err == nil {
+			*event = existing
+			return nil
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		if err := tx.Create(event).Error; err != nil {
+			return err
 		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+// FindPrecedingIPAccessEvent retrieves the ip access event of the same tenant
+// and username that occurred most recently before the input event if any.
+// Events at the same instant are ordered by uuid
+func (d DB) FindPrecedingIPAccessEvent(ctx context.Context, event *models.UserIPAccessEvent) (*models.UserIPAccessEvent, error) {
+	events, err := d.FindPrecedingIPAccessEvents(ctx, event, 1)
+	if err != nil || len(events) == 0 {
 		return nil, err
 	}
 
-	return &priorEvent, nil
+	return &events[0], nil
+}
+
+// FindSubsequentIPAccessEvent retrieves the ip access event of the same tenant
+// and username that occurred soonest after the input event if any. Events at
+// the same instant are ordered by uuid
+func (d DB) FindSubsequentIPAccessEvent(ctx context.Context, event *models.UserIPAccessEvent) (*models.UserIPAccessEvent, error) {
+	events, err := d.FindSubsequentIPAccessEvents(ctx, event, 1)
+	if err != nil || len(events) == 0 {
+		return nil, err
+	}
 
+	return &events[0], nil
 }
 
-// FindSubsequentIPAccessEvent retrieves the ip access event that occurred most
-// recently after the input event if any
-func (d DB) FindSubsequentIPAccessEvent(event *models.UserIPAccessEvent) (*models.UserIPAccessEvent, error) {
-	var subsequentEvent models.UserIPAccessEvent
-	err := d.db.Limit(1).Where("username = ?", event.Username).Where("unix_timestamp  >= ?", event.UnixTimestamp).Where("event_uuid != ?", event.EventUUID).Order("unix_timestamp ASC").Find(&subsequentEvent).Error
+// FindPrecedingIPAccessEvents retrieves up to limit ip access events of the
+// same tenant and username that occurred before the input event, nearest
+// first
+func (d DB) FindPrecedingIPAccessEvents(ctx context.Context, event *models.UserIPAccessEvent, limit int) (priorEvents []models.UserIPAccessEvent, err error) {
+	q := startQuery(ctx, "find preceding events", "event_uuid", event.EventUUID)
+	defer func() { q.end(err, "found", len(priorEvents)) }()
 
+	conn, err := d.withContext(ctx)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, nil
+		return nil, err
+	}
+
+	err = conn.Limit(limit).Where("tenant = ?", event.Tenant).Where("username = ?", event.Username).Where("(unix_millis, event_uuid) < (?, ?)", event.Millis(), event.EventUUID).Order("unix_millis DESC, event_uuid DESC").Find(&priorEvents).Error
+	return priorEvents, err
+}
+
+// FindSubsequentIPAccessEvents retrieves up to limit ip access events of the
+// same tenant and username that occurred after the input event, nearest
+// first
+func (d DB) FindSubsequentIPAccessEvents(ctx context.Context, event *models.UserIPAccessEvent, limit int) (subsequentEvents []models.UserIPAccessEvent, err error) {
+	q := startQuery(ctx, "find subsequent events", "event_uuid", event.EventUUID)
+	defer func() { q.end(err, "found", len(subsequentEvents)) }()
+
+	conn, err := d.withContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = conn.Limit(limit).Where("tenant = ?", event.Tenant).Where("username = ?", event.Username).Where("(unix_millis, event_uuid) > (?, ?)", event.Millis(), event.EventUUID).Order("unix_millis ASC, event_uuid ASC").Find(&subsequentEvents).Error
+	return subsequentEvents, err
+}
+
+// FindNeighboringIPAccessEvents retrieves in a single query up to limit ip
+// access events of the same tenant and username that occurred before the
+// input event and up to limit that occurred after it, each nearest first.
+// Events at the same instant are ordered by uuid
+func (d DB) FindNeighboringIPAccessEvents(ctx context.Context, event *models.UserIPAccessEvent, limit int) (preceding, subsequent []models.UserIPAccessEvent, err error) {
+	q := startQuery(ctx, "find neighboring events", "event_uuid", event.EventUUID)
+	defer func() { q.end(err, "preceding", len(preceding), "subsequent", len(subsequent)) }()
+
+	conn, err := d.withContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := conn.Raw(neighborsQuery,
+		event.Tenant, event.Username, event.Millis(), event.EventUUID, limit,
+		event.Tenant, event.Username, event.Millis(), event.EventUUID, limit,
+	).Rows()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var n neighbor
+		if err := conn.ScanRows(rows, &n); err != nil {
+			return nil, nil, err
+		}
+		if n.Side < 0 {
+			preceding = append(preceding, n.UserIPAccessEvent)
+		} else {
+			subsequent = append(subsequent, n.UserIPAccessEvent)
 		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// the order of the rows of a compound select is not guaranteed
+	sort.Slice(preceding, func(i, j int) bool { return preceding[j].Precedes(&preceding[i]) })
+	sort.Slice(subsequent, func(i, j int) bool { return subsequent[i].Precedes(&subsequent[j]) })
+	return preceding, subsequent, nil
+}
+
+// neighbor is a row of the neighbors query, an event and the side of the
+// input event it lies on
+type neighbor struct {
+	models.UserIPAccessEvent
+	Side int
+}
+
+// ListUserIPAccessEvents retrieves up to limit of the ip access events of
+// the tenant's username that occurred before beforeMillis, most recent
+// first. A zero beforeMillis lists the most recent events
+func (d DB) ListUserIPAccessEvents(ctx context.Context, tenant, username string, beforeMillis int64, limit int) (events []models.UserIPAccessEvent, err error) {
+	q := startQuery(ctx, "list events")
+	defer func() { q.end(err, "found", len(events)) }()
+
+	conn, err := d.withContext(ctx)
+	if err != nil {
 		return nil, err
 	}
 
-	return &subsequentEvent, nil
+	query := conn.Limit(limit).Where("tenant = ?", tenant).Where("username = ?", username)
+	if beforeMillis != 0 {
+		query = query.Where("unix_millis < ?", beforeMillis)
+	}
+	err = query.Order("unix_millis DESC, event_uuid DESC").Find(&events).Error
+	return events, err
+}
+
+// eventColumns are the columns of the events table besides the tenant
+var eventColumns = []string{"event_uuid", "username", "unix_timestamp", "unix_millis", "ip_address", "client"}
+
+// migrateEventTenants rebuilds an events table created before event uuids
+// were keyed by tenant, keeping the tenant of each event and assigning
+// events stored before tenants to the default tenant. The primary key of an
+// existing table cannot be altered in place, and the indexes of the old
+// table are dropped first so the new table can take their names
+func (d DB) migrateEventTenants() error {
+	if !d.writer.HasTable(&models.UserIPAccessEvent{}) {
+		return nil
+	}
+
+	var keyed int
+	if err := d.writer.Raw("SELECT count(*) FROM pragma_table_info('user_ip_access_events') WHERE name = 'tenant' AND pk > 0").Row().Scan(&keyed); err != nil {
+		return err
+	}
+	if keyed > 0 {
+		return nil
+	}
+
+	dialect := d.writer.Dialect()
+	var columns []string
+	for _, column := range eventColumns {
+		if dialect.HasColumn("user_ip_access_events", column) {
+			columns = append(columns, column)
+		}
+	}
+	tenant := "''"
+	if dialect.HasColumn("user_ip_access_events", "tenant") {
+		tenant = "tenant"
+	}
+
+	var indexes []string
+	rows, err := d.writer.Raw("SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = 'user_ip_access_events' AND sql IS NOT NULL").Rows()
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		indexes = append(indexes, name)
+	}
+	rows.Close()
+
+	return d.writer.Transaction(func(tx *gorm.DB) error {
+		for _, index := range indexes {
+			if err := tx.Exec(`DROP INDEX "` + index + `"`).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec("ALTER TABLE user_ip_access_events RENAME TO user_ip_access_events_untenanted").Error; err != nil {
+			return err
+		}
+		if err := tx.CreateTable(&models.UserIPAccessEvent{}).Error; err != nil {
+			return err
+		}
+
+		list := strings.Join(columns, ", ")
+		if err := tx.Exec("INSERT INTO user_ip_access_events (tenant, " + list + ") SELECT " + tenant + ", " + list + " FROM user_ip_access_events_untenanted").Error; err != nil {
+			return err
+		}
+		return tx.Exec("DROP TABLE user_ip_access_events_untenanted").Error
+	})
+}
+
+// migrateEventIndexes replaces the single column indexes which events were
+// queried by before neighbors were found through one composite index. The
+// unix_millis index is kept to backfill millisecond timestamps
+func (d DB) migrateEventIndexes() error {
+	for _, column := range []string{"tenant", "username", "unix_timestamp"} {
+		if err := d.writer.Exec("DROP INDEX IF EXISTS idx_user_ip_access_events_" + column).Error; err != nil {
+			return err
+		}
+	}
+	return d.writer.Model(&models.UserIPAccessEvent{}).AddIndex(neighborIndex, "tenant", "username", "unix_millis", "event_uuid").Error
+}
+
+// query traces a storage query made on behalf of a context as a span, and
+// logs it at debug level once it ends
+type query struct {
+	ctx    context.Context
+	span   trace.Span
+	op     string
+	start  time.Time
+	fields []interface{}
+}
+
+// startQuery starts the span of the query, described by the fields
+func startQuery(ctx context.Context, op string, fields ...interface{}) *query {
+	attrs := append(tracing.Attributes(fields...), attribute.String("db.system", "sqlite"), attribute.String("db.operation", op))
+	_, span := tracing.Start(ctx, "db "+op, attrs...)
+	return &query{ctx: ctx, span: span, op: op, start: time.Now(), fields: fields}
+}
+
+// end ends the query with its error and the fields describing its result
+func (q *query) end(err error, fields ...interface{}) {
+	q.span.SetAttributes(tracing.Attributes(fields...)...)
+	tracing.End(q.span, err)
+
+	logger := logging.FromContext(q.ctx)
+	if !logger.Enabled(logging.LevelDebug) {
+		return
+	}
+
+	fields = append(append(q.fields, fields...), "op", q.op, "duration", time.Since(q.start))
+	if err != nil {
+		fields = append(fields, "error", err)
+	}
+	logger.Debug("query", fields...)
 }
@@ -0,0 +1,551 @@
+package db
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/txross1993/superman-api/models"
+)
+
+func TestContextQueries(t *testing.T) {
+	d, cleanup := newTestDB(t)
+	defer cleanup()
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	bounded, stop := context.WithTimeout(context.Background(), time.Minute)
+	defer stop()
+
+	tests := map[string]struct {
+		ctx         context.Context
+		uuid        string
+		expectedErr error
+	}{
+		"Background": {ctx: context.Background(), uuid: "85ad929a-db03-4bf4-9541-8f728fa12e41"},
+		"Deadline":   {ctx: bounded, uuid: "85ad929a-db03-4bf4-9541-8f728fa12e42"},
+		"Cancelled":  {ctx: cancelled, uuid: "85ad929a-db03-4bf4-9541-8f728fa12e43", expectedErr: context.Canceled},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		event := &models.UserIPAccessEvent{
+			Username:      "bob",
+			UnixTimestamp: 1514764800,
+			EventUUID:     test.uuid,
+			IPAddress:     "206.81.252.200",
+		}
+
+		created, err := d.FindOrCreateUserIPAccessEvent(test.ctx, event)
+		assert.Equal(t, test.expectedErr, err)
+		assert.Equal(t, test.expectedErr == nil, created)
+
+		_, err = d.FindPrecedingIPAccessEvents(test.ctx, event, 5)
+		assert.Equal(t, test.expectedErr, err)
+		_, err = d.FindSubsequentIPAccessEvents(test.ctx, event, 5)
+		assert.Equal(t, test.expectedErr, err)
+		_, _, err = d.FindNeighboringIPAccessEvents(test.ctx, event, 5)
+		assert.Equal(t, test.expectedErr, err)
+		_, err = d.ListUserIPAccessEvents(test.ctx, models.DefaultTenant, "bob", 0, 5)
+		assert.Equal(t, test.expectedErr, err)
+
+		profile := models.NewUserProfile(models.DefaultTenant, "bob")
+		assert.Equal(t, test.expectedErr, d.SaveUserProfile(test.ctx, profile))
+		_, err = d.FindUserProfile(test.ctx, models.DefaultTenant, "bob")
+		assert.Equal(t, test.expectedErr, err)
+	}
+
+	// only the events stored under a live context remain
+	events, err := d.ListUserIPAccessEvents(context.Background(), models.DefaultTenant, "bob", 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(events))
+}
+
+func TestNeighboringEvents(t *testing.T) {
+	d, cleanup := newTestDB(t)
+	defer cleanup()
+
+	// b and c share an instant, as do e and f. Carol's and the other tenant's
+	// events are never neighbors of bob's
+	start := int64(1514764800000)
+	history := []models.UserIPAccessEvent{
+		newEvent("", "bob", "a", start),
+		newEvent("", "bob", "c", start+1000),
+		newEvent("", "bob", "b", start+1000),
+		newEvent("", "bob", "d", start+2000),
+		newEvent("", "bob", "f", start+3000),
+		newEvent("", "bob", "e", start+3000),
+		newEvent("", "carol", "g", start+2000),
+		newEvent("acme", "bob", "h", start+2000),
+	}
+	for i := range history {
+		if _, err := d.FindOrCreateUserIPAccessEvent(context.Background(), &history[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string]struct {
+		event              models.UserIPAccessEvent
+		limit              int
+		expectedPreceding  []string
+		expectedSubsequent []string
+	}{
+		"Whole Window": {
+			event:              history[3],
+			limit:              5,
+			expectedPreceding:  []string{"c", "b", "a"},
+			expectedSubsequent: []string{"e", "f"},
+		},
+		"Nearest Only": {
+			event:              history[3],
+			limit:              1,
+			expectedPreceding:  []string{"c"},
+			expectedSubsequent: []string{"e"},
+		},
+		"First Event": {
+			event:              history[0],
+			limit:              2,
+			expectedSubsequent: []string{"b", "c"},
+		},
+		"First Of Tie": {
+			event:              history[2],
+			limit:              5,
+			expectedPreceding:  []string{"a"},
+			expectedSubsequent: []string{"c", "d", "e", "f"},
+		},
+		"Second Of Tie": {
+			event:              history[1],
+			limit:              5,
+			expectedPreceding:  []string{"b", "a"},
+			expectedSubsequent: []string{"d", "e", "f"},
+		},
+		"Last Of Tie": {
+			event:             history[4],
+			limit:             2,
+			expectedPreceding: []string{"e", "d"},
+		},
+		"Unstored Event In Tie": {
+			event:              newEvent("", "bob", "bb", start+1000),
+			limit:              1,
+			expectedPreceding:  []string{"b"},
+			expectedSubsequent: []string{"c"},
+		},
+		"After Every Event": {
+			event:             newEvent("", "bob", "z", start+4000),
+			limit:             2,
+			expectedPreceding: []string{"f", "e"},
+		},
+		"Unknown User": {
+			event: newEvent("", "dave", "i", start),
+			limit: 5,
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		preceding, subsequent, err := d.FindNeighboringIPAccessEvents(context.Background(), &test.event, test.limit)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedPreceding, uuids(preceding))
+		assert.Equal(t, test.expectedSubsequent, uuids(subsequent))
+
+		// the separate queries agree with the single query
+		preceding, err = d.FindPrecedingIPAccessEvents(context.Background(), &test.event, test.limit)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedPreceding, uuids(preceding))
+		subsequent, err = d.FindSubsequentIPAccessEvents(context.Background(), &test.event, test.limit)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expectedSubsequent, uuids(subsequent))
+	}
+}
+
+// TestConcurrentWrites races writers submitting the same events against
+// readers finding their neighbors, checking that no statement fails on a
+// locked database and that each event is created exactly once
+// TestWriterDeadline tests that writes waiting for the writer connection,
+// held here by another transaction, give up once their context is done
+func TestWriterDeadline(t *testing.T) {
+	d, cleanup := newTestDB(t)
+	defer cleanup()
+
+	held := d.writer.Begin()
+	if err := held.Error; err != nil {
+		t.Fatal(err)
+	}
+	release := time.AfterFunc(2*time.Second, func() { held.Rollback() })
+	defer func() {
+		if release.Stop() {
+			held.Rollback()
+		}
+	}()
+
+	event := newEvent(models.DefaultTenant, "bob", "85ad929a-db03-4bf4-9541-8f728fa12e41", 1514764800000)
+	tests := map[string]func(ctx context.Context) error{
+		"Find Or Create Event": func(ctx context.Context) error {
+			_, err := d.FindOrCreateUserIPAccessEvent(ctx, &event)
+			return err
+		},
+		"Save Profile": func(ctx context.Context) error {
+			return d.SaveUserProfile(ctx, models.NewUserProfile(models.DefaultTenant, "bob"))
+		},
+		"Enqueue Delivery": func(ctx context.Context) error {
+			return d.EnqueueDelivery(ctx, &models.WebhookDelivery{Destination: "https://example.com", DedupeKey: "a"})
+		},
+		"Save Delivery": func(ctx context.Context) error {
+			return d.SaveDelivery(ctx, &models.WebhookDelivery{Destination: "https://example.com", DedupeKey: "b"})
+		},
+	}
+
+	for name, write := range tests {
+		t.Logf("Running test case: %s", name)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		began := time.Now()
+		err := write(ctx)
+		cancel()
+
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.True(t, time.Since(began) < time.Second, "waited %s for the writer", time.Since(began))
+	}
+}
+
+// TestMigrateEventTenants tests that events tables keyed by uuid alone are
+// rebuilt keyed by tenant and uuid, keeping every event and its tenant
+func TestMigrateEventTenants(t *testing.T) {
+	tests := map[string]struct {
+		schema         []string
+		tenant         string
+		expectedTenant string
+	}{
+		"Before Tenants": {
+			schema: []string{
+				"CREATE TABLE user_ip_access_events (event_uuid varchar(255), username varchar(255) NOT NULL, unix_timestamp bigint NOT NULL, ip_address varchar(255) NOT NULL, PRIMARY KEY (event_uuid))",
+				"CREATE INDEX idx_user_ip_access_events_username ON user_ip_access_events(username)",
+				"INSERT INTO user_ip_access_events VALUES ('85ad929a-db03-4bf4-9541-8f728fa12e41', 'bob', 1514764800, '206.81.252.200')",
+			},
+			expectedTenant: models.DefaultTenant,
+		},
+		"Tenant Not Keyed": {
+			schema: []string{
+				"CREATE TABLE user_ip_access_events (event_uuid varchar(255), username varchar(255) NOT NULL, unix_timestamp bigint NOT NULL, unix_millis bigint NOT NULL DEFAULT 0, ip_address varchar(255) NOT NULL, client varchar(255), tenant varchar(255) NOT NULL DEFAULT '', PRIMARY KEY (event_uuid))",
+				"CREATE INDEX idx_user_ip_access_events_unix_millis ON user_ip_access_events(unix_millis)",
+				"CREATE INDEX " + neighborIndex + " ON user_ip_access_events(tenant, username, unix_millis, event_uuid)",
+				"INSERT INTO user_ip_access_events VALUES ('85ad929a-db03-4bf4-9541-8f728fa12e41', 'bob', 1514764800, 1514764800250, '206.81.252.200', 'collector', 'acme')",
+			},
+			expectedTenant: "acme",
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		dbFile, cleanup := newTestDBFile(t)
+		defer cleanup()
+
+		old, err := gorm.Open("sqlite3", dbFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, statement := range test.schema {
+			if err := old.Exec(statement).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+		old.Close()
+
+		d, err := InitDB(dbFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer d.Close()
+
+		stored, err := d.ListUserIPAccessEvents(context.Background(), test.expectedTenant, "bob", 0, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"85ad929a-db03-4bf4-9541-8f728fa12e41"}, uuids(stored))
+
+		// the uuid is free in every other tenant
+		e := newEvent("globex", "bob", "85ad929a-db03-4bf4-9541-8f728fa12e41", 1514764900000)
+		created, err := d.FindOrCreateUserIPAccessEvent(context.Background(), &e)
+		assert.NoError(t, err)
+		assert.True(t, created)
+		assert.True(t, d.writer.Dialect().HasIndex("user_ip_access_events", neighborIndex))
+	}
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	const (
+		events  = 50
+		writers = 8
+		readers = 8
+	)
+
+	d, cleanup := newTestDB(t, WithPool(Pool{MaxReaders: readers, MaxIdleReaders: readers}))
+	defer cleanup()
+
+	start := int64(1514764800000)
+	var created int64
+	var mu sync.Mutex
+	var errs []error
+	record := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}
+
+	for i := 0; i < events; i++ {
+		ready := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(writers + readers)
+		for w := 0; w < writers; w++ {
+			go func() {
+				defer wg.Done()
+				<-ready
+				e := newEvent("", "bob", fmt.Sprintf("event-%03d", i), start+int64(i)*1000)
+				ok, err := d.FindOrCreateUserIPAccessEvent(context.Background(), &e)
+				if err != nil {
+					record(err)
+				}
+				if ok {
+					atomic.AddInt64(&created, 1)
+				}
+				profile := models.NewUserProfile("", "bob")
+				if err := d.SaveUserProfile(context.Background(), profile); err != nil {
+					record(err)
+				}
+			}()
+		}
+		for r := 0; r < readers; r++ {
+			go func() {
+				defer wg.Done()
+				<-ready
+				e := newEvent("", "bob", "reader", start+int64(i)*1000)
+				if _, _, err := d.FindNeighboringIPAccessEvents(context.Background(), &e, 5); err != nil {
+					record(err)
+				}
+				if _, err := d.FindUserProfile(context.Background(), "", "bob"); err != nil {
+					record(err)
+				}
+			}()
+		}
+		close(ready)
+		wg.Wait()
+	}
+
+	assert.Empty(t, errs)
+	assert.Equal(t, int64(events), created)
+	stored, err := d.ListUserIPAccessEvents(context.Background(), "", "bob", 0, 2*events)
+	assert.NoError(t, err)
+	assert.Equal(t, events, len(stored))
+}
+
+var fixtureRows = flag.Int("fixture-rows", 2000000, "events stored in the neighbor benchmark fixture")
+
+// BenchmarkNeighboringEvents compares finding the neighbors of an event
+// among millions by two queries over the former single column indexes to
+// two queries and to a single query over the composite index
+func BenchmarkNeighboringEvents(b *testing.B) {
+	const eventsPerUser = 100
+	d, cleanup := newTestDB(b)
+	defer cleanup()
+
+	users := *fixtureRows / eventsPerUser
+	b.Logf("storing %d events of %d users", users*eventsPerUser, users)
+	if err := storeFixture(d, users, eventsPerUser); err != nil {
+		b.Fatal(err)
+	}
+
+	legacy := []string{
+		"DROP INDEX IF EXISTS " + neighborIndex,
+		"CREATE INDEX idx_user_ip_access_events_tenant ON user_ip_access_events(tenant)",
+		"CREATE INDEX idx_user_ip_access_events_username ON user_ip_access_events(username)",
+		"CREATE INDEX idx_user_ip_access_events_unix_timestamp ON user_ip_access_events(unix_timestamp)",
+	}
+	tests := []struct {
+		name    string
+		indexes func() error
+		find    func(ctx context.Context, e *models.UserIPAccessEvent) error
+	}{
+		{
+			name:    "SingleColumnIndexes/TwoQueries",
+			indexes: func() error { return execAll(d, legacy) },
+			find:    d.findNeighborsSeparately,
+		},
+		{
+			name:    "CompositeIndex/TwoQueries",
+			indexes: d.migrateEventIndexes,
+			find:    d.findNeighborsSeparately,
+		},
+		{
+			name:    "CompositeIndex/SingleQuery",
+			indexes: d.migrateEventIndexes,
+			find: func(ctx context.Context, e *models.UserIPAccessEvent) error {
+				_, _, err := d.FindNeighboringIPAccessEvents(ctx, e, 5)
+				return err
+			},
+		},
+	}
+
+	for _, test := range tests {
+		if err := test.indexes(); err != nil {
+			b.Fatal(err)
+		}
+		b.Run(test.name, func(b *testing.B) {
+			r := rand.New(rand.NewSource(1))
+			for i := 0; i < b.N; i++ {
+				e := newEvent("", fmt.Sprintf("user-%d", r.Intn(users)), "current", int64(r.Intn(eventsPerUser))*60000)
+				if err := test.find(context.Background(), &e); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// storeFixture stores the events of the users in a single transaction,
+// each user's events a minute apart
+func storeFixture(d DB, users, eventsPerUser int) error {
+	tx, err := d.writer.DB().Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare("INSERT INTO user_ip_access_events (event_uuid, username, unix_timestamp, unix_millis, ip_address, client, tenant) VALUES (?, ?, ?, ?, ?, '', '')")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for u := 0; u < users; u++ {
+		for i := 0; i < eventsPerUser; i++ {
+			millis := int64(i) * 60000
+			if _, err := stmt.Exec(fmt.Sprintf("%d-%d", u, i), fmt.Sprintf("user-%d", u), millis/1000, millis, fmt.Sprintf("10.%d.%d.%d", u%256, i, u/256%256)); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+func (d DB) findNeighborsSeparately(ctx context.Context, e *models.UserIPAccessEvent) error {
+	if _, err := d.FindPrecedingIPAccessEvents(ctx, e, 5); err != nil {
+		return err
+	}
+	_, err := d.FindSubsequentIPAccessEvents(ctx, e, 5)
+	return err
+}
+
+func execAll(d DB, statements []string) error {
+	for _, statement := range statements {
+		if err := d.writer.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestTiedNeighbors tests that events at the same instant are ordered the
+// same way whichever of them is analyzed, so that no event is both the
+// preceding and the subsequent neighbor of another and neighbors agree
+func TestTiedNeighbors(t *testing.T) {
+	d, cleanup := newTestDB(t)
+	defer cleanup()
+
+	// a burst of logins within a second, stored out of order
+	start := int64(1514764800000)
+	burst := []string{"85ad929a", "0c2f6e1b", "f4b1c3d2", "3e9a7b60", "b7d05c1e"}
+	for _, uuid := range burst {
+		e := newEvent("", "bob", uuid, start)
+		if _, err := d.FindOrCreateUserIPAccessEvent(context.Background(), &e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ordered := []string{"0c2f6e1b", "3e9a7b60", "85ad929a", "b7d05c1e", "f4b1c3d2"}
+
+	for i, uuid := range ordered {
+		t.Logf("Running test case: %s", uuid)
+		e := newEvent("", "bob", uuid, start)
+		preceding, subsequent, err := d.FindNeighboringIPAccessEvents(context.Background(), &e, len(ordered))
+		assert.NoError(t, err)
+
+		var expectedPreceding []string
+		for j := i - 1; j >= 0; j-- {
+			expectedPreceding = append(expectedPreceding, ordered[j])
+		}
+		var expectedSubsequent []string
+		if i+1 < len(ordered) {
+			expectedSubsequent = ordered[i+1:]
+		}
+		assert.Equal(t, expectedPreceding, uuids(preceding))
+		assert.Equal(t, expectedSubsequent, uuids(subsequent))
+
+		single, err := d.FindPrecedingIPAccessEvent(context.Background(), &e)
+		assert.NoError(t, err)
+		if i == 0 {
+			assert.Nil(t, single)
+		} else if assert.NotNil(t, single) {
+			assert.Equal(t, ordered[i-1], single.EventUUID)
+		}
+		single, err = d.FindSubsequentIPAccessEvent(context.Background(), &e)
+		assert.NoError(t, err)
+		if i+1 == len(ordered) {
+			assert.Nil(t, single)
+		} else if assert.NotNil(t, single) {
+			assert.Equal(t, ordered[i+1], single.EventUUID)
+		}
+	}
+}
+
+// newTestDB opens a database in a temporary directory, returning a func
+// closing the database and removing the directory
+func newTestDB(tb testing.TB, opts ...Option) (DB, func()) {
+	tb.Helper()
+	dbFile, remove := newTestDBFile(tb)
+	d, err := InitDB(dbFile, opts...)
+	if err != nil {
+		remove()
+		tb.Fatal(err)
+	}
+	return d, func() {
+		d.Close()
+		remove()
+	}
+}
+
+// newTestDBFile returns the path of a database file in a temporary
+// directory, and a func removing the directory
+func newTestDBFile(tb testing.TB) (string, func()) {
+	tb.Helper()
+	dir, err := ioutil.TempDir("", "superman-db")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return path.Join(dir, "test.db"), func() { os.RemoveAll(dir) }
+}
+
+func newEvent(tenant, username, uuid string, millis int64) models.UserIPAccessEvent {
+	e := models.UserIPAccessEvent{
+		Tenant:    tenant,
+		Username:  username,
+		EventUUID: uuid,
+		IPAddress: "206.81.252.200",
+	}
+	e.SetMillis(millis)
+	return e
+}
+
+func uuids(events []models.UserIPAccessEvent) []string {
+	var ids []string
+	for _, e := range events {
+		ids = append(ids, e.EventUUID)
+	}
+	return ids
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/txross1993/superman-api/models"
+)
+
+// EnqueueDelivery saves the webhook delivery unless one with the same
+// destination and dedupe key already exists, in which case the existing
+// record is loaded into the input
+func (d DB) EnqueueDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	conn, err := d.writerWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	return conn.Transaction(func(tx *gorm.DB) error {
+		return tx.Where("destination = ? AND dedupe_key = ?", delivery.Destination, delivery.DedupeKey).FirstOrCreate(delivery).Error
+	})
+}
+
+// DueDeliveries retrieves up to limit pending webhook deliveries whose next
+// attempt is due at the provided time, oldest first
+func (d DB) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	conn, err := d.withContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	err = conn.Limit(limit).Where("delivered_at IS NULL AND dead_at IS NULL").Where("next_attempt_at <= ?", now.UTC()).Order("next_attempt_at ASC, id ASC").Find(&deliveries).Error
+	return deliveries, err
+}
+
+// SaveDelivery records the outcome of a webhook delivery attempt
+func (d DB) SaveDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	conn, err := d.writerWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	return conn.Transaction(func(tx *gorm.DB) error {
+		return tx.Save(delivery).Error
+	})
+}
+
+// ListDeadDeliveries retrieves every webhook delivery of the tenant which
+// exhausted its attempts, most recent first
+func (d DB) ListDeadDeliveries(tenant string) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := d.db.Where("tenant = ? AND dead_at IS NOT NULL", tenant).Order("dead_at DESC, id DESC").Find(&deliveries).Error
+	return deliveries, err
+}
+
+// RetryDeadDelivery schedules the tenant's dead webhook delivery for a fresh
+// round of attempts at the provided time, reporting whether a dead delivery
+// of the tenant with the id was found
+func (d DB) RetryDeadDelivery(tenant string, id uint, at time.Time) (bool, error) {
+	result := d.writer.Model(&models.WebhookDelivery{}).Where("tenant = ? AND id = ? AND dead_at IS NOT NULL", tenant, id).Updates(map[string]interface{}{
+		"dead_at":         nil,
+		"attempts":        0,
+		"next_attempt_at": at.UTC(),
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Pool sizes the connections to the sqlite database. Writes are serialized
+// through a single connection while up to MaxReaders connections read
+// concurrently, which write-ahead logging lets proceed alongside the write
+type Pool struct {
+	// MaxReaders bounds the connections reading at once
+	MaxReaders int
+	// MaxIdleReaders bounds the reading connections kept open while idle
+	MaxIdleReaders int
+	// ConnMaxLifetime bounds how long a connection is reused, zero reuses
+	// connections indefinitely
+	ConnMaxLifetime time.Duration
+	// BusyTimeout is how long a statement waits for a lock held by another
+	// process, such as the api key commands, before failing
+	BusyTimeout time.Duration
+}
+
+// DefaultPool returns the connection pool used unless configured otherwise
+func DefaultPool() Pool {
+	return Pool{
+		MaxReaders:     8,
+		MaxIdleReaders: 8,
+		BusyTimeout:    5 * time.Second,
+	}
+}
+
+// Option configures how the database is opened
+type Option func(*DB)
+
+// WithPool sizes the connections to the database
+func WithPool(pool Pool) Option {
+	return func(d *DB) {
+		d.pool = pool
+	}
+}
+
+// dsn names the database file with the connection settings: write-ahead
+// logging, the busy timeout, and transactions which take the write lock
+// when they begin rather than fail upgrading to it
+func (p Pool) dsn(dbFile string) string {
+	return fmt.Sprintf("%s?_journal_mode=WAL&_busy_timeout=%d&_txlock=immediate", dbFile, p.BusyTimeout/time.Millisecond)
+}
+
+// open opens the writing connection and the pool of reading connections
+func (p Pool) open(dbFile string) (writer, readers *gorm.DB, err error) {
+	if writer, err = gorm.Open("sqlite3", p.dsn(dbFile)); err != nil {
+		return nil, nil, err
+	}
+	writer.DB().SetMaxOpenConns(1)
+	writer.DB().SetConnMaxLifetime(p.ConnMaxLifetime)
+
+	if readers, err = gorm.Open("sqlite3", p.dsn(dbFile)); err != nil {
+		writer.Close()
+		return nil, nil, err
+	}
+	readers.DB().SetMaxOpenConns(p.MaxReaders)
+	readers.DB().SetMaxIdleConns(p.MaxIdleReaders)
+	readers.DB().SetConnMaxLifetime(p.ConnMaxLifetime)
+
+	return writer, readers, nil
+}
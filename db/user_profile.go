@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jinzhu/gorm"
+	"github.com/txross1993/superman-api/models"
+)
+
+// FindUserProfile retrieves the profile for the tenant's username if any
+func (d DB) FindUserProfile(ctx context.Context, tenant, username string) (*models.UserProfile, error) {
+	q := startQuery(ctx, "find user profile")
+	profile, err := d.findUserProfile(ctx, tenant, username)
+	q.end(err, "found", profile != nil)
+	return profile, err
+}
+
+func (d DB) findUserProfile(ctx context.Context, tenant, username string) (*models.UserProfile, error) {
+	conn, err := d.withContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var profile models.UserProfile
+	err = conn.Where("tenant = ? AND username = ?", tenant, username).First(&profile).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// SaveUserProfile creates or replaces the profile for its tenant and username.
+// Saving updates the profile or creates it when missing in one transaction
+// so that concurrent saves of a new profile do not both create it
+func (d DB) SaveUserProfile(ctx context.Context, profile *models.UserProfile) (err error) {
+	q := startQuery(ctx, "save user profile")
+	defer func() { q.end(err) }()
+
+	conn, err := d.writerWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	return conn.Transaction(func(tx *gorm.DB) error {
+		return tx.Save(profile).Error
+	})
+}
+
+// migrateProfileTenants rebuilds a user profile table created before
+// profiles were keyed by tenant, assigning its profiles to the default
+// tenant. The primary key of an existing table cannot be altered in place
+func (d DB) migrateProfileTenants() error {
+	if !d.writer.HasTable(&models.UserProfile{}) || d.writer.Dialect().HasColumn("user_profiles", "tenant") {
+		return nil
+	}
+
+	return d.writer.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("ALTER TABLE user_profiles RENAME TO user_profiles_untenanted").Error; err != nil {
+			return err
+		}
+		if err := tx.CreateTable(&models.UserProfile{}).Error; err != nil {
+			return err
+		}
+
+		const columns = "username, login_count, first_seen_millis, last_seen_millis, locations, countries, as_ns, local_hours, updated_at"
+		if err := tx.Exec("INSERT INTO user_profiles (tenant, " + columns + ") SELECT '', " + columns + " FROM user_profiles_untenanted").Error; err != nil {
+			return err
+		}
+		return tx.Exec("DROP TABLE user_profiles_untenanted").Error
+	})
+}
@@ -0,0 +1,66 @@
+package errors
+
+import stderrors "errors"
+
+// Code is a stable machine readable identifier for a class of error which
+// clients may rely on across releases
+type Code string
+
+const (
+	// CodeInvalidIP indicates an ip address which could not be parsed
+	CodeInvalidIP Code = "invalid_ip"
+	// CodeMissingField indicates a required request field was not provided
+	CodeMissingField Code = "missing_field"
+	// CodeInvalidField indicates a request field whose value is not acceptable
+	CodeInvalidField Code = "invalid_field"
+	// CodeInvalidTimestamp indicates an unusable event timestamp
+	CodeInvalidTimestamp Code = "invalid_timestamp"
+	// CodeMalformedRequest indicates a request body which is not valid JSON
+	// or does not match the expected shape
+	CodeMalformedRequest Code = "malformed_request"
+	// CodeGeolocationFailed indicates the geoencoding service could not
+	// resolve an ip address
+	CodeGeolocationFailed Code = "geolocation_failed"
+	// CodeStorageUnavailable indicates the persistence layer failed
+	CodeStorageUnavailable Code = "storage_unavailable"
+	// CodePublishFailed indicates the event was stored and analyzed but its
+	// verdicts could not be published, so sending it again publishes them
+	CodePublishFailed Code = "publish_failed"
+	// CodeUnauthorized indicates a missing or invalid api key
+	CodeUnauthorized Code = "unauthorized"
+	// CodeForbidden indicates an api key lacking the required scope
+	CodeForbidden Code = "forbidden"
+	// CodeRateLimited indicates the client exceeded its request rate
+	CodeRateLimited Code = "rate_limited"
+	// CodeRequestTooLarge indicates a request body over the size limit
+	CodeRequestTooLarge Code = "request_too_large"
+	// CodeTimeout indicates the request ran past its deadline or its client
+	// went away before it was served
+	CodeTimeout Code = "timeout"
+	// CodeNotFound indicates the requested resource does not exist
+	CodeNotFound Code = "not_found"
+	// CodeInternal indicates an unexpected failure
+	CodeInternal Code = "internal_error"
+)
+
+// Coded is implemented by errors which map to a stable error code
+type Coded interface {
+	error
+	Code() Code
+}
+
+// FieldError is implemented by errors attributable to a single request field
+type FieldError interface {
+	Coded
+	Field() string
+}
+
+// CodeOf returns the code of the first error in the chain which carries one,
+// or CodeInternal if none does
+func CodeOf(err error) Code {
+	var coded Coded
+	if stderrors.As(err, &coded) {
+		return coded.Code()
+	}
+	return CodeInternal
+}
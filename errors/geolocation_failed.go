@@ -0,0 +1,24 @@
+package errors
+
+import "fmt"
+
+// GeolocationFailed is the error type for an ip address the geoencoding
+// service failed to resolve
+type GeolocationFailed struct {
+	IP  string
+	Err error
+}
+
+func (err *GeolocationFailed) Error() string {
+	return fmt.Sprintf("geolocation failed for %s: %v", err.IP, err.Err)
+}
+
+// Unwrap returns the underlying geoencoding error
+func (err *GeolocationFailed) Unwrap() error {
+	return err.Err
+}
+
+// Code returns CodeGeolocationFailed
+func (err *GeolocationFailed) Code() Code {
+	return CodeGeolocationFailed
+}
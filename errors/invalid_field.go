@@ -0,0 +1,24 @@
+package errors
+
+import "fmt"
+
+// InvalidField is the error type for a request field whose value is
+// present but not acceptable
+type InvalidField struct {
+	Name   string
+	Reason string
+}
+
+func (err *InvalidField) Error() string {
+	return fmt.Sprintf("invalid %s: %s", err.Name, err.Reason)
+}
+
+// Code returns CodeInvalidField
+func (err *InvalidField) Code() Code {
+	return CodeInvalidField
+}
+
+// Field returns the name of the invalid field
+func (err *InvalidField) Field() string {
+	return err.Name
+}
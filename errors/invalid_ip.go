@@ -10,3 +10,13 @@ type InvalidIP struct {
 func (err *InvalidIP) Error() string {
 	return fmt.Sprintf("invalid IP address format: %s", err.IP)
 }
+
+// Code returns CodeInvalidIP
+func (err *InvalidIP) Code() Code {
+	return CodeInvalidIP
+}
+
+// Field returns the request field holding the ip address
+func (err *InvalidIP) Field() string {
+	return "ip_address"
+}
@@ -0,0 +1,27 @@
+package errors
+
+import "fmt"
+
+// InvalidTimestamp is the error type for an event timestamp which could
+// not be parsed or is outside the accepted range
+type InvalidTimestamp struct {
+	Value  string
+	Reason string
+}
+
+func (err *InvalidTimestamp) Error() string {
+	if err.Reason == "" {
+		return fmt.Sprintf("invalid timestamp: %s", err.Value)
+	}
+	return fmt.Sprintf("invalid timestamp %s: %s", err.Value, err.Reason)
+}
+
+// Code returns CodeInvalidTimestamp
+func (err *InvalidTimestamp) Code() Code {
+	return CodeInvalidTimestamp
+}
+
+// Field returns the request field holding the timestamp
+func (err *InvalidTimestamp) Field() string {
+	return "unix_timestamp"
+}
@@ -0,0 +1,23 @@
+package errors
+
+import "fmt"
+
+// MissingField is the error type for a required request field which was
+// absent or empty
+type MissingField struct {
+	Name string
+}
+
+func (err *MissingField) Error() string {
+	return fmt.Sprintf("missing required field: %s", err.Name)
+}
+
+// Code returns CodeMissingField
+func (err *MissingField) Code() Code {
+	return CodeMissingField
+}
+
+// Field returns the name of the missing field
+func (err *MissingField) Field() string {
+	return err.Name
+}
@@ -0,0 +1,42 @@
+package errors
+
+import "strings"
+
+// Multiple is the error type aggregating the failures of operations run
+// concurrently, in the order the operations were started
+type Multiple struct {
+	Errors []error
+}
+
+func (err *Multiple) Error() string {
+	msgs := make([]string, len(err.Errors))
+	for i, e := range err.Errors {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the first failure so that the aggregate carries its code
+func (err *Multiple) Unwrap() error {
+	if len(err.Errors) == 0 {
+		return nil
+	}
+	return err.Errors[0]
+}
+
+// Add records a failure
+func (err *Multiple) Add(e error) {
+	err.Errors = append(err.Errors, e)
+}
+
+// ErrorOrNil returns the sole failure recorded, the aggregate error if
+// there were several, or nil if there were none
+func (err *Multiple) ErrorOrNil() error {
+	switch len(err.Errors) {
+	case 0:
+		return nil
+	case 1:
+		return err.Errors[0]
+	}
+	return err
+}
@@ -0,0 +1,25 @@
+package errors
+
+import "fmt"
+
+// PublishFailed is the error type for verdicts of a stored event which
+// could not be handed to a notifier, such as the webhook outbox or a
+// message broker
+type PublishFailed struct {
+	Op  string
+	Err error
+}
+
+func (err *PublishFailed) Error() string {
+	return fmt.Sprintf("publish failed: %s: %v", err.Op, err.Err)
+}
+
+// Unwrap returns the underlying notifier error
+func (err *PublishFailed) Unwrap() error {
+	return err.Err
+}
+
+// Code returns CodePublishFailed
+func (err *PublishFailed) Code() Code {
+	return CodePublishFailed
+}
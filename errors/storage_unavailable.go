@@ -0,0 +1,23 @@
+package errors
+
+import "fmt"
+
+// StorageUnavailable is the error type for a failed persistence operation
+type StorageUnavailable struct {
+	Op  string
+	Err error
+}
+
+func (err *StorageUnavailable) Error() string {
+	return fmt.Sprintf("storage unavailable: %s: %v", err.Op, err.Err)
+}
+
+// Unwrap returns the underlying persistence error
+func (err *StorageUnavailable) Unwrap() error {
+	return err.Err
+}
+
+// Code returns CodeStorageUnavailable
+func (err *StorageUnavailable) Code() Code {
+	return CodeStorageUnavailable
+}
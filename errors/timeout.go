@@ -0,0 +1,24 @@
+package errors
+
+import "fmt"
+
+// Timeout is the error type for an operation abandoned because its request
+// ran past its deadline or its client went away
+type Timeout struct {
+	Op  string
+	Err error
+}
+
+func (err *Timeout) Error() string {
+	return fmt.Sprintf("timed out: %s: %v", err.Op, err.Err)
+}
+
+// Unwrap returns the context error which ended the operation
+func (err *Timeout) Unwrap() error {
+	return err.Err
+}
+
+// Code returns CodeTimeout
+func (err *Timeout) Code() Code {
+	return CodeTimeout
+}
@@ -0,0 +1,56 @@
+package errors
+
+import (
+	stderrors "errors"
+	"strings"
+)
+
+// Validation is the error type aggregating every field violation found in a
+// request so clients can correct them all at once
+type Validation struct {
+	Violations []FieldError
+}
+
+func (err *Validation) Error() string {
+	msgs := make([]string, len(err.Violations))
+	for i, violation := range err.Violations {
+		msgs[i] = violation.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Code returns the code of the first violation
+func (err *Validation) Code() Code {
+	if len(err.Violations) == 0 {
+		return CodeInternal
+	}
+	return err.Violations[0].Code()
+}
+
+// Add records a violation
+func (err *Validation) Add(violation FieldError) {
+	err.Violations = append(err.Violations, violation)
+}
+
+// ErrorOrNil returns the aggregate error if any violation was recorded
+func (err *Validation) ErrorOrNil() error {
+	if len(err.Violations) == 0 {
+		return nil
+	}
+	return err
+}
+
+// FieldsOf returns every field violation carried by the error chain, either
+// the violations of a Validation error or a single field error
+func FieldsOf(err error) []FieldError {
+	var validation *Validation
+	if stderrors.As(err, &validation) {
+		return validation.Violations
+	}
+
+	var fieldErr FieldError
+	if stderrors.As(err, &fieldErr) {
+		return []FieldError{fieldErr}
+	}
+	return nil
+}
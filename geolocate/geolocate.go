@@ -1,33 +1,87 @@
 package geolocate
 
 import (
+	"context"
 	"net"
+	"time"
 
 	geoDB "github.com/oschwald/geoip2-golang"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/txross1993/superman-api/errors"
+	"github.com/txross1993/superman-api/logging"
 	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/tracing"
 )
 
 // GeoService provides the service to geoencode IP addresses
 type GeoService struct {
-	db *geoDB.Reader
+	db  *geoDB.Reader
+	asn *geoDB.Reader
+
+	asnPath string
+}
+
+// Option configures optional GeoService databases
+type Option func(*GeoService)
+
+// WithASNDatabase enriches geographies with the autonomous system number
+// found in the GeoLite2 ASN database at the provided path
+func WithASNDatabase(path string) Option {
+	return func(g *GeoService) {
+		g.asnPath = path
+	}
 }
 
 // NewGeoService initializes a new in-memory IP geoencoding service provided
 // a path to the local database file
-func NewGeoService(repositoryPath string) (*GeoService, error) {
+func NewGeoService(repositoryPath string, opts ...Option) (*GeoService, error) {
 	db, err := geoDB.Open(repositoryPath)
 	if err != nil {
 		return nil, err
 	}
 
-	return &GeoService{db}, nil
+	g := &GeoService{db: db}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	if g.asnPath != "" {
+		if g.asn, err = geoDB.Open(g.asnPath); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	return g, nil
 }
 
 // GetCoordinatesFromIP parses the input IP and queries the database for
-// latitude and longitude
-func (g GeoService) GetCoordinatesFromIP(ip string) (*models.Geography, error) {
+// latitude and longitude. The lookup is not made once the context is done
+func (g GeoService) GetCoordinatesFromIP(ctx context.Context, ip string) (*models.Geography, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, span := tracing.Start(ctx, "geolocate.GetCoordinatesFromIP")
+	start := time.Now()
+	geo, err := g.lookup(ip)
+	if geo != nil {
+		span.SetAttributes(attribute.String("geo.country", geo.Country), attribute.Int64("geo.radius", int64(geo.Radius)))
+	}
+	tracing.End(span, err)
+
+	if logger := logging.FromContext(ctx); logger.Enabled(logging.LevelDebug) {
+		fields := []interface{}{"ip", ip, "duration", time.Since(start)}
+		if err != nil {
+			fields = append(fields, "error", err)
+		}
+		logger.Debug("geolocation", fields...)
+	}
+	return geo, err
+}
+
+func (g GeoService) lookup(ip string) (*models.Geography, error) {
 	var geo models.Geography
 	netIP := net.ParseIP(ip)
 	if netIP == nil {
@@ -42,12 +96,25 @@ func (g GeoService) GetCoordinatesFromIP(ip string) (*models.Geography, error) {
 	geo.Latitude = record.Location.Latitude
 	geo.Longitude = record.Location.Longitude
 	geo.Radius = record.Location.AccuracyRadius
-	return &geo, nil
+	geo.Country = record.Country.IsoCode
+	geo.TimeZone = record.Location.TimeZone
+
+	if g.asn != nil {
+		asn, err := g.asn.ASN(netIP)
+		if err != nil {
+			return nil, err
+		}
+		geo.ASN = asn.AutonomousSystemNumber
+	}
 
+	return &geo, nil
 }
 
 // Close closes the GeoService repository
 func (g GeoService) Close() error {
+	if g.asn != nil {
+		g.asn.Close()
+	}
 	return g.db.Close()
 
 }
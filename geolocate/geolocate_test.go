@@ -1,6 +1,7 @@
 package geolocate
 
 import (
+	"context"
 	"path"
 	"path/filepath"
 	"testing"
@@ -36,7 +37,7 @@ func TestGetCoordinatesByIP(t *testing.T) {
 		},
 		"invalidIPv4": {
 			IP:          "300.300.300.300",
-			ExpectedErr: &errors.InvalidIP{"300.300.300.300"},
+			ExpectedErr: &errors.InvalidIP{IP: "300.300.300.300"},
 		},
 		"validIPv6": {
 			IP:          "2001:4860:4860::8888",
@@ -44,13 +45,13 @@ func TestGetCoordinatesByIP(t *testing.T) {
 		},
 		"invalidIPv6": {
 			IP:          "2001:4860:4860:8888",
-			ExpectedErr: &errors.InvalidIP{"2001:4860:4860:8888"},
+			ExpectedErr: &errors.InvalidIP{IP: "2001:4860:4860:8888"},
 		},
 	}
 
 	for name, test := range tests {
 		t.Logf("Running test case %s", name)
-		geo, err := geoSvc.GetCoordinatesFromIP(test.IP)
+		geo, err := geoSvc.GetCoordinatesFromIP(context.Background(), test.IP)
 
 		if err != nil {
 			assert.Equal(t, test.ExpectedErr, err)
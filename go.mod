@@ -1,13 +1,22 @@
 module github.com/txross1993/superman-api
 
-go 1.14
+go 1.16
 
 require (
 	github.com/gin-gonic/gin v1.6.3
 	github.com/go-playground/assert/v2 v2.0.1
 	github.com/jinzhu/gorm v1.9.12
 	github.com/oschwald/geoip2-golang v1.4.0
-	github.com/stretchr/testify v1.4.0
+	github.com/segmentio/kafka-go v0.4.16
+	github.com/stretchr/testify v1.7.1
 	github.com/umahmood/haversine v0.0.0-20151105152445-808ab04add26
-	golang.org/x/sys v0.0.0-20200602225109-6fdc65e7d980 // indirect
+	go.opentelemetry.io/otel v1.6.3
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc v1.6.3
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.6.3
+	go.opentelemetry.io/otel/sdk v1.6.3
+	go.opentelemetry.io/otel/trace v1.6.3
+	golang.org/x/time v0.0.0-20191024005414-555d28b269f0
+	google.golang.org/grpc v1.45.0
+	google.golang.org/protobuf v1.28.0
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/txross1993/superman-api/auth"
+	"github.com/txross1993/superman-api/config"
+	"github.com/txross1993/superman-api/db"
+	"github.com/txross1993/superman-api/models"
+)
+
+const keysUsage = `usage: superman-api keys <command> [flags]
+
+commands:
+  create  issue a new api key and print it once
+  list    list issued api keys
+  revoke  revoke an api key by name
+`
+
+// runKeys dispatches the api key administration subcommands
+func runKeys(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(keysUsage)
+	}
+
+	switch args[0] {
+	case "create":
+		return createKey(args[1:], out)
+	case "list":
+		return listKeys(args[1:], out)
+	case "revoke":
+		return revokeKey(args[1:], out)
+	}
+
+	return fmt.Errorf("unknown keys command %q\n%s", args[0], keysUsage)
+}
+
+func createKey(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("keys create", flag.ContinueOnError)
+	name := fs.String("name", "", "Provide a unique name identifying the api client")
+	scopes := fs.String("scopes", string(models.ScopeIngest), "Provide a comma separated list of scopes: ingest, read, cross-tenant, admin")
+	expires := fs.Duration("expires", 0, "Provide a lifetime after which the key expires, zero never expires")
+	tenant := fs.String("tenant", models.DefaultTenant, "Provide the tenant the key is bound to, empty lets the client choose with the X-Tenant-ID header")
+	cfg, err := config.Load(fs, args, os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return errors.New("-name is required")
+	}
+
+	if !models.ValidTenant(*tenant) {
+		return fmt.Errorf("invalid tenant %q", *tenant)
+	}
+
+	key := models.APIKey{Name: *name, Tenant: *tenant}
+	var granted []models.Scope
+	for _, s := range strings.Split(*scopes, ",") {
+		scope := models.Scope(strings.TrimSpace(s))
+		if !models.ValidScope(scope) {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+		granted = append(granted, scope)
+	}
+	key.SetScopes(granted)
+
+	if *expires > 0 {
+		expiresAt := time.Now().Add(*expires).UTC()
+		key.ExpiresAt = &expiresAt
+	}
+
+	plaintext, err := auth.NewKey()
+	if err != nil {
+		return err
+	}
+	key.KeyHash = auth.Hash(plaintext)
+	key.Prefix = auth.Prefix(plaintext)
+
+	sqlDB, err := openKeysDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	if err := sqlDB.CreateAPIKey(&key); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "created api key %q with scopes %s\n", key.Name, key.Scopes)
+	fmt.Fprintf(out, "store this key now, it will not be shown again:\n%s\n", plaintext)
+	return nil
+}
+
+func listKeys(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("keys list", flag.ContinueOnError)
+	cfg, err := config.Load(fs, args, os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	sqlDB, err := openKeysDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	keys, err := sqlDB.ListAPIKeys()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPREFIX\tTENANT\tSCOPES\tCREATED\tEXPIRES\tSTATUS")
+	for _, key := range keys {
+		expires := "never"
+		if key.ExpiresAt != nil {
+			expires = key.ExpiresAt.Format(time.RFC3339)
+		}
+
+		status := "active"
+		switch {
+		case key.RevokedAt != nil:
+			status = "revoked"
+		case !key.Active(now):
+			status = "expired"
+		}
+
+		tenant := key.Tenant
+		if tenant == models.DefaultTenant {
+			tenant = "-"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", key.Name, key.Prefix, tenant, key.Scopes, key.CreatedAt.Format(time.RFC3339), expires, status)
+	}
+	return w.Flush()
+}
+
+func revokeKey(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("keys revoke", flag.ContinueOnError)
+	name := fs.String("name", "", "Provide the name of the api key to revoke")
+	cfg, err := config.Load(fs, args, os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return errors.New("-name is required")
+	}
+
+	sqlDB, err := openKeysDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	if err := sqlDB.RevokeAPIKey(*name, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "revoked api key %q\n", *name)
+	return nil
+}
+
+// openKeysDB opens the database of the configuration, resolved from the
+// file, environment and flags as the server resolves it, so that keys are
+// administered where the server reads them
+func openKeysDB(cfg *config.Config) (db.DB, error) {
+	return db.InitDB(path.Join(cfg.Storage.DBPath, "local.db"), db.WithPool(cfg.Pool()))
+}
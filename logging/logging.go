@@ -0,0 +1,243 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the severity of a log line
+type Level int
+
+const (
+	// LevelDebug details the work done for a request
+	LevelDebug Level = iota
+	// LevelInfo records normal operation such as served requests
+	LevelInfo
+	// LevelWarn records a rejected request or a recoverable failure
+	LevelWarn
+	// LevelError records a failure needing attention
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// ParseLevel parses a level name such as "info"
+func ParseLevel(name string) (Level, error) {
+	for level, n := range levelNames {
+		if strings.EqualFold(name, n) {
+			return level, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", name)
+}
+
+// output is the destination shared by a logger and the loggers derived
+// from it
+type output struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level Level
+	now   func() time.Time
+}
+
+// Logger writes a json object per line holding the time, level, message,
+// the logger's fields and the fields of the call. Fields are alternating
+// keys and values
+type Logger struct {
+	out    *output
+	fields []interface{}
+}
+
+// New creates a logger writing lines at or above the level to w
+func New(w io.Writer, level Level) *Logger {
+	return &Logger{out: &output{w: w, level: level, now: time.Now}}
+}
+
+// With returns a logger adding the fields to every line
+func (l *Logger) With(fields ...interface{}) *Logger {
+	combined := make([]interface{}, 0, len(l.fields)+len(fields))
+	combined = append(combined, l.fields...)
+	combined = append(combined, fields...)
+	return &Logger{out: l.out, fields: combined}
+}
+
+// Enabled reports whether lines at the level are written
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.out.level
+}
+
+// Debug writes a line at LevelDebug
+func (l *Logger) Debug(msg string, fields ...interface{}) {
+	l.Log(LevelDebug, msg, fields...)
+}
+
+// Info writes a line at LevelInfo
+func (l *Logger) Info(msg string, fields ...interface{}) {
+	l.Log(LevelInfo, msg, fields...)
+}
+
+// Warn writes a line at LevelWarn
+func (l *Logger) Warn(msg string, fields ...interface{}) {
+	l.Log(LevelWarn, msg, fields...)
+}
+
+// Error writes a line at LevelError
+func (l *Logger) Error(msg string, fields ...interface{}) {
+	l.Log(LevelError, msg, fields...)
+}
+
+// Log writes a line at the level if enabled
+func (l *Logger) Log(level Level, msg string, fields ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	writeField(&buf, "time", l.out.now().UTC().Format(time.RFC3339Nano))
+	buf.WriteByte(',')
+	writeField(&buf, "level", level.String())
+	buf.WriteByte(',')
+	writeField(&buf, "msg", msg)
+	writeFields(&buf, l.fields)
+	writeFields(&buf, fields)
+	buf.WriteString("}\n")
+
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	l.out.w.Write(buf.Bytes())
+}
+
+// writeFields writes alternating keys and values, naming a trailing value
+// without a key "!BADKEY"
+func writeFields(buf *bytes.Buffer, fields []interface{}) {
+	for i := 0; i < len(fields); i += 2 {
+		key, ok := fields[i].(string)
+		if !ok || i+1 == len(fields) {
+			key, i = "!BADKEY", i-1
+		}
+		buf.WriteByte(',')
+		writeField(buf, key, fields[i+1])
+	}
+}
+
+func writeField(buf *bytes.Buffer, key string, value interface{}) {
+	switch v := value.(type) {
+	case time.Time:
+	case error:
+		value = v.Error()
+	case time.Duration:
+		value = v.String()
+	case fmt.Stringer:
+		value = v.String()
+	}
+
+	k, _ := json.Marshal(key)
+	buf.Write(k)
+	buf.WriteByte(':')
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(value))
+	}
+	buf.Write(b)
+}
+
+// Writer returns a writer logging each line written to it as the message
+// of a line at the level, for libraries which log with the standard logger
+func (l *Logger) Writer(level Level) io.Writer {
+	return lineWriter{logger: l, level: level}
+}
+
+type lineWriter struct {
+	logger *Logger
+	level  Level
+}
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		w.logger.Log(w.level, line)
+	}
+	return len(p), nil
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultLogger = New(os.Stderr, LevelInfo)
+)
+
+// Default returns the logger used where a context carries none
+func Default() *Logger {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultLogger
+}
+
+// SetDefault replaces the logger used where a context carries none
+func SetDefault(l *Logger) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultLogger = l
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying the logger
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger carried by the context, or the default
+// logger
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return l
+	}
+	return Default()
+}
+
+// maxRequestIDLen bounds caller provided request ids
+const maxRequestIDLen = 128
+
+// NewRequestID returns a random id for a request whose caller provided none
+func NewRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ValidRequestID accepts printable ascii ids without spaces so that caller
+// provided values are safe to echo and log
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+
+	for _, r := range id {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,142 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+	tests := map[string]struct {
+		level    Level
+		log      func(l *Logger)
+		expected []map[string]interface{}
+	}{
+		"Fields": {
+			level: LevelInfo,
+			log: func(l *Logger) {
+				l.Info("served", "status", 200, "duration", 1500*time.Millisecond, "error", errors.New("boom"))
+			},
+			expected: []map[string]interface{}{
+				{"level": "info", "msg": "served", "status": 200.0, "duration": "1.5s", "error": "boom"},
+			},
+		},
+		"Below Level": {
+			level: LevelWarn,
+			log: func(l *Logger) {
+				l.Debug("detail")
+				l.Info("served")
+				l.Warn("rejected")
+				l.Error("failed")
+			},
+			expected: []map[string]interface{}{
+				{"level": "warn", "msg": "rejected"},
+				{"level": "error", "msg": "failed"},
+			},
+		},
+		"With": {
+			level: LevelDebug,
+			log: func(l *Logger) {
+				l.With("request_id", "abc").Debug("lookup", "ip", "10.0.0.1")
+			},
+			expected: []map[string]interface{}{
+				{"level": "debug", "msg": "lookup", "request_id": "abc", "ip": "10.0.0.1"},
+			},
+		},
+		"Missing Key": {
+			level: LevelInfo,
+			log: func(l *Logger) {
+				l.Info("odd", "status", 200, "dangling")
+			},
+			expected: []map[string]interface{}{
+				{"level": "info", "msg": "odd", "status": 200.0, "!BADKEY": "dangling"},
+			},
+		},
+		"Writer": {
+			level: LevelInfo,
+			log: func(l *Logger) {
+				fmt.Fprint(l.Writer(LevelError), "first\nsecond\n")
+			},
+			expected: []map[string]interface{}{
+				{"level": "error", "msg": "first"},
+				{"level": "error", "msg": "second"},
+			},
+		},
+	}
+
+	now := time.Date(2020, 6, 2, 12, 0, 0, 0, time.UTC)
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		var out bytes.Buffer
+		l := New(&out, test.level)
+		l.out.now = func() time.Time { return now }
+		test.log(l)
+
+		var lines []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var fields map[string]interface{}
+			if assert.NoError(t, json.Unmarshal([]byte(line), &fields), line) {
+				assert.Equal(t, "2020-06-02T12:00:00Z", fields["time"])
+				delete(fields, "time")
+				lines = append(lines, fields)
+			}
+		}
+		assert.Equal(t, test.expected, lines)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]struct {
+		name     string
+		expected Level
+		valid    bool
+	}{
+		"Debug":      {name: "debug", expected: LevelDebug, valid: true},
+		"Upper Case": {name: "WARN", expected: LevelWarn, valid: true},
+		"Unknown":    {name: "verbose", expected: LevelInfo},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		level, err := ParseLevel(test.name)
+		assert.Equal(t, test.expected, level)
+		assert.Equal(t, test.valid, err == nil)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, Default(), FromContext(context.Background()))
+
+	l := New(&bytes.Buffer{}, LevelDebug)
+	assert.Equal(t, l, FromContext(NewContext(context.Background(), l)))
+}
+
+func TestValidRequestID(t *testing.T) {
+	tests := map[string]struct {
+		id       string
+		expected bool
+	}{
+		"Generated":     {id: NewRequestID(), expected: true},
+		"Caller Chosen": {id: "req-42/retry", expected: true},
+		"Empty":         {id: ""},
+		"Too Long":      {id: strings.Repeat("a", 129)},
+		"Space":         {id: "req 42"},
+		"Newline":       {id: "req\n42"},
+		"Non ASCII":     {id: "réq"},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		assert.Equal(t, test.expected, ValidRequestID(test.id))
+	}
+}
@@ -1,43 +1,151 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
+	"net"
 	"os"
 	"path"
+	"time"
+
+	"github.com/gin-gonic/gin"
 
 	"github.com/txross1993/superman-api/api"
+	"github.com/txross1993/superman-api/config"
+	"github.com/txross1993/superman-api/consume"
 	"github.com/txross1993/superman-api/db"
 	"github.com/txross1993/superman-api/geolocate"
+	"github.com/txross1993/superman-api/logging"
+	"github.com/txross1993/superman-api/notify"
+	"github.com/txross1993/superman-api/rpc"
+	"github.com/txross1993/superman-api/stream"
 	"github.com/txross1993/superman-api/superman"
+	"github.com/txross1993/superman-api/tracing"
 )
 
 func main() {
-	var apiCfg api.Config
-	var geoliteRepository string
-	var dataPath string
-	flag.StringVar(&apiCfg.Host, "host", getEnvOrDefault("HOST", "0.0.0.0"), "Provide the bind address for hosting the api")
-	flag.StringVar(&apiCfg.Port, "port", getEnvOrDefault("PORT", "8080"), "Provide the bind port for hosting the api")
-	flag.StringVar(&geoliteRepository, "geodb", getEnvOrDefault("GEODB", "GeoLite2-City_20200602/GeoLite2-City.mmdb"), "Provide the fully qualified path to the GeoLite2 database *.mmdb file")
-	flag.StringVar(&dataPath, "dbpath", getEnvOrDefault("DBPATH", "local-db"), "Provide the fully qualified path to the sqlite database host directory")
-	flag.Parse()
-
-	geoSvc, err := geolocate.NewGeoService(geoliteRepository)
+	if len(os.Args) > 1 && os.Args[1] == "keys" {
+		if err := runKeys(os.Args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := runConfig(os.Args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	consuming := len(os.Args) > 1 && os.Args[1] == "consume"
+	args := os.Args[1:]
+	if consuming {
+		args = args[1:]
+	}
+
+	cfg, err := config.Load(flag.CommandLine, args, os.Getenv)
+	if err != nil {
+		log.Fatal(err)
+	}
+	setupLogging(cfg.LogLevel())
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TracingOptions())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownTracing(ctx)
+	}()
+
+	var geoOpts []geolocate.Option
+	if cfg.Storage.ASNDB != "" {
+		geoOpts = append(geoOpts, geolocate.WithASNDatabase(cfg.Storage.ASNDB))
+	}
+
+	geoSvc, err := geolocate.NewGeoService(cfg.Storage.GeoDB, geoOpts...)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer geoSvc.Close()
 
-	localDb := path.Join(dataPath, "local.db")
+	localDb := path.Join(cfg.Storage.DBPath, "local.db")
 
-	sqlDB, err := db.InitDB(localDb)
+	sqlDB, err := db.InitDB(localDb, db.WithPool(cfg.Pool()))
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer sqlDB.Close()
 
-	superman := superman.NewService(geoSvc, sqlDB)
-	apiCfg.Superman = superman
+	policy, tenantPolicies, err := cfg.Policies()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	broker := stream.NewBroker(cfg.Stream.Buffer)
+	serviceOpts := []superman.ServiceOpt{superman.WithPolicy(policy), superman.WithNotifier(broker)}
+	for tenant, p := range tenantPolicies {
+		serviceOpts = append(serviceOpts, superman.WithTenantPolicy(tenant, p))
+	}
+
+	if destinations := webhookDestinations(cfg.Webhooks.URLs, cfg.Webhooks.Secret); len(destinations) > 0 {
+		dispatcher := notify.NewDispatcher(sqlDB, destinations, notify.WithBackoff(cfg.Backoff()))
+		stop := make(chan struct{})
+		defer close(stop)
+		go dispatcher.Run(stop)
+		serviceOpts = append(serviceOpts, superman.WithNotifier(dispatcher))
+	}
+
+	if consuming {
+		verdicts := newVerdictWriter(cfg.Kafka)
+		defer verdicts.Close()
+		serviceOpts = append(serviceOpts, superman.WithNotifier(consume.NewProducer(verdicts)))
+	}
+
+	superman := superman.NewService(geoSvc, sqlDB, serviceOpts...)
+	go reloadOnHangup(args, cfg, superman)
+
+	if consuming {
+		if err := runConsumer(cfg.Kafka, cfg.ValidationPolicy(), superman); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	apiCfg := api.Config{
+		Host:     cfg.Server.Host,
+		Port:     cfg.Server.Port,
+		Superman: superman,
+		Keys:     sqlDB,
+		RateLimit: api.RateLimit{
+			RequestsPerSecond: cfg.Limits.RateLimit,
+			Burst:             cfg.Limits.RateBurst,
+		},
+		MaxBodyBytes:    cfg.Limits.MaxBodyBytes,
+		DeadLetters:     sqlDB,
+		Stream:          broker,
+		StreamHeartbeat: cfg.Stream.Heartbeat,
+		RequestTimeout:  cfg.Server.RequestTimeout,
+		Validation:      cfg.ValidationPolicy(),
+	}
+
+	if cfg.Server.GRPCPort != "" {
+		lis, err := net.Listen("tcp", net.JoinHostPort(cfg.Server.Host, cfg.Server.GRPCPort))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		grpcServer := rpc.NewServer(superman, rpc.WithKeystore(sqlDB), rpc.WithTimeout(cfg.Server.RequestTimeout), rpc.WithValidationPolicy(cfg.ValidationPolicy()))
+		defer grpcServer.Stop()
+		go func() {
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	}
 
 	api := api.NewAPI(apiCfg)
 
@@ -47,10 +155,25 @@ func main() {
 
 }
 
-func getEnvOrDefault(val, defaultVal string) string {
-	if env := os.Getenv(val); env != "" {
-		return env
+// webhookDestinations returns the webhook urls, each signed with the shared
+// secret
+func webhookDestinations(urls []string, secret string) []notify.Destination {
+	var destinations []notify.Destination
+	for _, url := range urls {
+		destinations = append(destinations, notify.Destination{URL: url, Secret: secret})
 	}
+	return destinations
+}
+
+// setupLogging writes json lines at or above the level to stderr, including
+// gin's debug output and the standard logger's, which after startup only
+// reports fatal errors
+func setupLogging(level logging.Level) {
+	logger := logging.New(os.Stderr, level)
+	logging.SetDefault(logger)
 
-	return defaultVal
+	log.SetFlags(0)
+	log.SetOutput(logger.Writer(logging.LevelError))
+	gin.DefaultWriter = logger.Writer(logging.LevelDebug)
+	gin.DefaultErrorWriter = logger.Writer(logging.LevelError)
 }
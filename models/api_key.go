@@ -0,0 +1,87 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Scope represents a permission granted to an api key
+type Scope string
+
+const (
+	// ScopeIngest permits submitting user ip access events for analysis
+	ScopeIngest Scope = "ingest"
+	// ScopeRead permits reading analysis results and user history
+	ScopeRead Scope = "read"
+	// ScopeCrossTenant permits a key bound to no tenant to act for the
+	// tenant a request names
+	ScopeCrossTenant Scope = "cross-tenant"
+	// ScopeAdmin permits every operation
+	ScopeAdmin Scope = "admin"
+)
+
+// ValidScope reports whether the scope is one the api recognizes
+func ValidScope(scope Scope) bool {
+	switch scope {
+	case ScopeIngest, ScopeRead, ScopeCrossTenant, ScopeAdmin:
+		return true
+	}
+	return false
+}
+
+// APIKey represents a credential issued to an api client. Only the hash of
+// the key is stored; the plaintext key is shown once at creation time
+type APIKey struct {
+	ID        uint       `json:"id" gorm:"primary_key"`
+	Name      string     `json:"name" gorm:"not null;unique_index"`
+	KeyHash   string     `json:"-" gorm:"not null;unique_index"`
+	Prefix    string     `json:"prefix" gorm:"not null"`
+	Scopes    string     `json:"scopes" gorm:"not null"`
+	Tenant    string     `json:"tenant,omitempty" gorm:"not null;default:''"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// SetScopes stores the provided scopes on the key
+func (k *APIKey) SetScopes(scopes []Scope) {
+	names := make([]string, len(scopes))
+	for i, scope := range scopes {
+		names[i] = string(scope)
+	}
+	k.Scopes = strings.Join(names, ",")
+}
+
+// ScopeList returns the scopes granted to the key
+func (k *APIKey) ScopeList() []Scope {
+	var scopes []Scope
+	for _, name := range strings.Split(k.Scopes, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			scopes = append(scopes, Scope(name))
+		}
+	}
+	return scopes
+}
+
+// HasScope reports whether the key grants the scope. The admin scope
+// grants every other scope
+func (k *APIKey) HasScope(scope Scope) bool {
+	for _, granted := range k.ScopeList() {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether the key is neither revoked nor expired at the
+// provided time
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	if k.ExpiresAt != nil && !now.Before(*k.ExpiresAt) {
+		return false
+	}
+	return true
+}
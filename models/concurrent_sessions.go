@@ -0,0 +1,12 @@
+package models
+
+// ConcurrentSessions represents a user active from two or more distinct
+// locations which alternate within a short period, indicating shared
+// credentials rather than travel
+type ConcurrentSessions struct {
+	Locations            []*Geography `json:"locations"`
+	Alternations         int          `json:"alternations"`
+	FirstTimestampMillis int64        `json:"firstTimestampMillis"`
+	LastTimestampMillis  int64        `json:"lastTimestampMillis"`
+	Events               []*IPAccess  `json:"events"`
+}
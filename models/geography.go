@@ -2,11 +2,19 @@ package models
 
 import "github.com/umahmood/haversine"
 
+// kmToMiles converts the geoencoder accuracy radius to miles
+const kmToMiles = 0.621371
+
 // Geography represents a lat,lon, and accuracy radius of the coordinates
+// along with the country, time zone and autonomous system of the address
+// when known
 type Geography struct {
 	Latitude  float64 `json:"lat"`
 	Longitude float64 `json:"lon"`
 	Radius    uint16  `json:"radius"`
+	Country   string  `json:"country,omitempty"`
+	TimeZone  string  `json:"timeZone,omitempty"`
+	ASN       uint    `json:"asn,omitempty"`
 }
 
 // MilesFrom calculates the miles between this coordinate and the provided point
@@ -21,6 +29,11 @@ func (g *Geography) MilesFrom(coordinate *Geography) float64 {
 	return distanceMiles
 }
 
+// AccuracyMiles returns the accuracy radius of the coordinates in miles
+func (g *Geography) AccuracyMiles() float64 {
+	return float64(g.Radius) * kmToMiles
+}
+
 // haversineCoord returns the point as a haversine coordinate for calculating
 // distance
 func (g *Geography) haversineCoord() haversine.Coord {
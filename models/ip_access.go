@@ -1,10 +1,36 @@
 package models
 
+// TravelAssessment names how the travel between two ip access events was
+// judged
+type TravelAssessment string
+
+const (
+	// AssessmentCoLocated indicates both events geoencode to the same place,
+	// which is never suspicious however close together they occurred
+	AssessmentCoLocated TravelAssessment = "co_located"
+	// AssessmentDistance indicates the events occurred within the minimum
+	// time window, so distance alone decided
+	AssessmentDistance TravelAssessment = "distance"
+	// AssessmentSpeed indicates the travel speed between the events decided
+	AssessmentSpeed TravelAssessment = "speed"
+	// AssessmentUnknown indicates an event could not be geoencoded
+	AssessmentUnknown TravelAssessment = "unknown"
+	// AssessmentAllowed indicates an event came from a network allowed by
+	// the tenant's policy, such as a vpn egress, whose location says nothing
+	// about where the user is
+	AssessmentAllowed TravelAssessment = "allowed"
+)
+
 // IPAccess represents a user ip access event with nonessential columns from
 // the database model dropped
 type IPAccess struct {
 	*Geography
-	IP        string `json:"ip"`
-	Speed     int64  `json:"speed"`
-	Timestamp int64  `json:"timestamp"`
+	EventUUID       string           `json:"-"`
+	IP              string           `json:"ip"`
+	Speed           int64            `json:"speed"`
+	Distance        float64          `json:"distance"`
+	Assessment      TravelAssessment `json:"assessment,omitempty"`
+	Suspicious      bool             `json:"-"`
+	Timestamp       int64            `json:"timestamp"`
+	TimestampMillis int64            `json:"timestampMillis"`
 }
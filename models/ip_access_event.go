@@ -1,43 +1,85 @@
 package models
 
-import (
-	"encoding/json"
-	"net"
-
-	"github.com/txross1993/superman-api/errors"
-)
+import "encoding/json"
 
 // UserIPAccessEvent represents an instance of access from an IP address for a
-// given username
+// given username of a tenant. The tenant is never read from the request body
+// but from the credential the event was submitted with, and event uuids are
+// unique within a tenant
 type UserIPAccessEvent struct {
 	EventUUID     string `json:"event_uuid" gorm:"primary_key"`
-	Username      string `json:"username" gorm:"not null" sql:"index"`
-	UnixTimestamp int64  `json:"unix_timestamp" gorm:"not null" sql:"index"`
+	Username      string `json:"username" gorm:"not null"`
+	UnixTimestamp int64  `json:"unix_timestamp" gorm:"not null"`
+	UnixMillis    int64  `json:"-" gorm:"not null;default:0" sql:"index"`
 	IPAddress     string `json:"ip_address" gorm:"not null"`
+	Client        string `json:"-"`
+	Tenant        string `json:"-" gorm:"primary_key;not null;default:''"`
 }
 
-// UnmarshalJSON performs data validation on the ip address of the event
+// UnmarshalJSON decodes the event, accepting the timestamp in any format
+// understood by parseTimestamp. The decoded fields are validated by the
+// ValidationPolicy of whoever received the event
 func (u *UserIPAccessEvent) UnmarshalJSON(data []byte) error {
 	type Alias UserIPAccessEvent
 	aux := &struct {
 		*Alias
+		UnixTimestamp json.RawMessage `json:"unix_timestamp"`
 	}{
 		Alias: (*Alias)(u),
 	}
 	if err := json.Unmarshal(data, &aux); err != nil {
 		return err
 	}
-	netIP := net.ParseIP(u.IPAddress)
-	if netIP == nil {
-		return &errors.InvalidIP{IP: u.IPAddress}
+
+	millis, err := parseTimestamp(aux.UnixTimestamp)
+	if err != nil {
+		return err
 	}
+	u.SetMillis(millis)
+
+	return nil
+}
+
+// SetMillis sets the event time from epoch milliseconds, keeping the
+// seconds resolution UnixTimestamp consistent
+func (u *UserIPAccessEvent) SetMillis(ms int64) {
+	u.UnixMillis = ms
+	u.UnixTimestamp = floorDiv(ms, 1000)
+}
+
+// Millis returns the event time in epoch milliseconds. Events constructed
+// with only a seconds resolution timestamp are treated as occurring at the
+// start of that second
+func (u *UserIPAccessEvent) Millis() int64 {
+	if u.UnixMillis != 0 {
+		return u.UnixMillis
+	}
+	return u.UnixTimestamp * 1000
+}
+
+// Precedes orders events totally by time and then by uuid, so that events
+// at the same instant have a stable order regardless of when they were
+// stored
+func (u *UserIPAccessEvent) Precedes(other *UserIPAccessEvent) bool {
+	if u.Millis() != other.Millis() {
+		return u.Millis() < other.Millis()
+	}
+	return u.EventUUID < other.EventUUID
+}
+
+// BeforeSave ensures the millisecond timestamp is populated before the
+// event is persisted
+func (u *UserIPAccessEvent) BeforeSave() error {
+	u.UnixMillis = u.Millis()
 	return nil
 }
 
 // AsIPAccess performs data translation from this model to the IPAccess model
 func (u *UserIPAccessEvent) AsIPAccess() *IPAccess {
 	return &IPAccess{
-		IP:        u.IPAddress,
-		Timestamp: u.UnixTimestamp,
+		EventUUID:       u.EventUUID,
+		IP:              u.IPAddress,
+		Timestamp:       u.UnixTimestamp,
+		TimestampMillis: u.Millis(),
 	}
 }
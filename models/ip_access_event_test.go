@@ -2,7 +2,9 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/txross1993/superman-api/errors"
@@ -14,12 +16,211 @@ func TestUnmarshalValidation(t *testing.T) {
 	b, _ := json.Marshal(&UserIPAccessEvent{
 		IPAddress:     invalidIP,
 		Username:      "bob",
-		EventUUID:     "abc",
+		EventUUID:     "85ad929a-db03-4bf4-9541-8f728fa12e42",
 		UnixTimestamp: 1514764800,
 	})
 
 	u := UserIPAccessEvent{}
-	err := json.Unmarshal(b, &u)
-	expected := &errors.InvalidIP{invalidIP}
+	if !assert.NoError(t, json.Unmarshal(b, &u)) {
+		return
+	}
+	err := DefaultValidationPolicy().Validate(&u, time.Unix(1514764800, 0))
+	expected := &errors.InvalidIP{IP: invalidIP}
 	assert.EqualError(t, err, expected.Error())
 }
+
+func TestValidate(t *testing.T) {
+	current := time.Unix(1514764800, 0)
+
+	valid := func() *UserIPAccessEvent {
+		return &UserIPAccessEvent{
+			EventUUID:     "85ad929a-db03-4bf4-9541-8f728fa12e42",
+			Username:      "bob",
+			UnixTimestamp: current.Unix(),
+			IPAddress:     "206.81.252.200",
+		}
+	}
+
+	tests := map[string]struct {
+		policy ValidationPolicy
+		modify func(u *UserIPAccessEvent)
+		want   map[string]errors.Code
+	}{
+		"valid": {
+			modify: func(u *UserIPAccessEvent) {},
+		},
+		"every field invalid": {
+			modify: func(u *UserIPAccessEvent) {
+				u.EventUUID = "abc"
+				u.Username = " "
+				u.UnixTimestamp = 0
+				u.IPAddress = "300.300.300.300"
+			},
+			want: map[string]errors.Code{
+				"event_uuid":     errors.CodeInvalidField,
+				"username":       errors.CodeMissingField,
+				"unix_timestamp": errors.CodeMissingField,
+				"ip_address":     errors.CodeInvalidIP,
+			},
+		},
+		"username too long": {
+			modify: func(u *UserIPAccessEvent) { u.Username = strings.Repeat("b", 257) },
+			want:   map[string]errors.Code{"username": errors.CodeInvalidField},
+		},
+		"username control characters": {
+			modify: func(u *UserIPAccessEvent) { u.Username = "bob\n" },
+			want:   map[string]errors.Code{"username": errors.CodeInvalidField},
+		},
+		"far future timestamp": {
+			modify: func(u *UserIPAccessEvent) { u.UnixTimestamp = current.Add(time.Hour).Unix() },
+			want:   map[string]errors.Code{"unix_timestamp": errors.CodeInvalidTimestamp},
+		},
+		"future beyond year 2262": {
+			modify: func(u *UserIPAccessEvent) { u.UnixMillis = 10413792000250 },
+			want:   map[string]errors.Code{"unix_timestamp": errors.CodeInvalidTimestamp},
+		},
+		"within future skew": {
+			modify: func(u *UserIPAccessEvent) { u.UnixTimestamp = current.Add(time.Minute).Unix() },
+		},
+		"unbounded past by default": {
+			modify: func(u *UserIPAccessEvent) { u.UnixTimestamp = current.AddDate(-10, 0, 0).Unix() },
+		},
+		"beyond configured past skew": {
+			policy: ValidationPolicy{MaxPastSkew: 24 * time.Hour, MaxFutureSkew: time.Minute},
+			modify: func(u *UserIPAccessEvent) { u.UnixTimestamp = current.AddDate(0, 0, -2).Unix() },
+			want:   map[string]errors.Code{"unix_timestamp": errors.CodeInvalidTimestamp},
+		},
+		"negative timestamp": {
+			modify: func(u *UserIPAccessEvent) { u.UnixTimestamp = -1 },
+			want:   map[string]errors.Code{"unix_timestamp": errors.CodeInvalidTimestamp},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case %s", name)
+		policy := test.policy
+		if policy == (ValidationPolicy{}) {
+			policy = DefaultValidationPolicy()
+		}
+
+		event := valid()
+		test.modify(event)
+		err := policy.Validate(event, current)
+		if len(test.want) == 0 {
+			assert.NoError(t, err)
+			continue
+		}
+
+		got := map[string]errors.Code{}
+		for _, violation := range errors.FieldsOf(err) {
+			got[violation.Field()] = violation.Code()
+		}
+		assert.Equal(t, test.want, got)
+	}
+}
+
+// TestTimestampRange tests that RFC 3339 times outside the years UnixNano
+// can represent convert to and from their epoch milliseconds
+func TestTimestampRange(t *testing.T) {
+	tests := map[string]struct {
+		timestamp  string
+		wantMillis int64
+	}{
+		"before 1678":    {timestamp: `"1600-01-01T00:00:00Z"`, wantMillis: -11676096000000},
+		"after 2262":     {timestamp: `"2300-01-01T00:00:00.25Z"`, wantMillis: 10413792000250},
+		"latest rfc3339": {timestamp: `"9999-12-31T23:59:59.999Z"`, wantMillis: 253402300799999},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case %s", name)
+		ms, err := parseTimestamp(json.RawMessage(test.timestamp))
+		assert.NoError(t, err)
+		assert.Equal(t, test.wantMillis, ms)
+		assert.Equal(t, test.timestamp, `"`+millisToTime(ms).UTC().Format(time.RFC3339Nano)+`"`)
+	}
+}
+
+func TestUnmarshalTimestampFormats(t *testing.T) {
+	tests := map[string]struct {
+		timestamp   string
+		wantMillis  int64
+		wantSeconds int64
+		wantCode    errors.Code
+	}{
+		"epoch seconds": {
+			timestamp:   `1514764800`,
+			wantMillis:  1514764800000,
+			wantSeconds: 1514764800,
+		},
+		"epoch milliseconds": {
+			timestamp:   `1514764800123`,
+			wantMillis:  1514764800123,
+			wantSeconds: 1514764800,
+		},
+		"fractional seconds": {
+			timestamp:   `1514764800.25`,
+			wantMillis:  1514764800250,
+			wantSeconds: 1514764800,
+		},
+		"rfc 3339": {
+			timestamp:   `"2018-01-01T00:00:00.5Z"`,
+			wantMillis:  1514764800500,
+			wantSeconds: 1514764800,
+		},
+		"rfc 3339 with offset": {
+			timestamp:   `"2017-12-31T19:00:00-05:00"`,
+			wantMillis:  1514764800000,
+			wantSeconds: 1514764800,
+		},
+		"numeric string": {
+			timestamp:   `"1514764800"`,
+			wantMillis:  1514764800000,
+			wantSeconds: 1514764800,
+		},
+		"unparseable string": {
+			timestamp: `"yesterday"`,
+			wantCode:  errors.CodeInvalidTimestamp,
+		},
+		"boolean": {
+			timestamp: `true`,
+			wantCode:  errors.CodeInvalidTimestamp,
+		},
+		"missing, left to validation": {
+			timestamp: `null`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case %s", name)
+		data := `{"username": "bob", "event_uuid": "85ad929a-db03-4bf4-9541-8f728fa12e42", "ip_address": "206.81.252.200", "unix_timestamp": ` + test.timestamp + `}`
+
+		var u UserIPAccessEvent
+		err := json.Unmarshal([]byte(data), &u)
+		if test.wantCode != "" {
+			assert.Equal(t, test.wantCode, errors.CodeOf(err))
+			continue
+		}
+
+		assert.NoError(t, err)
+		assert.Equal(t, test.wantMillis, u.Millis())
+		assert.Equal(t, test.wantSeconds, u.UnixTimestamp)
+	}
+}
+
+func TestPrecedes(t *testing.T) {
+	tests := map[string]struct {
+		a, b     UserIPAccessEvent
+		expected bool
+	}{
+		"Earlier":              {a: UserIPAccessEvent{EventUUID: "b", UnixMillis: 1000}, b: UserIPAccessEvent{EventUUID: "a", UnixMillis: 2000}, expected: true},
+		"Later":                {a: UserIPAccessEvent{EventUUID: "a", UnixMillis: 2000}, b: UserIPAccessEvent{EventUUID: "b", UnixMillis: 1000}},
+		"Same Instant By UUID": {a: UserIPAccessEvent{EventUUID: "a", UnixMillis: 1000}, b: UserIPAccessEvent{EventUUID: "b", UnixMillis: 1000}, expected: true},
+		"Seconds And Millis":   {a: UserIPAccessEvent{EventUUID: "b", UnixTimestamp: 1}, b: UserIPAccessEvent{EventUUID: "a", UnixMillis: 1000}},
+		"Itself":               {a: UserIPAccessEvent{EventUUID: "a", UnixMillis: 1000}, b: UserIPAccessEvent{EventUUID: "a", UnixMillis: 1000}},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		assert.Equal(t, test.expected, test.a.Precedes(&test.b))
+	}
+}
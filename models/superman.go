@@ -2,11 +2,15 @@ package models
 
 // Superman encapsulates the main Superman API response
 type Superman struct {
-	CurrentGeo           *Geography `json:"currentGeo"`
-	TravelToSuspicious   bool       `json:"travelToCurrentGeoSuspicious"`
-	TravelFromSuspicious bool       `json:"travelFromCurrentGeoSuspicious"`
-	PrecedingIPAccess    *IPAccess  `json:"precedingIpAccess,omitempty"`
-	SubsequentIPAccess   *IPAccess  `json:"subsequentIpAccess,omitempty"`
+	CurrentGeo           *Geography          `json:"currentGeo"`
+	TravelToSuspicious   bool                `json:"travelToCurrentGeoSuspicious"`
+	TravelFromSuspicious bool                `json:"travelFromCurrentGeoSuspicious"`
+	PrecedingIPAccess    *IPAccess           `json:"precedingIpAccess,omitempty"`
+	SubsequentIPAccess   *IPAccess           `json:"subsequentIpAccess,omitempty"`
+	SuspiciousPaths      []*TravelPath       `json:"suspiciousPaths,omitempty"`
+	ConcurrentSessions   *ConcurrentSessions `json:"concurrentSessions,omitempty"`
+	UnfamiliarLocation   *UnfamiliarLocation `json:"unfamiliarLocation,omitempty"`
+	UnusualHour          *UnusualHour        `json:"unusualHour,omitempty"`
 }
 
 // SupermanOpt represents a functional option for building a Superman response
@@ -36,10 +40,7 @@ func WithPrecedingEvent(event *IPAccess) SupermanOpt {
 		s.PrecedingIPAccess = event
 
 		if event != nil {
-			if s.PrecedingIPAccess.Speed >= 500 {
-				s.TravelToSuspicious = true
-			}
-
+			s.TravelToSuspicious = event.Suspicious
 		}
 	}
 }
@@ -49,11 +50,37 @@ func WithPrecedingEvent(event *IPAccess) SupermanOpt {
 func WithSubsequentEvent(event *IPAccess) SupermanOpt {
 	return func(s *Superman) {
 		s.SubsequentIPAccess = event
-		if event != nil {
-			if s.SubsequentIPAccess.Speed >= 500 {
-				s.TravelFromSuspicious = true
-			}
 
+		if event != nil {
+			s.TravelFromSuspicious = event.Suspicious
 		}
 	}
 }
+
+// WithSuspiciousPaths provides the functional option for Superman.SuspiciousPaths
+func WithSuspiciousPaths(paths []*TravelPath) SupermanOpt {
+	return func(s *Superman) {
+		s.SuspiciousPaths = paths
+	}
+}
+
+// WithConcurrentSessions provides the functional option for Superman.ConcurrentSessions
+func WithConcurrentSessions(sessions *ConcurrentSessions) SupermanOpt {
+	return func(s *Superman) {
+		s.ConcurrentSessions = sessions
+	}
+}
+
+// WithUnfamiliarLocation provides the functional option for Superman.UnfamiliarLocation
+func WithUnfamiliarLocation(unfamiliar *UnfamiliarLocation) SupermanOpt {
+	return func(s *Superman) {
+		s.UnfamiliarLocation = unfamiliar
+	}
+}
+
+// WithUnusualHour provides the functional option for Superman.UnusualHour
+func WithUnusualHour(unusual *UnusualHour) SupermanOpt {
+	return func(s *Superman) {
+		s.UnusualHour = unusual
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+import "regexp"
+
+// DefaultTenant is the tenant of events submitted without one, which keeps
+// single tenant deployments working unchanged
+const DefaultTenant = ""
+
+// tenantPattern matches the identifiers accepted for tenants
+var tenantPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// ValidTenant reports whether the tenant identifier is acceptable. The
+// default tenant is always valid
+func ValidTenant(tenant string) bool {
+	return tenant == DefaultTenant || tenantPattern.MatchString(tenant)
+}
+
+// TenantFor returns the tenant a request made with the key acts for. A key
+// acts only for the tenant it is bound to, the default tenant if bound to
+// none, and reports false if another is requested. A key granted the cross
+// tenant scope, or no key when authentication is disabled, acts for the
+// requested tenant
+func (k *APIKey) TenantFor(requested string) (string, bool) {
+	if k == nil || (k.Tenant == DefaultTenant && k.HasScope(ScopeCrossTenant)) {
+		return requested, true
+	}
+	if requested != DefaultTenant && requested != k.Tenant {
+		return "", false
+	}
+	return k.Tenant, true
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantFor(t *testing.T) {
+	tests := map[string]struct {
+		key            *APIKey
+		requested      string
+		expectedTenant string
+		expectedOK     bool
+	}{
+		"No Key":                        {requested: "acme", expectedTenant: "acme", expectedOK: true},
+		"Bound Key":                     {key: &APIKey{Scopes: "ingest", Tenant: "acme"}, expectedTenant: "acme", expectedOK: true},
+		"Bound Key Naming Its Tenant":   {key: &APIKey{Scopes: "ingest", Tenant: "acme"}, requested: "acme", expectedTenant: "acme", expectedOK: true},
+		"Bound Key Naming Another":      {key: &APIKey{Scopes: "admin", Tenant: "acme"}, requested: "globex"},
+		"Unbound Key":                   {key: &APIKey{Scopes: "ingest"}, expectedTenant: DefaultTenant, expectedOK: true},
+		"Unbound Key Naming A Tenant":   {key: &APIKey{Scopes: "ingest,read"}, requested: "acme"},
+		"Cross Tenant Key":              {key: &APIKey{Scopes: "ingest,cross-tenant"}, requested: "acme", expectedTenant: "acme", expectedOK: true},
+		"Admin Key Naming A Tenant":     {key: &APIKey{Scopes: "admin"}, requested: "acme", expectedTenant: "acme", expectedOK: true},
+		"Cross Tenant Key Without Name": {key: &APIKey{Scopes: "cross-tenant"}, expectedTenant: DefaultTenant, expectedOK: true},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		tenant, ok := test.key.TenantFor(test.requested)
+		assert.Equal(t, test.expectedOK, ok)
+		assert.Equal(t, test.expectedTenant, tenant)
+	}
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/txross1993/superman-api/errors"
+)
+
+// millisThreshold separates epoch seconds from epoch milliseconds. Integer
+// timestamps at or above it are read as milliseconds: as seconds they would
+// fall after the year 5000, as milliseconds they fall after March 1973
+const millisThreshold = int64(1e11)
+
+// parseTimestamp reads an event timestamp provided as an RFC 3339 string,
+// integer epoch seconds, fractional epoch seconds, or integer epoch
+// milliseconds and returns it as epoch milliseconds. An absent or null
+// timestamp returns zero
+func parseTimestamp(raw json.RawMessage) (int64, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, nil
+	}
+
+	value := string(raw)
+	if raw[0] == '"' {
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return 0, &errors.InvalidTimestamp{Value: string(raw), Reason: "malformed string"}
+		}
+
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return timeToMillis(t), nil
+		}
+	}
+
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if i >= millisThreshold || i <= -millisThreshold {
+			return i, nil
+		}
+		return i * 1000, nil
+	}
+
+	if strings.ContainsAny(value, ".eE") {
+		if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) && math.Abs(f) < float64(millisThreshold) {
+			return int64(math.Round(f * 1000)), nil
+		}
+	}
+
+	return 0, &errors.InvalidTimestamp{Value: value, Reason: "expected an RFC 3339 string, epoch seconds, or epoch milliseconds"}
+}
+
+// timeToMillis converts a time to epoch milliseconds. Unlike UnixNano it
+// is defined for every year RFC 3339 can express, not only 1678 to 2262
+func timeToMillis(t time.Time) int64 {
+	return t.Unix()*1000 + int64(t.Nanosecond())/int64(time.Millisecond)
+}
+
+// millisToTime converts epoch milliseconds to a time, for every millisecond
+// timestamp rather than only those within the range of UnixNano
+func millisToTime(ms int64) time.Time {
+	sec := floorDiv(ms, 1000)
+	return time.Unix(sec, (ms-sec*1000)*int64(time.Millisecond))
+}
+
+// floorDiv divides rounding toward negative infinity so that pre-epoch
+// millisecond timestamps map to the second containing them
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,13 @@
+package models
+
+// TravelPath represents a chain of a user's ip access events, ordered by
+// time, in which one or more pairs of events violate the travel policy.
+// Each hop after the first carries the speed, distance, and assessment of
+// the travel from the hop before it, while the path itself carries those of
+// the most severe violating pair
+type TravelPath struct {
+	Hops       []*IPAccess      `json:"hops"`
+	Speed      int64            `json:"speed"`
+	Distance   float64          `json:"distance"`
+	Assessment TravelAssessment `json:"assessment"`
+}
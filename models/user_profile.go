@@ -0,0 +1,317 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// maxProfileLocations bounds the locations remembered for a user; the least
+// seen location is forgotten to make room for a new one
+const maxProfileLocations = 64
+
+// UserProfile summarizes where and when a username of a tenant usually logs
+// in. It is updated incrementally as each new event is analyzed. The
+// default tenant is blank, which gorm takes for an unset key, so Username
+// leads the primary key and the tenant column defaults to blank
+type UserProfile struct {
+	Username        string           `json:"username" gorm:"primary_key"`
+	Tenant          string           `json:"tenant,omitempty" gorm:"primary_key;not null;default:''"`
+	LoginCount      int64            `json:"loginCount" gorm:"not null;default:0"`
+	FirstSeenMillis int64            `json:"firstSeenMillis"`
+	LastSeenMillis  int64            `json:"lastSeenMillis"`
+	Locations       ProfileLocations `json:"locations" gorm:"type:text"`
+	Countries       Counts           `json:"countries" gorm:"type:text"`
+	ASNs            Counts           `json:"asns" gorm:"type:text"`
+	LocalHours      HourHistogram    `json:"localHours" gorm:"type:text"`
+	UpdatedAt       time.Time        `json:"updatedAt"`
+}
+
+// ProfileLocation is a cluster of nearby logins summarized by its centroid
+type ProfileLocation struct {
+	Latitude       float64 `json:"lat"`
+	Longitude      float64 `json:"lon"`
+	Radius         uint16  `json:"radius"`
+	Country        string  `json:"country,omitempty"`
+	Count          int64   `json:"count"`
+	LastSeenMillis int64   `json:"lastSeenMillis"`
+}
+
+// ProfileLocations is the list of locations stored with a profile
+type ProfileLocations []ProfileLocation
+
+// Counts tallies logins by a key such as country or autonomous system
+type Counts map[string]int64
+
+// HourHistogram tallies logins by hour of day in the user's local time
+type HourHistogram [24]int64
+
+// UnfamiliarLocation represents a login far from every location in the
+// user's profile
+type UnfamiliarLocation struct {
+	NearestMiles   float64          `json:"nearestMiles"`
+	Nearest        *ProfileLocation `json:"nearest"`
+	KnownLocations int              `json:"knownLocations"`
+	ProfileLogins  int64            `json:"profileLogins"`
+}
+
+// UnusualHour represents a login at a local hour the user rarely logs in at.
+// Confidence is the estimated probability that the user does not log in
+// within an hour of the local hour
+type UnusualHour struct {
+	LocalHour      int     `json:"localHour"`
+	TimeZone       string  `json:"timeZone"`
+	Confidence     float64 `json:"confidence"`
+	LoginsNearHour int64   `json:"loginsNearHour"`
+	HistoryLogins  int64   `json:"historyLogins"`
+}
+
+// NewUserProfile returns an empty profile for the username of the tenant
+func NewUserProfile(tenant, username string) *UserProfile {
+	return &UserProfile{
+		Tenant:    tenant,
+		Username:  username,
+		Countries: Counts{},
+		ASNs:      Counts{},
+	}
+}
+
+// Record adds a login at the provided location and time to the profile.
+// Logins within radiusMiles of a known location, or within the location's
+// own accuracy radius if larger, are counted toward that location
+func (p *UserProfile) Record(geo *Geography, millis int64, radiusMiles float64) {
+	if p.LoginCount == 0 || millis < p.FirstSeenMillis {
+		p.FirstSeenMillis = millis
+	}
+	if millis > p.LastSeenMillis {
+		p.LastSeenMillis = millis
+	}
+	p.LoginCount++
+
+	if geo == nil {
+		return
+	}
+
+	p.LocalHours[LocalTime(geo, millis).Hour()]++
+
+	if p.Countries == nil {
+		p.Countries = Counts{}
+	}
+	if geo.Country != "" {
+		p.Countries[geo.Country]++
+	}
+	if p.ASNs == nil {
+		p.ASNs = Counts{}
+	}
+	if geo.ASN != 0 {
+		p.ASNs[strconv.FormatUint(uint64(geo.ASN), 10)]++
+	}
+
+	p.recordLocation(geo, millis, radiusMiles)
+}
+
+// recordLocation moves the nearest location in range toward the login, or
+// remembers a new location
+func (p *UserProfile) recordLocation(geo *Geography, millis int64, radiusMiles float64) {
+	limit := radiusMiles
+	if accuracy := geo.AccuracyMiles(); accuracy > limit {
+		limit = accuracy
+	}
+
+	if nearest, miles := p.Nearest(geo); nearest != nil && miles <= limit {
+		nearest.Count++
+		n := float64(nearest.Count)
+		nearest.Latitude += (geo.Latitude - nearest.Latitude) / n
+		nearest.Longitude += (geo.Longitude - nearest.Longitude) / n
+		if geo.Radius > nearest.Radius {
+			nearest.Radius = geo.Radius
+		}
+		if millis > nearest.LastSeenMillis {
+			nearest.LastSeenMillis = millis
+		}
+		return
+	}
+
+	if len(p.Locations) >= maxProfileLocations {
+		sort.SliceStable(p.Locations, func(i, j int) bool {
+			a, b := p.Locations[i], p.Locations[j]
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			return a.LastSeenMillis > b.LastSeenMillis
+		})
+		p.Locations = p.Locations[:maxProfileLocations-1]
+	}
+
+	p.Locations = append(p.Locations, ProfileLocation{
+		Latitude:       geo.Latitude,
+		Longitude:      geo.Longitude,
+		Radius:         geo.Radius,
+		Country:        geo.Country,
+		Count:          1,
+		LastSeenMillis: millis,
+	})
+}
+
+// Nearest returns the known location closest to geo and its distance in
+// miles, or nil if the profile has no locations
+func (p *UserProfile) Nearest(geo *Geography) (*ProfileLocation, float64) {
+	var nearest *ProfileLocation
+	var nearestMiles float64
+	for i := range p.Locations {
+		miles := p.Locations[i].geography().MilesFrom(geo)
+		if nearest == nil || miles < nearestMiles {
+			nearest, nearestMiles = &p.Locations[i], miles
+		}
+	}
+
+	return nearest, nearestMiles
+}
+
+// Unfamiliar reports how far geo is from the profile when it lies beyond
+// radiusMiles, or beyond its own accuracy radius if larger, of every known
+// location. Nil is returned when the location is familiar or the profile has
+// fewer than minLogins logins to judge by
+func (p *UserProfile) Unfamiliar(geo *Geography, minLogins int64, radiusMiles float64) *UnfamiliarLocation {
+	if geo == nil || p.LoginCount < minLogins {
+		return nil
+	}
+
+	nearest, miles := p.Nearest(geo)
+	if nearest == nil {
+		return nil
+	}
+
+	limit := radiusMiles
+	if accuracy := geo.AccuracyMiles(); accuracy > limit {
+		limit = accuracy
+	}
+	if miles <= limit {
+		return nil
+	}
+
+	found := *nearest
+	return &UnfamiliarLocation{
+		NearestMiles:   miles,
+		Nearest:        &found,
+		KnownLocations: len(p.Locations),
+		ProfileLogins:  p.LoginCount,
+	}
+}
+
+// UnusualHour reports the login hour in the local time zone of geo when the
+// profile's history makes it unusual with at least minConfidence. Logins in
+// the hour and the hours either side count toward the hour, and the rule of
+// succession estimates the chance of a login there as (k+1)/(n+2). Nil is
+// returned when the time zone is unknown or the histogram holds fewer than
+// minHistory logins
+func (p *UserProfile) UnusualHour(geo *Geography, millis int64, minHistory int64, minConfidence float64) *UnusualHour {
+	if geo == nil || geo.TimeZone == "" {
+		return nil
+	}
+
+	var n int64
+	for _, count := range p.LocalHours {
+		n += count
+	}
+	if n == 0 || n < minHistory {
+		return nil
+	}
+
+	local := LocalTime(geo, millis)
+	hour := local.Hour()
+	var k int64
+	for offset := -1; offset <= 1; offset++ {
+		k += p.LocalHours[(hour+offset+24)%24]
+	}
+
+	confidence := 1 - float64(k+1)/float64(n+2)
+	if confidence < minConfidence {
+		return nil
+	}
+
+	return &UnusualHour{
+		LocalHour:      hour,
+		TimeZone:       local.Location().String(),
+		Confidence:     confidence,
+		LoginsNearHour: k,
+		HistoryLogins:  n,
+	}
+}
+
+// geography returns the centroid of the location
+func (l ProfileLocation) geography() *Geography {
+	return &Geography{Latitude: l.Latitude, Longitude: l.Longitude, Radius: l.Radius}
+}
+
+// LocalTime returns the instant in the time zone of the location, falling
+// back to UTC when the zone is unknown
+func LocalTime(geo *Geography, millis int64) time.Time {
+	t := millisToTime(millis).UTC()
+	if geo == nil || geo.TimeZone == "" {
+		return t
+	}
+
+	loc, err := time.LoadLocation(geo.TimeZone)
+	if err != nil {
+		return t
+	}
+
+	return t.In(loc)
+}
+
+// Value stores the locations as json
+func (l ProfileLocations) Value() (driver.Value, error) {
+	return jsonValue(l)
+}
+
+// Scan loads the locations from json
+func (l *ProfileLocations) Scan(src interface{}) error {
+	return jsonScan(src, l)
+}
+
+// Value stores the counts as json
+func (c Counts) Value() (driver.Value, error) {
+	return jsonValue(c)
+}
+
+// Scan loads the counts from json
+func (c *Counts) Scan(src interface{}) error {
+	return jsonScan(src, c)
+}
+
+// Value stores the histogram as json
+func (h HourHistogram) Value() (driver.Value, error) {
+	return jsonValue(h)
+}
+
+// Scan loads the histogram from json
+func (h *HourHistogram) Scan(src interface{}) error {
+	return jsonScan(src, h)
+}
+
+// jsonValue encodes a profile column as json text
+func jsonValue(v interface{}) (driver.Value, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// jsonScan decodes a profile column stored as json text
+func jsonScan(src interface{}, v interface{}) error {
+	switch data := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(data, v)
+	case string:
+		return json.Unmarshal([]byte(data), v)
+	default:
+		return fmt.Errorf("cannot scan %T into %T", src, v)
+	}
+}
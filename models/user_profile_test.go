@@ -0,0 +1,164 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserProfileRecord(t *testing.T) {
+	// 2018-01-01T15:00:00Z
+	noonChicago := int64(1514818800000)
+
+	tests := map[string]struct {
+		logins            []*Geography
+		expectedLocations int
+		expectedHour      int
+		expectedCountries Counts
+		expectedASNs      Counts
+	}{
+		"Local Hour From Time Zone": {
+			logins:            []*Geography{{Latitude: 41.85, Longitude: -87.65, Country: "US", TimeZone: "America/Chicago", ASN: 7018}},
+			expectedLocations: 1,
+			expectedHour:      9,
+			expectedCountries: Counts{"US": 1},
+			expectedASNs:      Counts{"7018": 1},
+		},
+		"Unknown Time Zone Uses UTC": {
+			logins:            []*Geography{{Latitude: 41.85, Longitude: -87.65}},
+			expectedLocations: 1,
+			expectedHour:      15,
+			expectedCountries: Counts{},
+			expectedASNs:      Counts{},
+		},
+		"Nearby Logins Clustered": {
+			logins: []*Geography{
+				{Latitude: 41.85, Longitude: -87.65, Country: "US"},
+				{Latitude: 41.95, Longitude: -87.75, Country: "US"},
+				{Latitude: 34.77, Longitude: 113.72, Country: "CN"},
+			},
+			expectedLocations: 2,
+			expectedHour:      15,
+			expectedCountries: Counts{"US": 2, "CN": 1},
+			expectedASNs:      Counts{},
+		},
+		"Accuracy Radius Widens Cluster": {
+			logins: []*Geography{
+				{Latitude: 41.85, Longitude: -87.65},
+				{Latitude: 43.05, Longitude: -87.9, Radius: 200},
+			},
+			expectedLocations: 1,
+			expectedHour:      15,
+			expectedCountries: Counts{},
+			expectedASNs:      Counts{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		profile := NewUserProfile(DefaultTenant, "bob")
+		for _, geo := range test.logins {
+			profile.Record(geo, noonChicago, 50)
+		}
+
+		assert.Equal(t, int64(len(test.logins)), profile.LoginCount)
+		assert.Equal(t, test.expectedLocations, len(profile.Locations))
+		assert.Equal(t, int64(len(test.logins)), profile.LocalHours[test.expectedHour])
+		assert.Equal(t, test.expectedCountries, profile.Countries)
+		assert.Equal(t, test.expectedASNs, profile.ASNs)
+	}
+}
+
+func TestUserProfileColumns(t *testing.T) {
+	profile := NewUserProfile(DefaultTenant, "bob")
+	profile.Record(&Geography{Latitude: 41.85, Longitude: -87.65, Country: "US"}, 1514818800000, 50)
+
+	locations, err := profile.Locations.Value()
+	assert.NoError(t, err)
+	var scannedLocations ProfileLocations
+	assert.NoError(t, scannedLocations.Scan([]byte(locations.(string))))
+	assert.Equal(t, profile.Locations, scannedLocations)
+
+	hours, err := profile.LocalHours.Value()
+	assert.NoError(t, err)
+	var scannedHours HourHistogram
+	assert.NoError(t, scannedHours.Scan(hours))
+	assert.Equal(t, profile.LocalHours, scannedHours)
+
+	var scannedCounts Counts
+	assert.Error(t, scannedCounts.Scan(42))
+}
+
+func TestUserProfileUnusualHour(t *testing.T) {
+	chicago := &Geography{Latitude: 41.85, Longitude: -87.65, TimeZone: "America/Chicago"}
+	// 2018-01-01T15:00:00Z, 09:00 in Chicago
+	morning := int64(1514818800000)
+	hour := int64(3600 * 1000)
+
+	tests := map[string]struct {
+		history            int
+		loginMillis        int64
+		geo                *Geography
+		expectedUnusual    bool
+		expectedLocalHour  int
+		expectedConfidence float64
+	}{
+		"Usual Hour": {
+			history:     30,
+			loginMillis: morning,
+			geo:         chicago,
+		},
+		"Adjacent Hour Usual": {
+			history:     30,
+			loginMillis: morning + hour,
+			geo:         chicago,
+		},
+		"Unusual Hour": {
+			history:            30,
+			loginMillis:        morning + 12*hour,
+			geo:                chicago,
+			expectedUnusual:    true,
+			expectedLocalHour:  21,
+			expectedConfidence: 1 - 1.0/32,
+		},
+		"Unusual In Other Time Zone": {
+			history:            30,
+			loginMillis:        morning,
+			geo:                &Geography{TimeZone: "Asia/Shanghai"},
+			expectedUnusual:    true,
+			expectedLocalHour:  23,
+			expectedConfidence: 1 - 1.0/32,
+		},
+		"Too Little History": {
+			history:     10,
+			loginMillis: morning + 12*hour,
+			geo:         chicago,
+		},
+		"Unknown Time Zone": {
+			history:     30,
+			loginMillis: morning + 12*hour,
+			geo:         &Geography{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		profile := NewUserProfile(DefaultTenant, "bob")
+		for i := 0; i < test.history; i++ {
+			profile.Record(chicago, morning+int64(i)*24*hour, 50)
+		}
+
+		unusual := profile.UnusualHour(test.geo, test.loginMillis, 20, 0.95)
+		if !test.expectedUnusual {
+			assert.Nil(t, unusual)
+			continue
+		}
+
+		if assert.NotNil(t, unusual) {
+			assert.Equal(t, test.expectedLocalHour, unusual.LocalHour)
+			assert.Equal(t, test.geo.TimeZone, unusual.TimeZone)
+			assert.InDelta(t, test.expectedConfidence, unusual.Confidence, 1e-9)
+			assert.Equal(t, int64(test.history), unusual.HistoryLogins)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/txross1993/superman-api/errors"
+)
+
+// uuidPattern matches the canonical 8-4-4-4-12 hexadecimal uuid form
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ValidationPolicy bounds the values accepted for user ip access events.
+// Timestamp skews are measured against the server clock; a zero
+// MaxPastSkew accepts events arbitrarily far in the past so history may
+// be backfilled
+type ValidationPolicy struct {
+	MaxUsernameLength int
+	MaxPastSkew       time.Duration
+	MaxFutureSkew     time.Duration
+}
+
+// DefaultValidationPolicy returns the policy applied unless configured
+func DefaultValidationPolicy() ValidationPolicy {
+	return ValidationPolicy{
+		MaxUsernameLength: 256,
+		MaxFutureSkew:     5 * time.Minute,
+	}
+}
+
+// Validate checks every field of the event against the policy, measuring
+// timestamp skews from now, and reports all violations together
+func (p ValidationPolicy) Validate(u *UserIPAccessEvent, now time.Time) error {
+	var violations errors.Validation
+
+	switch {
+	case u.EventUUID == "":
+		violations.Add(&errors.MissingField{Name: "event_uuid"})
+	case !uuidPattern.MatchString(u.EventUUID):
+		violations.Add(&errors.InvalidField{Name: "event_uuid", Reason: "must be a uuid"})
+	}
+
+	switch {
+	case strings.TrimSpace(u.Username) == "":
+		violations.Add(&errors.MissingField{Name: "username"})
+	case !utf8.ValidString(u.Username):
+		violations.Add(&errors.InvalidField{Name: "username", Reason: "must be valid utf-8"})
+	case p.MaxUsernameLength > 0 && utf8.RuneCountInString(u.Username) > p.MaxUsernameLength:
+		violations.Add(&errors.InvalidField{Name: "username", Reason: "must be at most " + strconv.Itoa(p.MaxUsernameLength) + " characters"})
+	case strings.IndexFunc(u.Username, unicode.IsControl) >= 0:
+		violations.Add(&errors.InvalidField{Name: "username", Reason: "must not contain control characters"})
+	}
+
+	if err := p.validateTimestamp(u.Millis(), now); err != nil {
+		violations.Add(err)
+	}
+
+	switch {
+	case u.IPAddress == "":
+		violations.Add(&errors.MissingField{Name: "ip_address"})
+	case net.ParseIP(u.IPAddress) == nil:
+		violations.Add(&errors.InvalidIP{IP: u.IPAddress})
+	}
+
+	return violations.ErrorOrNil()
+}
+
+// validateTimestamp checks the epoch millisecond timestamp is set and within
+// the accepted skew of now
+func (p ValidationPolicy) validateTimestamp(ms int64, now time.Time) errors.FieldError {
+	if ms == 0 {
+		return &errors.MissingField{Name: "unix_timestamp"}
+	}
+
+	eventTime := millisToTime(ms)
+	value := eventTime.UTC().Format(time.RFC3339Nano)
+	if ms < 0 {
+		return &errors.InvalidTimestamp{Value: value, Reason: "must be after the unix epoch"}
+	}
+
+	if p.MaxFutureSkew >= 0 && eventTime.After(now.Add(p.MaxFutureSkew)) {
+		return &errors.InvalidTimestamp{Value: value, Reason: "more than " + p.MaxFutureSkew.String() + " in the future"}
+	}
+
+	if p.MaxPastSkew > 0 && eventTime.Before(now.Add(-p.MaxPastSkew)) {
+		return &errors.InvalidTimestamp{Value: value, Reason: "more than " + p.MaxPastSkew.String() + " in the past"}
+	}
+
+	return nil
+}
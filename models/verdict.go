@@ -0,0 +1,92 @@
+package models
+
+// Reason names a signal which made a login event suspicious
+type Reason string
+
+const (
+	// ReasonTravelTo indicates impossible travel from the preceding login
+	ReasonTravelTo Reason = "travel_to"
+	// ReasonTravelFrom indicates impossible travel to the subsequent login
+	ReasonTravelFrom Reason = "travel_from"
+	// ReasonSuspiciousPath indicates impossible travel between logins in
+	// the surrounding window
+	ReasonSuspiciousPath Reason = "suspicious_path"
+	// ReasonConcurrentSessions indicates logins alternating between distinct
+	// locations
+	ReasonConcurrentSessions Reason = "concurrent_sessions"
+	// ReasonUnfamiliarLocation indicates a login far from every location in
+	// the user's profile
+	ReasonUnfamiliarLocation Reason = "unfamiliar_location"
+	// ReasonUnusualHour indicates a login at a local hour the user rarely
+	// logs in at
+	ReasonUnusualHour Reason = "unusual_hour"
+)
+
+// reasonWeights holds how strongly each signal alone indicates an account
+// takeover
+var reasonWeights = map[Reason]float64{
+	ReasonTravelTo:           0.6,
+	ReasonTravelFrom:         0.6,
+	ReasonSuspiciousPath:     0.5,
+	ReasonConcurrentSessions: 0.7,
+	ReasonUnfamiliarLocation: 0.4,
+	ReasonUnusualHour:        0.3,
+}
+
+// Score combines the weights of the reasons into a score between 0 and 1,
+// treating each signal as independent evidence: the score is the chance
+// that at least one signal is right
+func Score(reasons []Reason) float64 {
+	clear := 1.0
+	for _, reason := range reasons {
+		clear *= 1 - reasonWeights[reason]
+	}
+	return 1 - clear
+}
+
+// Verdict is the outcome of analyzing a login event. Analyzing one event
+// may also change the verdict on a neighboring event which was received
+// earlier; such a re-evaluated verdict names the event which triggered it
+type Verdict struct {
+	EventUUID        string    `json:"eventUuid"`
+	Tenant           string    `json:"tenant,omitempty"`
+	Username         string    `json:"username"`
+	IP               string    `json:"ip"`
+	TimestampMillis  int64     `json:"timestampMillis"`
+	Suspicious       bool      `json:"suspicious"`
+	Score            float64   `json:"score"`
+	Reasons          []Reason  `json:"reasons,omitempty"`
+	TriggerEventUUID string    `json:"triggerEventUuid,omitempty"`
+	Analysis         *Superman `json:"analysis,omitempty"`
+}
+
+// Reevaluated reports whether the verdict is on an event other than the one
+// whose analysis produced it
+func (v *Verdict) Reevaluated() bool {
+	return v.TriggerEventUUID != "" && v.TriggerEventUUID != v.EventUUID
+}
+
+// Reasons lists the signals in the response which make the current login
+// suspicious
+func (s *Superman) Reasons() []Reason {
+	var reasons []Reason
+	if s.TravelToSuspicious {
+		reasons = append(reasons, ReasonTravelTo)
+	}
+	if s.TravelFromSuspicious {
+		reasons = append(reasons, ReasonTravelFrom)
+	}
+	if len(s.SuspiciousPaths) > 0 {
+		reasons = append(reasons, ReasonSuspiciousPath)
+	}
+	if s.ConcurrentSessions != nil {
+		reasons = append(reasons, ReasonConcurrentSessions)
+	}
+	if s.UnfamiliarLocation != nil {
+		reasons = append(reasons, ReasonUnfamiliarLocation)
+	}
+	if s.UnusualHour != nil {
+		reasons = append(reasons, ReasonUnusualHour)
+	}
+	return reasons
+}
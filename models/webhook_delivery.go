@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// WebhookDelivery is an outbox record of a notification owed to a webhook
+// destination. Records are kept after delivery so that the same verdict is
+// never sent to a destination twice, and are marked dead once every
+// attempt has failed. Dead deliveries are exposed only to the tenant of
+// their verdict
+type WebhookDelivery struct {
+	ID            uint       `json:"id" gorm:"primary_key"`
+	Destination   string     `json:"destination" gorm:"not null;unique_index:idx_webhook_delivery_dedupe"`
+	DedupeKey     string     `json:"dedupeKey" gorm:"not null;unique_index:idx_webhook_delivery_dedupe"`
+	EventUUID     string     `json:"eventUuid" gorm:"not null"`
+	Tenant        string     `json:"tenant,omitempty" gorm:"not null;default:''" sql:"index"`
+	Payload       string     `json:"payload" gorm:"type:text;not null"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt" sql:"index"`
+	LastError     string     `json:"lastError,omitempty"`
+	DeliveredAt   *time.Time `json:"deliveredAt,omitempty"`
+	DeadAt        *time.Time `json:"deadAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// Pending reports whether the delivery is still to be attempted
+func (d *WebhookDelivery) Pending() bool {
+	return d.DeliveredAt == nil && d.DeadAt == nil
+}
@@ -0,0 +1,336 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/txross1993/superman-api/logging"
+	"github.com/txross1993/superman-api/models"
+)
+
+var metrics = expvar.NewMap("webhooks")
+
+const (
+	// TypeSuspiciousLogin is the payload type of a verdict on the login
+	// event just analyzed
+	TypeSuspiciousLogin = "login.suspicious"
+	// TypeReevaluatedLogin is the payload type of a verdict on a neighboring
+	// login event which changed when another event was analyzed
+	TypeReevaluatedLogin = "login.reevaluated"
+)
+
+type outbox interface {
+	EnqueueDelivery(context.Context, *models.WebhookDelivery) error
+	DueDeliveries(context.Context, time.Time, int) ([]models.WebhookDelivery, error)
+	SaveDelivery(context.Context, *models.WebhookDelivery) error
+}
+
+// Destination is a webhook url and the secret used to sign requests to it
+type Destination struct {
+	URL    string
+	Secret string
+}
+
+// Backoff configures retries of failed deliveries. The delay before each
+// retry doubles from Initial up to Max, and a delivery is dead lettered
+// after MaxAttempts failures
+type Backoff struct {
+	Initial     time.Duration
+	Max         time.Duration
+	MaxAttempts int
+}
+
+// DefaultBackoff returns the retry schedule applied unless configured,
+// which retries for about an hour
+func DefaultBackoff() Backoff {
+	return Backoff{
+		Initial:     time.Second,
+		Max:         10 * time.Minute,
+		MaxAttempts: 15,
+	}
+}
+
+// delay returns the wait before the attempt following the provided number
+// of failed attempts
+func (b Backoff) delay(failures int) time.Duration {
+	d := b.Initial
+	for i := 1; i < failures && d < b.Max; i++ {
+		d *= 2
+	}
+	if d > b.Max {
+		d = b.Max
+	}
+	return d
+}
+
+// Payload is the json body posted to webhook destinations
+type Payload struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Verdict   *models.Verdict `json:"verdict"`
+}
+
+// Dispatcher records suspicious verdicts in a durable outbox and delivers
+// them to every webhook destination, retrying failures with exponential
+// backoff
+type Dispatcher struct {
+	store        outbox
+	destinations map[string]Destination
+	order        []string
+	client       *http.Client
+	backoff      Backoff
+	interval     time.Duration
+	batch        int
+	now          func() time.Time
+	wake         chan struct{}
+}
+
+// Option represents a functional option for configuring a Dispatcher
+type Option func(d *Dispatcher)
+
+// WithHTTPClient provides the functional option for the client which posts
+// to destinations
+func WithHTTPClient(client *http.Client) Option {
+	return func(d *Dispatcher) {
+		d.client = client
+	}
+}
+
+// WithBackoff provides the functional option for the retry schedule
+func WithBackoff(b Backoff) Option {
+	return func(d *Dispatcher) {
+		d.backoff = b
+	}
+}
+
+// WithPollInterval provides the functional option for how often the outbox
+// is checked for deliveries due a retry
+func WithPollInterval(interval time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.interval = interval
+	}
+}
+
+// WithClock provides the functional option for the source of the current
+// time
+func WithClock(now func() time.Time) Option {
+	return func(d *Dispatcher) {
+		d.now = now
+	}
+}
+
+// NewDispatcher creates a dispatcher which delivers to the destinations
+// through the outbox
+func NewDispatcher(store outbox, destinations []Destination, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		store:        store,
+		destinations: map[string]Destination{},
+		client:       &http.Client{Timeout: 10 * time.Second},
+		backoff:      DefaultBackoff(),
+		interval:     time.Second,
+		batch:        100,
+		now:          time.Now,
+		wake:         make(chan struct{}, 1),
+	}
+	for _, dest := range destinations {
+		if _, ok := d.destinations[dest.URL]; !ok {
+			d.order = append(d.order, dest.URL)
+		}
+		d.destinations[dest.URL] = dest
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Notify records each suspicious verdict in the outbox once per destination
+// and wakes the delivery loop. Verdicts already recorded are skipped, so an
+// event analyzed again does not alert twice
+func (d *Dispatcher) Notify(ctx context.Context, verdicts []*models.Verdict) error {
+	now := d.now().UTC()
+	for _, verdict := range verdicts {
+		if !verdict.Suspicious {
+			continue
+		}
+
+		payload := Payload{
+			ID:        dedupeKey(verdict),
+			Type:      TypeSuspiciousLogin,
+			CreatedAt: now,
+			Verdict:   verdict,
+		}
+		if verdict.Reevaluated() {
+			payload.Type = TypeReevaluatedLogin
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+
+		for _, url := range d.order {
+			delivery := &models.WebhookDelivery{
+				Destination:   url,
+				DedupeKey:     payload.ID,
+				EventUUID:     verdict.EventUUID,
+				Tenant:        verdict.Tenant,
+				Payload:       string(body),
+				NextAttemptAt: now,
+			}
+			if err := d.store.EnqueueDelivery(ctx, delivery); err != nil {
+				return err
+			}
+		}
+	}
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run delivers due notifications whenever verdicts are recorded and on every
+// poll interval until stop is closed
+func (d *Dispatcher) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	for {
+		// deliveries interrupted by stopping are left due
+		if _, err := d.DeliverDue(ctx); err != nil && ctx.Err() == nil {
+			logging.Default().Error("webhook delivery", "error", err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// DeliverDue attempts every delivery whose next attempt is due, returning
+// the number delivered. Batches are fetched while the last was full and made
+// progress
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	delivered := 0
+	for {
+		due, err := d.store.DueDeliveries(ctx, d.now(), d.batch)
+		if err != nil {
+			return delivered, err
+		}
+
+		batchDelivered := 0
+		for i := range due {
+			ok, err := d.attempt(ctx, &due[i])
+			if err != nil {
+				return delivered, err
+			}
+			if ok {
+				batchDelivered++
+			}
+		}
+		delivered += batchDelivered
+
+		if len(due) < d.batch || batchDelivered == 0 {
+			return delivered, nil
+		}
+	}
+}
+
+// attempt posts the delivery to its destination and records the outcome,
+// scheduling a retry or dead lettering the delivery on failure. A post
+// interrupted by the context is not counted as an attempt
+func (d *Dispatcher) attempt(ctx context.Context, delivery *models.WebhookDelivery) (bool, error) {
+	err := d.post(ctx, delivery)
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	now := d.now().UTC()
+	delivery.Attempts++
+
+	if err == nil {
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		metrics.Add("delivered", 1)
+		return true, d.store.SaveDelivery(ctx, delivery)
+	}
+
+	delivery.LastError = err.Error()
+	metrics.Add("failed_attempts", 1)
+	if delivery.Attempts >= d.backoff.MaxAttempts {
+		delivery.DeadAt = &now
+		metrics.Add("dead_lettered", 1)
+	} else {
+		delivery.NextAttemptAt = now.Add(d.backoff.delay(delivery.Attempts))
+	}
+
+	return false, d.store.SaveDelivery(ctx, delivery)
+}
+
+// post sends the signed payload to the destination, treating any status
+// other than 2xx as a failure. The request is abandoned once the context is
+// done
+func (d *Dispatcher) post(ctx context.Context, delivery *models.WebhookDelivery) error {
+	dest, ok := d.destinations[delivery.Destination]
+	if !ok {
+		return fmt.Errorf("destination %s is no longer configured", delivery.Destination)
+	}
+
+	body := []byte(delivery.Payload)
+	timestamp := d.now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dest.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDelivery, delivery.DedupeKey)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(dest.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("destination responded %s", resp.Status)
+	}
+	return nil
+}
+
+// dedupeKey identifies a verdict on an event by the event whose analysis
+// produced it. Event uuids are unique only within a tenant, so the keys of
+// tenants other than the default carry the tenant
+func dedupeKey(verdict *models.Verdict) string {
+	key := verdict.EventUUID
+	if verdict.Reevaluated() {
+		key += "/" + verdict.TriggerEventUUID
+	}
+	if verdict.Tenant != models.DefaultTenant {
+		key = verdict.Tenant + ":" + key
+	}
+	return key
+}
@@ -0,0 +1,333 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/txross1993/superman-api/db"
+	"github.com/txross1993/superman-api/models"
+)
+
+const testSecret = "shh"
+
+func TestDispatcher(t *testing.T) {
+	tests := map[string]struct {
+		failures          int
+		maxAttempts       int
+		verdicts          []*models.Verdict
+		expectedRequests  int
+		expectedDelivered int
+		expectedDead      int
+	}{
+		"Delivered First Attempt": {
+			maxAttempts:       3,
+			verdicts:          []*models.Verdict{suspicious("a", "")},
+			expectedRequests:  1,
+			expectedDelivered: 1,
+		},
+		"Not Suspicious Skipped": {
+			maxAttempts: 3,
+			verdicts:    []*models.Verdict{{EventUUID: "a"}},
+		},
+		"Duplicate Verdicts Sent Once": {
+			maxAttempts:       3,
+			verdicts:          []*models.Verdict{suspicious("a", ""), suspicious("a", "")},
+			expectedRequests:  1,
+			expectedDelivered: 1,
+		},
+		"Same UUID In Another Tenant Sent Separately": {
+			maxAttempts:       3,
+			verdicts:          []*models.Verdict{suspicious("a", ""), inTenant("acme", suspicious("a", ""))},
+			expectedRequests:  2,
+			expectedDelivered: 2,
+		},
+		"Reevaluated Neighbor Sent Separately": {
+			maxAttempts:       3,
+			verdicts:          []*models.Verdict{suspicious("a", ""), suspicious("b", "a")},
+			expectedRequests:  2,
+			expectedDelivered: 2,
+		},
+		"Delivered After Retries": {
+			failures:          2,
+			maxAttempts:       3,
+			verdicts:          []*models.Verdict{suspicious("a", "")},
+			expectedRequests:  3,
+			expectedDelivered: 1,
+		},
+		"Dead Lettered": {
+			failures:         5,
+			maxAttempts:      3,
+			verdicts:         []*models.Verdict{suspicious("a", "")},
+			expectedRequests: 3,
+			expectedDead:     1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		store, cleanup := newTestDB(t)
+		receiver := newReceiver(test.failures)
+		server := httptest.NewServer(receiver)
+
+		clock := &testClock{now: time.Unix(1514764800, 0)}
+		dispatcher := NewDispatcher(store, []Destination{{URL: server.URL, Secret: testSecret}},
+			WithClock(clock.Now),
+			WithBackoff(Backoff{Initial: time.Second, Max: time.Minute, MaxAttempts: test.maxAttempts}),
+		)
+
+		if err := dispatcher.Notify(context.Background(), test.verdicts); err != nil {
+			t.Fatal(err)
+		}
+
+		delivered := 0
+		for i := 0; i < test.maxAttempts+1; i++ {
+			n, err := dispatcher.DeliverDue(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			delivered += n
+			clock.Advance(time.Minute)
+		}
+
+		dead, err := store.ListDeadDeliveries(models.DefaultTenant)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, test.expectedRequests, receiver.count())
+		assert.Equal(t, test.expectedDelivered, delivered)
+		assert.Equal(t, test.expectedDead, len(dead))
+		assert.Equal(t, 0, receiver.badSignatures)
+
+		server.Close()
+		cleanup()
+	}
+}
+
+func TestDispatcherBackoff(t *testing.T) {
+	store, cleanup := newTestDB(t)
+	defer cleanup()
+
+	receiver := newReceiver(10)
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	clock := &testClock{now: time.Unix(1514764800, 0)}
+	dispatcher := NewDispatcher(store, []Destination{{URL: server.URL, Secret: testSecret}},
+		WithClock(clock.Now),
+		WithBackoff(Backoff{Initial: time.Second, Max: 4 * time.Second, MaxAttempts: 10}),
+	)
+	if err := dispatcher.Notify(context.Background(), []*models.Verdict{suspicious("a", "")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// retries come due after 1s, 2s, 4s, then the 4s maximum
+	for _, wait := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		before := receiver.count()
+		dispatcher.DeliverDue(context.Background())
+		assert.Equal(t, before+1, receiver.count())
+
+		clock.Advance(wait - time.Millisecond)
+		dispatcher.DeliverDue(context.Background())
+		assert.Equal(t, before+1, receiver.count())
+		clock.Advance(time.Millisecond)
+	}
+}
+
+func TestDispatcherSurvivesRestart(t *testing.T) {
+	dbFile, cleanup := newTestDBFile(t)
+	defer cleanup()
+
+	receiver := newReceiver(0)
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+	destinations := []Destination{{URL: server.URL, Secret: testSecret}}
+
+	store, err := db.InitDB(dbFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewDispatcher(store, destinations).Notify(context.Background(), []*models.Verdict{suspicious("a", "")}); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	restarted, err := db.InitDB(dbFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.Close()
+
+	delivered, err := NewDispatcher(restarted, destinations).DeliverDue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, 1, receiver.count())
+
+	var payload Payload
+	if assert.NoError(t, json.Unmarshal(receiver.bodies[0], &payload)) {
+		assert.Equal(t, "a", payload.ID)
+		assert.Equal(t, TypeSuspiciousLogin, payload.Type)
+		assert.Equal(t, "a", payload.Verdict.EventUUID)
+	}
+}
+
+// TestDispatcherStopCancelsPost tests that stopping the dispatcher abandons
+// a post in flight rather than waiting out the client timeout, leaving the
+// delivery due without counting the attempt
+func TestDispatcherStopCancelsPost(t *testing.T) {
+	store, cleanup := newTestDB(t)
+	defer cleanup()
+
+	received := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		received <- struct{}{}
+		select {
+		case <-req.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	dispatcher := NewDispatcher(store, []Destination{{URL: server.URL, Secret: testSecret}}, WithPollInterval(time.Hour))
+	if err := dispatcher.Notify(context.Background(), []*models.Verdict{suspicious("a", "")}); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		dispatcher.Run(stop)
+		close(done)
+	}()
+
+	<-received
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("dispatcher waited on the post in flight")
+	}
+
+	due, err := store.DueDeliveries(context.Background(), time.Now(), 10)
+	if assert.NoError(t, err) && assert.Equal(t, 1, len(due)) {
+		assert.Equal(t, 0, due[0].Attempts)
+	}
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":"a"}`)
+	signature := Sign(testSecret, 1514764800, body)
+
+	assert.True(t, Verify(testSecret, 1514764800, body, signature))
+	assert.False(t, Verify("other", 1514764800, body, signature))
+	assert.False(t, Verify(testSecret, 1514764801, body, signature))
+	assert.False(t, Verify(testSecret, 1514764800, []byte(`{"id":"b"}`), signature))
+}
+
+// suspicious creates a suspicious verdict on the event, re-evaluated by the
+// trigger event if provided
+func suspicious(uuid, trigger string) *models.Verdict {
+	return &models.Verdict{
+		EventUUID:        uuid,
+		Username:         "bob",
+		Suspicious:       true,
+		Reasons:          []models.Reason{models.ReasonTravelTo},
+		TriggerEventUUID: trigger,
+	}
+}
+
+func inTenant(tenant string, verdict *models.Verdict) *models.Verdict {
+	verdict.Tenant = tenant
+	return verdict
+}
+
+// newTestDB opens an outbox in a temporary database, returning a func
+// closing the database and removing it
+func newTestDB(t *testing.T) (db.DB, func()) {
+	t.Helper()
+	dbFile, remove := newTestDBFile(t)
+	store, err := db.InitDB(dbFile)
+	if err != nil {
+		remove()
+		t.Fatal(err)
+	}
+	return store, func() {
+		store.Close()
+		remove()
+	}
+}
+
+// newTestDBFile returns the path of a database file in a temporary
+// directory, and a func removing the directory
+func newTestDBFile(t *testing.T) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "superman-notify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path.Join(dir, "test.db"), func() { os.RemoveAll(dir) }
+}
+
+// receiver is a webhook destination which fails its first requests and
+// checks the signature of every request
+type receiver struct {
+	mu            sync.Mutex
+	failures      int
+	bodies        [][]byte
+	badSignatures int
+}
+
+func newReceiver(failures int) *receiver {
+	return &receiver{failures: failures}
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body, _ := ioutil.ReadAll(req.Body)
+	timestamp, _ := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	if !Verify(testSecret, timestamp, body, req.Header.Get(HeaderSignature)) {
+		r.badSignatures++
+	}
+	r.bodies = append(r.bodies, body)
+
+	if len(r.bodies) <= r.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.bodies)
+}
+
+// testClock is a manually advanced source of the current time
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+const (
+	// HeaderSignature carries the hmac signature of the request
+	HeaderSignature = "X-Superman-Signature"
+	// HeaderTimestamp carries the unix time at which the request was signed
+	HeaderTimestamp = "X-Superman-Timestamp"
+	// HeaderDelivery carries the id of the notification, which is the same
+	// across retries so receivers can discard duplicates
+	HeaderDelivery = "X-Superman-Delivery"
+)
+
+// signaturePrefix names the hash used for the signature
+const signaturePrefix = "sha256="
+
+// Sign returns the signature of the request body sent at the unix timestamp.
+// The signature is the hex encoded HMAC-SHA256 of the timestamp, a period,
+// and the body, keyed by the destination secret. Covering the timestamp lets
+// receivers reject replayed requests
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature matches the request body sent at the
+// unix timestamp
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,144 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/txross1993/superman-api/auth"
+	"github.com/txross1993/superman-api/errors"
+	"github.com/txross1993/superman-api/models"
+)
+
+type keystore interface {
+	FindAPIKeyByHash(string) (*models.APIKey, error)
+}
+
+// methodScopes holds the scope an api key needs to call each method
+var methodScopes = map[string]models.Scope{
+	"/superman.v1.SupermanService/AnalyzeEvent":   models.ScopeIngest,
+	"/superman.v1.SupermanService/AnalyzeEvents":  models.ScopeIngest,
+	"/superman.v1.SupermanService/GetUserHistory": models.ScopeRead,
+}
+
+// tenantMetadata selects the tenant a call acts for when its api key is
+// granted the cross tenant scope
+const tenantMetadata = "x-tenant-id"
+
+// clientKey is the context key holding the authenticated api key
+type clientKey struct{}
+
+// tenantKey is the context key holding the tenant the call acts for
+type tenantKey struct{}
+
+// client returns the authenticated api key for the call if any
+func client(ctx context.Context) *models.APIKey {
+	key, _ := ctx.Value(clientKey{}).(*models.APIKey)
+	return key
+}
+
+// tenant returns the tenant the call acts for
+func tenant(ctx context.Context) string {
+	t, _ := ctx.Value(tenantKey{}).(string)
+	return t
+}
+
+// authenticate rejects calls which do not present an active api key
+// granting the scope of the method, returning the context carrying the key
+// and the tenant the call acts for. Authentication is disabled when the
+// server is configured without a keystore
+func (s *Server) authenticate(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var key *models.APIKey
+	if s.keys != nil {
+		scope, ok := methodScopes[method]
+		if !ok {
+			scope = models.ScopeAdmin
+		}
+
+		plaintext := requestKey(md)
+		if plaintext == "" {
+			return nil, codeError(errors.CodeUnauthorized, "missing api key")
+		}
+
+		var err error
+		key, err = s.keys.FindAPIKeyByHash(auth.Hash(plaintext))
+		if err != nil {
+			return nil, statusError(&errors.StorageUnavailable{Op: "find api key", Err: err})
+		}
+
+		if key == nil || !key.Active(time.Now()) {
+			return nil, codeError(errors.CodeUnauthorized, "invalid api key")
+		}
+
+		if !key.HasScope(scope) {
+			return nil, codeError(errors.CodeForbidden, "api key lacks the "+string(scope)+" scope")
+		}
+
+		ctx = context.WithValue(ctx, clientKey{}, key)
+	}
+
+	var requested string
+	if values := md.Get(tenantMetadata); len(values) > 0 {
+		requested = strings.TrimSpace(values[0])
+	}
+	if !models.ValidTenant(requested) {
+		return nil, codeError(errors.CodeInvalidField, "invalid "+tenantMetadata+" metadata")
+	}
+
+	t, ok := key.TenantFor(requested)
+	if !ok {
+		return nil, codeError(errors.CodeForbidden, "api key may not act for tenant "+requested)
+	}
+
+	return context.WithValue(ctx, tenantKey{}, t), nil
+}
+
+// requestKey extracts the plaintext api key from either the authorization
+// bearer token or the x-api-key metadata
+func requestKey(md metadata.MD) string {
+	for _, header := range md.Get("authorization") {
+		const bearer = "bearer "
+		if len(header) > len(bearer) && strings.ToLower(header[:len(bearer)]) == bearer {
+			return strings.TrimSpace(header[len(bearer):])
+		}
+	}
+
+	if keys := md.Get("x-api-key"); len(keys) > 0 {
+		return strings.TrimSpace(keys[0])
+	}
+	return ""
+}
+
+// unaryAuth authenticates unary calls
+func (s *Server) unaryAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// streamAuth authenticates streaming calls
+func (s *Server) streamAuth(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticatedStream is a server stream whose context carries the
+// authenticated api key or the call's logger
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,188 @@
+package rpc
+
+import (
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/rpc/supermanpb"
+)
+
+// eventFromProto translates the protobuf event to the model. unix_millis
+// takes precedence over unix_timestamp as millis does over seconds in the
+// http api
+func eventFromProto(in *supermanpb.UserIPAccessEvent) *models.UserIPAccessEvent {
+	event := &models.UserIPAccessEvent{
+		EventUUID: in.GetEventUuid(),
+		Username:  in.GetUsername(),
+		IPAddress: in.GetIpAddress(),
+	}
+
+	millis := in.GetUnixMillis()
+	if millis == 0 {
+		millis = in.GetUnixTimestamp() * 1000
+	}
+	event.SetMillis(millis)
+
+	return event
+}
+
+// eventToProto translates a stored event to its protobuf message
+func eventToProto(event *models.UserIPAccessEvent) *supermanpb.UserIPAccessEvent {
+	return &supermanpb.UserIPAccessEvent{
+		EventUuid:     event.EventUUID,
+		Username:      event.Username,
+		UnixTimestamp: event.UnixTimestamp,
+		UnixMillis:    event.Millis(),
+		IpAddress:     event.IPAddress,
+	}
+}
+
+// supermanToProto translates the analysis of an event to its protobuf
+// message
+func supermanToProto(s *models.Superman) *supermanpb.Superman {
+	if s == nil {
+		return nil
+	}
+
+	out := &supermanpb.Superman{
+		CurrentGeo:                     geographyToProto(s.CurrentGeo),
+		TravelToCurrentGeoSuspicious:   s.TravelToSuspicious,
+		TravelFromCurrentGeoSuspicious: s.TravelFromSuspicious,
+		PrecedingIpAccess:              ipAccessToProto(s.PrecedingIPAccess),
+		SubsequentIpAccess:             ipAccessToProto(s.SubsequentIPAccess),
+		ConcurrentSessions:             concurrentSessionsToProto(s.ConcurrentSessions),
+		UnfamiliarLocation:             unfamiliarLocationToProto(s.UnfamiliarLocation),
+		UnusualHour:                    unusualHourToProto(s.UnusualHour),
+	}
+	for _, path := range s.SuspiciousPaths {
+		out.SuspiciousPaths = append(out.SuspiciousPaths, travelPathToProto(path))
+	}
+
+	return out
+}
+
+func geographyToProto(geo *models.Geography) *supermanpb.Geography {
+	if geo == nil {
+		return nil
+	}
+
+	return &supermanpb.Geography{
+		Lat:      geo.Latitude,
+		Lon:      geo.Longitude,
+		Radius:   uint32(geo.Radius),
+		Country:  geo.Country,
+		TimeZone: geo.TimeZone,
+		Asn:      uint32(geo.ASN),
+	}
+}
+
+func ipAccessToProto(access *models.IPAccess) *supermanpb.IPAccess {
+	if access == nil {
+		return nil
+	}
+
+	return &supermanpb.IPAccess{
+		Geography:       geographyToProto(access.Geography),
+		Ip:              access.IP,
+		Speed:           access.Speed,
+		Distance:        access.Distance,
+		Assessment:      string(access.Assessment),
+		Timestamp:       access.Timestamp,
+		TimestampMillis: access.TimestampMillis,
+	}
+}
+
+func travelPathToProto(path *models.TravelPath) *supermanpb.TravelPath {
+	out := &supermanpb.TravelPath{
+		Speed:      path.Speed,
+		Distance:   path.Distance,
+		Assessment: string(path.Assessment),
+	}
+	for _, hop := range path.Hops {
+		out.Hops = append(out.Hops, ipAccessToProto(hop))
+	}
+
+	return out
+}
+
+func concurrentSessionsToProto(sessions *models.ConcurrentSessions) *supermanpb.ConcurrentSessions {
+	if sessions == nil {
+		return nil
+	}
+
+	out := &supermanpb.ConcurrentSessions{
+		Alternations:         int32(sessions.Alternations),
+		FirstTimestampMillis: sessions.FirstTimestampMillis,
+		LastTimestampMillis:  sessions.LastTimestampMillis,
+	}
+	for _, geo := range sessions.Locations {
+		out.Locations = append(out.Locations, geographyToProto(geo))
+	}
+	for _, event := range sessions.Events {
+		out.Events = append(out.Events, ipAccessToProto(event))
+	}
+
+	return out
+}
+
+func profileLocationToProto(location *models.ProfileLocation) *supermanpb.ProfileLocation {
+	if location == nil {
+		return nil
+	}
+
+	return &supermanpb.ProfileLocation{
+		Lat:            location.Latitude,
+		Lon:            location.Longitude,
+		Radius:         uint32(location.Radius),
+		Country:        location.Country,
+		Count:          location.Count,
+		LastSeenMillis: location.LastSeenMillis,
+	}
+}
+
+func unfamiliarLocationToProto(unfamiliar *models.UnfamiliarLocation) *supermanpb.UnfamiliarLocation {
+	if unfamiliar == nil {
+		return nil
+	}
+
+	return &supermanpb.UnfamiliarLocation{
+		NearestMiles:   unfamiliar.NearestMiles,
+		Nearest:        profileLocationToProto(unfamiliar.Nearest),
+		KnownLocations: int32(unfamiliar.KnownLocations),
+		ProfileLogins:  unfamiliar.ProfileLogins,
+	}
+}
+
+func unusualHourToProto(unusual *models.UnusualHour) *supermanpb.UnusualHour {
+	if unusual == nil {
+		return nil
+	}
+
+	return &supermanpb.UnusualHour{
+		LocalHour:      int32(unusual.LocalHour),
+		TimeZone:       unusual.TimeZone,
+		Confidence:     unusual.Confidence,
+		LoginsNearHour: unusual.LoginsNearHour,
+		HistoryLogins:  unusual.HistoryLogins,
+	}
+}
+
+// profileToProto translates a user profile to its protobuf message
+func profileToProto(profile *models.UserProfile) *supermanpb.UserProfile {
+	if profile == nil {
+		return nil
+	}
+
+	out := &supermanpb.UserProfile{
+		Username:        profile.Username,
+		LoginCount:      profile.LoginCount,
+		FirstSeenMillis: profile.FirstSeenMillis,
+		LastSeenMillis:  profile.LastSeenMillis,
+		Countries:       map[string]int64(profile.Countries),
+		Asns:            map[string]int64(profile.ASNs),
+		LocalHours:      profile.LocalHours[:],
+	}
+	for i := range profile.Locations {
+		out.Locations = append(out.Locations, profileLocationToProto(&profile.Locations[i]))
+	}
+
+	return out
+}
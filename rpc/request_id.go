@@ -0,0 +1,113 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/txross1993/superman-api/logging"
+	"github.com/txross1993/superman-api/tracing"
+)
+
+// requestIDMetadata carries the request id in both directions, as the
+// X-Request-ID header does for the http api
+const requestIDMetadata = "x-request-id"
+
+// startCall assigns the call an id, accepting a well formed caller provided
+// x-request-id or generating one, and starts its span continuing any trace
+// context sent by the caller. The returned context carries a logger adding
+// the id, and the trace id when sampled, to every line
+func (s *Server) startCall(ctx context.Context, method string) (context.Context, trace.Span, string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var id string
+	if values := md.Get(requestIDMetadata); len(values) > 0 {
+		id = values[0]
+	}
+	if !logging.ValidRequestID(id) {
+		id = logging.NewRequestID()
+	}
+
+	ctx, span := tracing.StartServer(ctx, metadataCarrier(md), method,
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.method", method),
+		attribute.String("rpc.request_id", id),
+	)
+
+	logger := s.logger.With("request_id", id)
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		logger = logger.With("trace_id", traceID)
+	}
+	return logging.NewContext(ctx, logger), span, id
+}
+
+// endCall ends the span of a finished call and logs it with the logger of
+// its context. Server side failures are logged at LevelError
+func endCall(ctx context.Context, span trace.Span, method string, start time.Time, err error) {
+	code := status.Code(err)
+	fields := []interface{}{"method", method, "code", code.String(), "duration", time.Since(start)}
+	span.SetAttributes(attribute.String("rpc.grpc.status_code", code.String()))
+
+	level := logging.LevelInfo
+	switch code {
+	case codes.Internal, codes.Unavailable, codes.Unknown:
+		level = logging.LevelError
+		fields = append(fields, "error", err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+
+	logging.FromContext(ctx).Log(level, "call", fields...)
+}
+
+// unaryLog tags, traces and logs unary calls
+func (s *Server) unaryLog(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	ctx, span, id := s.startCall(ctx, info.FullMethod)
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, id))
+
+	resp, err := handler(ctx, req)
+	endCall(ctx, span, info.FullMethod, start, err)
+	return resp, err
+}
+
+// streamLog tags, traces and logs streaming calls
+func (s *Server) streamLog(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx, span, id := s.startCall(ss.Context(), info.FullMethod)
+	ss.SetHeader(metadata.Pairs(requestIDMetadata, id))
+
+	err := handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	endCall(ctx, span, info.FullMethod, start, err)
+	return err
+}
+
+// metadataCarrier adapts incoming metadata to the propagation of trace
+// context
+type metadataCarrier metadata.MD
+
+func (m metadataCarrier) Get(key string) string {
+	if values := metadata.MD(m).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (m metadataCarrier) Set(key, value string) {
+	metadata.MD(m).Set(key, value)
+}
+
+func (m metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,223 @@
+package rpc
+
+import (
+	"context"
+	"expvar"
+	"io"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/txross1993/superman-api/errors"
+	"github.com/txross1993/superman-api/logging"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/rpc/supermanpb"
+)
+
+var metrics = expvar.NewMap("rpc")
+
+const (
+	// DefaultHistoryLimit is the number of events GetUserHistory returns
+	// when the request does not set a limit
+	DefaultHistoryLimit = 100
+	// MaxHistoryLimit bounds the number of events one GetUserHistory call
+	// returns
+	MaxHistoryLimit = 1000
+)
+
+type service interface {
+	AnalyzeEvent(context.Context, *models.UserIPAccessEvent) (*models.Superman, error)
+	History(context.Context, string, string, int64, int) ([]models.UserIPAccessEvent, error)
+	Profile(context.Context, string, string) (*models.UserProfile, error)
+}
+
+// Server serves the SupermanService over grpc, backed by the same service
+// as the http api
+type Server struct {
+	supermanpb.UnimplementedSupermanServiceServer
+	service    service
+	keys       keystore
+	logger     *logging.Logger
+	timeout    time.Duration
+	validation models.ValidationPolicy
+	grpc       *grpc.Server
+}
+
+// Option represents a functional option for configuring a Server
+type Option func(s *Server)
+
+// WithKeystore provides the functional option for the api key store used to
+// authenticate clients. Authentication is disabled without one
+func WithKeystore(keys keystore) Option {
+	return func(s *Server) {
+		s.keys = keys
+	}
+}
+
+// WithLogger provides the functional option for the logger of calls, which
+// defaults to logging.Default
+func WithLogger(logger *logging.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithTimeout provides the functional option for the time allowed to serve
+// each unary call, on top of any deadline set by the client. Zero leaves
+// calls bounded only by their clients
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.timeout = timeout
+	}
+}
+
+// WithValidationPolicy provides the functional option for the policy events
+// are validated against, which defaults to models.DefaultValidationPolicy
+func WithValidationPolicy(p models.ValidationPolicy) Option {
+	return func(s *Server) {
+		s.validation = p
+	}
+}
+
+// NewServer creates a grpc server analyzing events with the service
+func NewServer(svc service, opts ...Option) *Server {
+	s := &Server{service: svc, logger: logging.Default(), validation: models.DefaultValidationPolicy()}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.grpc = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.unaryLog, s.unaryDeadline, s.unaryAuth),
+		grpc.ChainStreamInterceptor(s.streamLog, s.streamAuth),
+	)
+	supermanpb.RegisterSupermanServiceServer(s.grpc, s)
+
+	return s
+}
+
+// unaryDeadline bounds unary calls by the server's timeout
+func (s *Server) unaryDeadline(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if s.timeout <= 0 {
+		return handler(ctx, req)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return handler(ctx, req)
+}
+
+// Serve accepts connections on the listener until Stop is called
+func (s *Server) Serve(lis net.Listener) error {
+	return s.grpc.Serve(lis)
+}
+
+// Stop stops accepting calls and waits for those in flight to finish
+func (s *Server) Stop() {
+	s.grpc.GracefulStop()
+}
+
+// AnalyzeEvent stores the login event and compares it to the user's
+// neighboring logins and profile
+func (s *Server) AnalyzeEvent(ctx context.Context, in *supermanpb.UserIPAccessEvent) (*supermanpb.Superman, error) {
+	resp, err := s.analyze(ctx, in)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return resp, nil
+}
+
+// AnalyzeEvents analyzes each event of the stream in the order received and
+// answers it with its analysis or the reason it was rejected. The stream
+// ends when the client closes it
+func (s *Server) AnalyzeEvents(stream supermanpb.SupermanService_AnalyzeEventsServer) error {
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		result := &supermanpb.AnalyzeEventsResult{EventUuid: in.GetEventUuid()}
+		resp, err := s.analyze(stream.Context(), in)
+		if err != nil {
+			result.Result = &supermanpb.AnalyzeEventsResult_Error{Error: errorToProto(err)}
+		} else {
+			result.Result = &supermanpb.AnalyzeEventsResult_Superman{Superman: resp}
+		}
+
+		if err := stream.Send(result); err != nil {
+			return err
+		}
+	}
+}
+
+// analyze validates the event, attributes it to the calling client and its
+// tenant and hands it to the service
+func (s *Server) analyze(ctx context.Context, in *supermanpb.UserIPAccessEvent) (*supermanpb.Superman, error) {
+	event := eventFromProto(in)
+	if err := s.validation.Validate(event, time.Now()); err != nil {
+		metrics.Add("rejected", 1)
+		return nil, err
+	}
+
+	if key := client(ctx); key != nil {
+		event.Client = key.Name
+	}
+	event.Tenant = tenant(ctx)
+
+	resp, err := s.service.AnalyzeEvent(ctx, event)
+	if err != nil {
+		metrics.Add("failed", 1)
+		return nil, err
+	}
+
+	metrics.Add("analyzed", 1)
+	return supermanToProto(resp), nil
+}
+
+// GetUserHistory lists the stored login events of the user of the caller's
+// tenant, most recent first, along with the user's profile
+func (s *Server) GetUserHistory(ctx context.Context, in *supermanpb.GetUserHistoryRequest) (*supermanpb.UserHistory, error) {
+	var violations errors.Validation
+	if in.GetUsername() == "" {
+		violations.Add(&errors.MissingField{Name: "username"})
+	}
+
+	limit := int(in.GetLimit())
+	switch {
+	case limit < 0:
+		violations.Add(&errors.InvalidField{Name: "limit", Reason: "must not be negative"})
+	case limit == 0:
+		limit = DefaultHistoryLimit
+	case limit > MaxHistoryLimit:
+		limit = MaxHistoryLimit
+	}
+
+	if in.GetBeforeMillis() < 0 {
+		violations.Add(&errors.InvalidField{Name: "before_millis", Reason: "must not be negative"})
+	}
+
+	if err := violations.ErrorOrNil(); err != nil {
+		return nil, statusError(err)
+	}
+
+	events, err := s.service.History(ctx, tenant(ctx), in.GetUsername(), in.GetBeforeMillis(), limit)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	profile, err := s.service.Profile(ctx, tenant(ctx), in.GetUsername())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	out := &supermanpb.UserHistory{Profile: profileToProto(profile)}
+	for i := range events {
+		out.Events = append(out.Events, eventToProto(&events[i]))
+	}
+
+	return out, nil
+}
@@ -0,0 +1,392 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/txross1993/superman-api/auth"
+	"github.com/txross1993/superman-api/db"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/rpc/supermanpb"
+	"github.com/txross1993/superman-api/superman"
+)
+
+const start = int64(1514764800000)
+
+func TestAnalyzeEvent(t *testing.T) {
+	client, _, cleanup := testServer(t, false)
+	defer cleanup()
+
+	tests := map[string]struct {
+		event      *supermanpb.UserIPAccessEvent
+		wantCode   codes.Code
+		wantError  string
+		wantFields []string
+	}{
+		"millis": {
+			event: loginEvent(1, "alice", start),
+		},
+		"seconds": {
+			event: &supermanpb.UserIPAccessEvent{EventUuid: eventUUID(2, "alice"), Username: "alice", UnixTimestamp: start/1000 + 60, IpAddress: "10.0.0.1"},
+		},
+		"missing fields": {
+			event:      &supermanpb.UserIPAccessEvent{EventUuid: eventUUID(3, "alice"), IpAddress: "10.0.0.1"},
+			wantCode:   codes.InvalidArgument,
+			wantError:  "missing_field",
+			wantFields: []string{"username", "unix_timestamp"},
+		},
+		"invalid ip": {
+			event:      &supermanpb.UserIPAccessEvent{EventUuid: eventUUID(4, "alice"), Username: "alice", UnixMillis: start, IpAddress: "not-an-ip"},
+			wantCode:   codes.InvalidArgument,
+			wantError:  "invalid_ip",
+			wantFields: []string{"ip_address"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case %s", name)
+		resp, err := client.AnalyzeEvent(context.Background(), test.event)
+
+		if test.wantCode == codes.OK {
+			assert.NoError(t, err)
+			assert.NotNil(t, resp.GetCurrentGeo())
+			continue
+		}
+
+		st := status.Convert(err)
+		assert.Equal(t, test.wantCode, st.Code())
+		detail := errorDetail(st)
+		if assert.NotNil(t, detail) {
+			assert.Equal(t, test.wantError, detail.GetCode())
+			var fields []string
+			for _, violation := range detail.GetViolations() {
+				fields = append(fields, violation.GetField())
+			}
+			assert.Equal(t, test.wantFields, fields)
+		}
+	}
+}
+
+func TestAnalyzeEventsStream(t *testing.T) {
+	client, _, cleanup := testServer(t, false)
+	defer cleanup()
+
+	stream, err := client.AnalyzeEvents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := []*supermanpb.UserIPAccessEvent{
+		loginEvent(1, "bob", start),
+		{EventUuid: eventUUID(2, "bob"), Username: "bob", IpAddress: "10.0.0.1"},
+		loginEvent(3, "bob", start+3600*1000),
+	}
+	for _, event := range events {
+		if err := stream.Send(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+
+	var results []*supermanpb.AnalyzeEventsResult
+	for {
+		result, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, result)
+	}
+
+	// a rejected event is answered in place without ending the stream
+	if assert.Equal(t, len(events), len(results)) {
+		for i, result := range results {
+			assert.Equal(t, events[i].GetEventUuid(), result.GetEventUuid())
+		}
+		assert.NotNil(t, results[0].GetSuperman())
+		assert.Equal(t, "missing_field", results[1].GetError().GetCode())
+		assert.NotNil(t, results[2].GetSuperman().GetPrecedingIpAccess())
+	}
+}
+
+func TestGetUserHistory(t *testing.T) {
+	client, _, cleanup := testServer(t, false)
+	defer cleanup()
+
+	for i := 0; i < 5; i++ {
+		if _, err := client.AnalyzeEvent(context.Background(), loginEvent(i, "carol", start+int64(i)*3600*1000)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := client.AnalyzeEvent(context.Background(), loginEvent(9, "dave", start)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		request  *supermanpb.GetUserHistoryRequest
+		wantCode codes.Code
+		want     []int64
+	}{
+		"most recent": {
+			request: &supermanpb.GetUserHistoryRequest{Username: "carol"},
+			want:    []int64{4, 3, 2, 1, 0},
+		},
+		"limit": {
+			request: &supermanpb.GetUserHistoryRequest{Username: "carol", Limit: 2},
+			want:    []int64{4, 3},
+		},
+		"before": {
+			request: &supermanpb.GetUserHistoryRequest{Username: "carol", Limit: 2, BeforeMillis: start + 3*3600*1000},
+			want:    []int64{2, 1},
+		},
+		"unknown user": {
+			request: &supermanpb.GetUserHistoryRequest{Username: "erin"},
+		},
+		"missing username": {
+			request:  &supermanpb.GetUserHistoryRequest{},
+			wantCode: codes.InvalidArgument,
+		},
+		"negative limit": {
+			request:  &supermanpb.GetUserHistoryRequest{Username: "carol", Limit: -1},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case %s", name)
+		resp, err := client.GetUserHistory(context.Background(), test.request)
+		assert.Equal(t, test.wantCode, status.Code(err))
+		if err != nil {
+			continue
+		}
+
+		var hours []int64
+		for _, event := range resp.GetEvents() {
+			assert.Equal(t, test.request.GetUsername(), event.GetUsername())
+			hours = append(hours, (event.GetUnixMillis()-start)/(3600*1000))
+		}
+		assert.Equal(t, test.want, hours)
+
+		if len(test.want) > 0 {
+			assert.Equal(t, int64(5), resp.GetProfile().GetLoginCount())
+		} else {
+			assert.Nil(t, resp.GetProfile())
+		}
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	client, sqlDB, cleanup := testServer(t, true)
+	defer cleanup()
+
+	keys := map[string]*models.APIKey{
+		"ingest": {Name: "collector", Scopes: "ingest"},
+		"read":   {Name: "dashboard", Scopes: "read"},
+		"acme":   {Name: "acme", Scopes: "admin", Tenant: "acme"},
+	}
+	plaintext := map[string]string{}
+	for label, key := range keys {
+		pt, err := auth.NewKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		key.KeyHash = auth.Hash(pt)
+		key.Prefix = auth.Prefix(pt)
+		if err := sqlDB.CreateAPIKey(key); err != nil {
+			t.Fatal(err)
+		}
+		plaintext[label] = pt
+	}
+
+	tests := map[string]struct {
+		md          []string
+		wantAnalyze codes.Code
+		wantHistory codes.Code
+	}{
+		"no key":       {wantAnalyze: codes.Unauthenticated, wantHistory: codes.Unauthenticated},
+		"unknown key":  {md: []string{"x-api-key", "sk_unknown"}, wantAnalyze: codes.Unauthenticated, wantHistory: codes.Unauthenticated},
+		"ingest key":   {md: []string{"x-api-key", plaintext["ingest"]}, wantHistory: codes.PermissionDenied},
+		"read key":     {md: []string{"x-api-key", plaintext["read"]}, wantAnalyze: codes.PermissionDenied},
+		"bearer token": {md: []string{"authorization", "Bearer " + plaintext["ingest"]}, wantHistory: codes.PermissionDenied},
+		"bound key":    {md: []string{"x-api-key", plaintext["acme"]}},
+		"other tenant": {md: []string{"x-api-key", plaintext["acme"], "x-tenant-id", "globex"}, wantAnalyze: codes.PermissionDenied, wantHistory: codes.PermissionDenied},
+		"bad tenant":   {md: []string{"x-api-key", plaintext["acme"], "x-tenant-id", "no spaces"}, wantAnalyze: codes.InvalidArgument, wantHistory: codes.InvalidArgument},
+		"unbound key":  {md: []string{"x-api-key", plaintext["ingest"], "x-tenant-id", "acme"}, wantAnalyze: codes.PermissionDenied, wantHistory: codes.PermissionDenied},
+	}
+
+	i := 0
+	for name, test := range tests {
+		t.Logf("Running test case %s", name)
+		ctx := metadata.AppendToOutgoingContext(context.Background(), test.md...)
+
+		i++
+		_, err := client.AnalyzeEvent(ctx, loginEvent(i, "frank", start+int64(i)*1000))
+		assert.Equal(t, test.wantAnalyze, status.Code(err))
+
+		_, err = client.GetUserHistory(ctx, &supermanpb.GetUserHistoryRequest{Username: "frank"})
+		assert.Equal(t, test.wantHistory, status.Code(err))
+
+		stream, err := client.AnalyzeEvents(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// an authenticated stream ends cleanly once the client closes it
+		stream.CloseSend()
+		if _, err = stream.Recv(); err == io.EOF {
+			err = nil
+		}
+		assert.Equal(t, test.wantAnalyze, status.Code(err))
+	}
+
+	stored, err := sqlDB.ListUserIPAccessEvents(context.Background(), models.DefaultTenant, "frank", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Equal(t, 2, len(stored)) {
+		assert.Equal(t, "collector", stored[0].Client)
+	}
+
+	stored, err = sqlDB.ListUserIPAccessEvents(context.Background(), "acme", "frank", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Equal(t, 1, len(stored)) {
+		assert.Equal(t, "acme", stored[0].Client)
+	}
+}
+
+// testServer serves a service storing events in a temporary database over
+// an in memory connection, authenticating clients against the database's
+// api keys if requested
+func TestRequestID(t *testing.T) {
+	client, _, cleanup := testServer(t, false)
+	defer cleanup()
+
+	tests := map[string]struct {
+		sent     string
+		expected string
+	}{
+		"caller provided": {sent: "trace-7", expected: "trace-7"},
+		"generated":       {},
+		"malformed":       {sent: "two words"},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case %s", name)
+		ctx := context.Background()
+		if test.sent != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, requestIDMetadata, test.sent)
+		}
+
+		var header metadata.MD
+		if _, err := client.AnalyzeEvent(ctx, loginEvent(1, "grace", start), grpc.Header(&header)); err != nil {
+			t.Fatal(err)
+		}
+
+		ids := header.Get(requestIDMetadata)
+		if assert.Equal(t, 1, len(ids)) {
+			if test.expected != "" {
+				assert.Equal(t, test.expected, ids[0])
+			} else {
+				assert.Equal(t, 32, len(ids[0]))
+			}
+		}
+	}
+}
+
+func testServer(t *testing.T, authenticate bool) (supermanpb.SupermanServiceClient, db.DB, func()) {
+	sqlDB, cleanup := newTestDB(t)
+
+	var opts []Option
+	if authenticate {
+		opts = append(opts, WithKeystore(sqlDB))
+	}
+	server := NewServer(superman.NewService(fixedGeo{}, sqlDB), opts...)
+
+	lis := bufconn.Listen(1 << 20)
+	go server.Serve(lis)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return supermanpb.NewSupermanServiceClient(conn), sqlDB, func() {
+		conn.Close()
+		server.Stop()
+		cleanup()
+	}
+}
+
+// newTestDB opens a database in a temporary directory, returning a func
+// closing the database and removing the directory
+func newTestDB(t *testing.T) (db.DB, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "superman-rpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sqlDB, err := db.InitDB(path.Join(dir, "test.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return sqlDB, func() {
+		sqlDB.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// loginEvent creates a login event numbered n for the username
+func loginEvent(n int, username string, millis int64) *supermanpb.UserIPAccessEvent {
+	return &supermanpb.UserIPAccessEvent{
+		EventUuid:  eventUUID(n, username),
+		Username:   username,
+		UnixMillis: millis,
+		IpAddress:  "10.0.0.1",
+	}
+}
+
+// eventUUID creates a uuid unique to the event number and username
+func eventUUID(n int, username string) string {
+	return fmt.Sprintf("%08x-0000-4000-8000-%012x", n, crc32.ChecksumIEEE([]byte(username)))
+}
+
+// errorDetail returns the Error message carried by the status if any
+func errorDetail(st *status.Status) *supermanpb.Error {
+	for _, detail := range st.Details() {
+		if e, ok := detail.(*supermanpb.Error); ok {
+			return e
+		}
+	}
+	return nil
+}
+
+// fixedGeo geoencodes every ip address to the same place
+type fixedGeo struct{}
+
+func (fixedGeo) GetCoordinatesFromIP(ctx context.Context, ip string) (*models.Geography, error) {
+	return &models.Geography{Latitude: 40, Longitude: -100}, nil
+}
@@ -0,0 +1,93 @@
+package rpc
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/txross1993/superman-api/errors"
+	"github.com/txross1993/superman-api/rpc/supermanpb"
+)
+
+// statusCodes holds the grpc status code for each error code, mirroring the
+// http statuses of the problem details
+var statusCodes = map[errors.Code]codes.Code{
+	errors.CodeInvalidIP:          codes.InvalidArgument,
+	errors.CodeMissingField:       codes.InvalidArgument,
+	errors.CodeInvalidField:       codes.InvalidArgument,
+	errors.CodeInvalidTimestamp:   codes.InvalidArgument,
+	errors.CodeMalformedRequest:   codes.InvalidArgument,
+	errors.CodeGeolocationFailed:  codes.Internal,
+	errors.CodeStorageUnavailable: codes.Unavailable,
+	errors.CodePublishFailed:      codes.Unavailable,
+	errors.CodeUnauthorized:       codes.Unauthenticated,
+	errors.CodeForbidden:          codes.PermissionDenied,
+	errors.CodeRateLimited:        codes.ResourceExhausted,
+	errors.CodeRequestTooLarge:    codes.ResourceExhausted,
+	errors.CodeTimeout:            codes.DeadlineExceeded,
+	errors.CodeNotFound:           codes.NotFound,
+	errors.CodeInternal:           codes.Internal,
+}
+
+// errorToProto maps the error to its stable code and field violations.
+// Details of server side failures are withheld from the client as they are
+// from the problem details
+func errorToProto(err error) *supermanpb.Error {
+	code := errors.CodeOf(err)
+
+	detail := err.Error()
+	if code == errors.CodeInternal || code == errors.CodeStorageUnavailable || code == errors.CodePublishFailed || code == errors.CodeGeolocationFailed {
+		detail = ""
+	}
+
+	out := &supermanpb.Error{Code: string(code), Detail: detail}
+	for _, fieldErr := range errors.FieldsOf(err) {
+		out.Violations = append(out.Violations, &supermanpb.FieldViolation{
+			Field:  fieldErr.Field(),
+			Code:   string(fieldErr.Code()),
+			Detail: fieldErr.Error(),
+		})
+	}
+
+	return out
+}
+
+// statusError converts the error to a grpc status carrying its Error
+// message as a detail
+func statusError(err error) error {
+	detail := errorToProto(err)
+
+	code, ok := statusCodes[errors.Code(detail.Code)]
+	if !ok {
+		code = codes.Internal
+	}
+
+	msg := detail.Detail
+	if msg == "" {
+		msg = detail.Code
+	}
+
+	st, derr := status.New(code, msg).WithDetails(detail)
+	if derr != nil {
+		return status.Error(code, msg)
+	}
+	return st.Err()
+}
+
+// codeError converts the code and detail to a grpc status
+func codeError(code errors.Code, detail string) error {
+	return statusError(&codedError{code: code, detail: detail})
+}
+
+// codedError is an error of a code without a more specific error type
+type codedError struct {
+	code   errors.Code
+	detail string
+}
+
+func (err *codedError) Error() string {
+	return err.detail
+}
+
+func (err *codedError) Code() errors.Code {
+	return err.code
+}
@@ -0,0 +1,1748 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.26.0
+// 	protoc        (unknown)
+// source: superman/v1/superman.proto
+
+package supermanpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// UserIPAccessEvent is an instance of access from an ip address for a
+// username. unix_millis takes precedence over unix_timestamp when set.
+type UserIPAccessEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	EventUuid     string `protobuf:"bytes,1,opt,name=event_uuid,json=eventUuid,proto3" json:"event_uuid,omitempty"`
+	Username      string `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	UnixTimestamp int64  `protobuf:"varint,3,opt,name=unix_timestamp,json=unixTimestamp,proto3" json:"unix_timestamp,omitempty"`
+	UnixMillis    int64  `protobuf:"varint,4,opt,name=unix_millis,json=unixMillis,proto3" json:"unix_millis,omitempty"`
+	IpAddress     string `protobuf:"bytes,5,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+}
+
+func (x *UserIPAccessEvent) Reset() {
+	*x = UserIPAccessEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_superman_v1_superman_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UserIPAccessEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserIPAccessEvent) ProtoMessage() {}
+
+func (x *UserIPAccessEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_superman_v1_superman_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserIPAccessEvent.ProtoReflect.Descriptor instead.
+func (*UserIPAccessEvent) Descriptor() ([]byte, []int) {
+	return file_superman_v1_superman_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *UserIPAccessEvent) GetEventUuid() string {
+	if x != nil {
+		return x.EventUuid
+	}
+	return ""
+}
+
+func (x *UserIPAccessEvent) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *UserIPAccessEvent) GetUnixTimestamp() int64 {
+	if x != nil {
+		return x.UnixTimestamp
+	}
+	return 0
+}
+
+func (x *UserIPAccessEvent) GetUnixMillis() int64 {
+	if x != nil {
+		return x.UnixMillis
+	}
+	return 0
+}
+
+func (x *UserIPAccessEvent) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+// Geography is the location of an ip address.
+type Geography struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Lat      float64 `protobuf:"fixed64,1,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lon      float64 `protobuf:"fixed64,2,opt,name=lon,proto3" json:"lon,omitempty"`
+	Radius   uint32  `protobuf:"varint,3,opt,name=radius,proto3" json:"radius,omitempty"`
+	Country  string  `protobuf:"bytes,4,opt,name=country,proto3" json:"country,omitempty"`
+	TimeZone string  `protobuf:"bytes,5,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`
+	Asn      uint32  `protobuf:"varint,6,opt,name=asn,proto3" json:"asn,omitempty"`
+}
+
+func (x *Geography) Reset() {
+	*x = Geography{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_superman_v1_superman_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Geography) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Geography) ProtoMessage() {}
+
+func (x *Geography) ProtoReflect() protoreflect.Message {
+	mi := &file_superman_v1_superman_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Geography.ProtoReflect.Descriptor instead.
+func (*Geography) Descriptor() ([]byte, []int) {
+	return file_superman_v1_superman_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Geography) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *Geography) GetLon() float64 {
+	if x != nil {
+		return x.Lon
+	}
+	return 0
+}
+
+func (x *Geography) GetRadius() uint32 {
+	if x != nil {
+		return x.Radius
+	}
+	return 0
+}
+
+func (x *Geography) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *Geography) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
+func (x *Geography) GetAsn() uint32 {
+	if x != nil {
+		return x.Asn
+	}
+	return 0
+}
+
+// IPAccess is a neighboring login and the travel between it and the
+// analyzed login.
+type IPAccess struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Geography       *Geography `protobuf:"bytes,1,opt,name=geography,proto3" json:"geography,omitempty"`
+	Ip              string     `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
+	Speed           int64      `protobuf:"varint,3,opt,name=speed,proto3" json:"speed,omitempty"`
+	Distance        float64    `protobuf:"fixed64,4,opt,name=distance,proto3" json:"distance,omitempty"`
+	Assessment      string     `protobuf:"bytes,5,opt,name=assessment,proto3" json:"assessment,omitempty"`
+	Timestamp       int64      `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	TimestampMillis int64      `protobuf:"varint,7,opt,name=timestamp_millis,json=timestampMillis,proto3" json:"timestamp_millis,omitempty"`
+}
+
+func (x *IPAccess) Reset() {
+	*x = IPAccess{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_superman_v1_superman_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IPAccess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IPAccess) ProtoMessage() {}
+
+func (x *IPAccess) ProtoReflect() protoreflect.Message {
+	mi := &file_superman_v1_superman_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IPAccess.ProtoReflect.Descriptor instead.
+func (*IPAccess) Descriptor() ([]byte, []int) {
+	return file_superman_v1_superman_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *IPAccess) GetGeography() *Geography {
+	if x != nil {
+		return x.Geography
+	}
+	return nil
+}
+
+func (x *IPAccess) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *IPAccess) GetSpeed() int64 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
+}
+
+func (x *IPAccess) GetDistance() float64 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+func (x *IPAccess) GetAssessment() string {
+	if x != nil {
+		return x.Assessment
+	}
+	return ""
+}
+
+func (x *IPAccess) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *IPAccess) GetTimestampMillis() int64 {
+	if x != nil {
+		return x.TimestampMillis
+	}
+	return 0
+}
+
+// TravelPath is a time ordered chain of logins in which one or more pairs
+// violate the travel policy.
+type TravelPath struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hops       []*IPAccess `protobuf:"bytes,1,rep,name=hops,proto3" json:"hops,omitempty"`
+	Speed      int64       `protobuf:"varint,2,opt,name=speed,proto3" json:"speed,omitempty"`
+	Distance   float64     `protobuf:"fixed64,3,opt,name=distance,proto3" json:"distance,omitempty"`
+	Assessment string      `protobuf:"bytes,4,opt,name=assessment,proto3" json:"assessment,omitempty"`
+}
+
+func (x *TravelPath) Reset() {
+	*x = TravelPath{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_superman_v1_superman_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TravelPath) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TravelPath) ProtoMessage() {}
+
+func (x *TravelPath) ProtoReflect() protoreflect.Message {
+	mi := &file_superman_v1_superman_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TravelPath.ProtoReflect.Descriptor instead.
+func (*TravelPath) Descriptor() ([]byte, []int) {
+	return file_superman_v1_superman_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TravelPath) GetHops() []*IPAccess {
+	if x != nil {
+		return x.Hops
+	}
+	return nil
+}
+
+func (x *TravelPath) GetSpeed() int64 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
+}
+
+func (x *TravelPath) GetDistance() float64 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+func (x *TravelPath) GetAssessment() string {
+	if x != nil {
+		return x.Assessment
+	}
+	return ""
+}
+
+// ConcurrentSessions is a user active from distinct locations which
+// alternate within a short period.
+type ConcurrentSessions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Locations            []*Geography `protobuf:"bytes,1,rep,name=locations,proto3" json:"locations,omitempty"`
+	Alternations         int32        `protobuf:"varint,2,opt,name=alternations,proto3" json:"alternations,omitempty"`
+	FirstTimestampMillis int64        `protobuf:"varint,3,opt,name=first_timestamp_millis,json=firstTimestampMillis,proto3" json:"first_timestamp_millis,omitempty"`
+	LastTimestampMillis  int64        `protobuf:"varint,4,opt,name=last_timestamp_millis,json=lastTimestampMillis,proto3" json:"last_timestamp_millis,omitempty"`
+	Events               []*IPAccess  `protobuf:"bytes,5,rep,name=events,proto3" json:"events,omitempty"`
+}
+
+func (x *ConcurrentSessions) Reset() {
+	*x = ConcurrentSessions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_superman_v1_superman_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConcurrentSessions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConcurrentSessions) ProtoMessage() {}
+
+func (x *ConcurrentSessions) ProtoReflect() protoreflect.Message {
+	mi := &file_superman_v1_superman_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConcurrentSessions.ProtoReflect.Descriptor instead.
+func (*ConcurrentSessions) Descriptor() ([]byte, []int) {
+	return file_superman_v1_superman_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ConcurrentSessions) GetLocations() []*Geography {
+	if x != nil {
+		return x.Locations
+	}
+	return nil
+}
+
+func (x *ConcurrentSessions) GetAlternations() int32 {
+	if x != nil {
+		return x.Alternations
+	}
+	return 0
+}
+
+func (x *ConcurrentSessions) GetFirstTimestampMillis() int64 {
+	if x != nil {
+		return x.FirstTimestampMillis
+	}
+	return 0
+}
+
+func (x *ConcurrentSessions) GetLastTimestampMillis() int64 {
+	if x != nil {
+		return x.LastTimestampMillis
+	}
+	return 0
+}
+
+func (x *ConcurrentSessions) GetEvents() []*IPAccess {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+// ProfileLocation is a cluster of a user's logins.
+type ProfileLocation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Lat            float64 `protobuf:"fixed64,1,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lon            float64 `protobuf:"fixed64,2,opt,name=lon,proto3" json:"lon,omitempty"`
+	Radius         uint32  `protobuf:"varint,3,opt,name=radius,proto3" json:"radius,omitempty"`
+	Country        string  `protobuf:"bytes,4,opt,name=country,proto3" json:"country,omitempty"`
+	Count          int64   `protobuf:"varint,5,opt,name=count,proto3" json:"count,omitempty"`
+	LastSeenMillis int64   `protobuf:"varint,6,opt,name=last_seen_millis,json=lastSeenMillis,proto3" json:"last_seen_millis,omitempty"`
+}
+
+func (x *ProfileLocation) Reset() {
+	*x = ProfileLocation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_superman_v1_superman_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProfileLocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProfileLocation) ProtoMessage() {}
+
+func (x *ProfileLocation) ProtoReflect() protoreflect.Message {
+	mi := &file_superman_v1_superman_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProfileLocation.ProtoReflect.Descriptor instead.
+func (*ProfileLocation) Descriptor() ([]byte, []int) {
+	return file_superman_v1_superman_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ProfileLocation) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *ProfileLocation) GetLon() float64 {
+	if x != nil {
+		return x.Lon
+	}
+	return 0
+}
+
+func (x *ProfileLocation) GetRadius() uint32 {
+	if x != nil {
+		return x.Radius
+	}
+	return 0
+}
+
+func (x *ProfileLocation) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *ProfileLocation) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *ProfileLocation) GetLastSeenMillis() int64 {
+	if x != nil {
+		return x.LastSeenMillis
+	}
+	return 0
+}
+
+// UnfamiliarLocation is a login far from every location in the user's
+// profile.
+type UnfamiliarLocation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NearestMiles   float64          `protobuf:"fixed64,1,opt,name=nearest_miles,json=nearestMiles,proto3" json:"nearest_miles,omitempty"`
+	Nearest        *ProfileLocation `protobuf:"bytes,2,opt,name=nearest,proto3" json:"nearest,omitempty"`
+	KnownLocations int32            `protobuf:"varint,3,opt,name=known_locations,json=knownLocations,proto3" json:"known_locations,omitempty"`
+	ProfileLogins  int64            `protobuf:"varint,4,opt,name=profile_logins,json=profileLogins,proto3" json:"profile_logins,omitempty"`
+}
+
+func (x *UnfamiliarLocation) Reset() {
+	*x = UnfamiliarLocation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_superman_v1_superman_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnfamiliarLocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnfamiliarLocation) ProtoMessage() {}
+
+func (x *UnfamiliarLocation) ProtoReflect() protoreflect.Message {
+	mi := &file_superman_v1_superman_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnfamiliarLocation.ProtoReflect.Descriptor instead.
+func (*UnfamiliarLocation) Descriptor() ([]byte, []int) {
+	return file_superman_v1_superman_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UnfamiliarLocation) GetNearestMiles() float64 {
+	if x != nil {
+		return x.NearestMiles
+	}
+	return 0
+}
+
+func (x *UnfamiliarLocation) GetNearest() *ProfileLocation {
+	if x != nil {
+		return x.Nearest
+	}
+	return nil
+}
+
+func (x *UnfamiliarLocation) GetKnownLocations() int32 {
+	if x != nil {
+		return x.KnownLocations
+	}
+	return 0
+}
+
+func (x *UnfamiliarLocation) GetProfileLogins() int64 {
+	if x != nil {
+		return x.ProfileLogins
+	}
+	return 0
+}
+
+// UnusualHour is a login at a local hour the user rarely logs in at.
+type UnusualHour struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LocalHour      int32   `protobuf:"varint,1,opt,name=local_hour,json=localHour,proto3" json:"local_hour,omitempty"`
+	TimeZone       string  `protobuf:"bytes,2,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`
+	Confidence     float64 `protobuf:"fixed64,3,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	LoginsNearHour int64   `protobuf:"varint,4,opt,name=logins_near_hour,json=loginsNearHour,proto3" json:"logins_near_hour,omitempty"`
+	HistoryLogins  int64   `protobuf:"varint,5,opt,name=history_logins,json=historyLogins,proto3" json:"history_logins,omitempty"`
+}
+
+func (x *UnusualHour) Reset() {
+	*x = UnusualHour{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_superman_v1_superman_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnusualHour) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnusualHour) ProtoMessage() {}
+
+func (x *UnusualHour) ProtoReflect() protoreflect.Message {
+	mi := &file_superman_v1_superman_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnusualHour.ProtoReflect.Descriptor instead.
+func (*UnusualHour) Descriptor() ([]byte, []int) {
+	return file_superman_v1_superman_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UnusualHour) GetLocalHour() int32 {
+	if x != nil {
+		return x.LocalHour
+	}
+	return 0
+}
+
+func (x *UnusualHour) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
+func (x *UnusualHour) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *UnusualHour) GetLoginsNearHour() int64 {
+	if x != nil {
+		return x.LoginsNearHour
+	}
+	return 0
+}
+
+func (x *UnusualHour) GetHistoryLogins() int64 {
+	if x != nil {
+		return x.HistoryLogins
+	}
+	return 0
+}
+
+// Superman is the analysis of a login event.
+type Superman struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CurrentGeo                     *Geography          `protobuf:"bytes,1,opt,name=current_geo,json=currentGeo,proto3" json:"current_geo,omitempty"`
+	TravelToCurrentGeoSuspicious   bool                `protobuf:"varint,2,opt,name=travel_to_current_geo_suspicious,json=travelToCurrentGeoSuspicious,proto3" json:"travel_to_current_geo_suspicious,omitempty"`
+	TravelFromCurrentGeoSuspicious bool                `protobuf:"varint,3,opt,name=travel_from_current_geo_suspicious,json=travelFromCurrentGeoSuspicious,proto3" json:"travel_from_current_geo_suspicious,omitempty"`
+	PrecedingIpAccess              *IPAccess           `protobuf:"bytes,4,opt,name=preceding_ip_access,json=precedingIpAccess,proto3" json:"preceding_ip_access,omitempty"`
+	SubsequentIpAccess             *IPAccess           `protobuf:"bytes,5,opt,name=subsequent_ip_access,json=subsequentIpAccess,proto3" json:"subsequent_ip_access,omitempty"`
+	SuspiciousPaths                []*TravelPath       `protobuf:"bytes,6,rep,name=suspicious_paths,json=suspiciousPaths,proto3" json:"suspicious_paths,omitempty"`
+	ConcurrentSessions             *ConcurrentSessions `protobuf:"bytes,7,opt,name=concurrent_sessions,json=concurrentSessions,proto3" json:"concurrent_sessions,omitempty"`
+	UnfamiliarLocation             *UnfamiliarLocation `protobuf:"bytes,8,opt,name=unfamiliar_location,json=unfamiliarLocation,proto3" json:"unfamiliar_location,omitempty"`
+	UnusualHour                    *UnusualHour        `protobuf:"bytes,9,opt,name=unusual_hour,json=unusualHour,proto3" json:"unusual_hour,omitempty"`
+}
+
+func (x *Superman) Reset() {
+	*x = Superman{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_superman_v1_superman_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Superman) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Superman) ProtoMessage() {}
+
+func (x *Superman) ProtoReflect() protoreflect.Message {
+	mi := &file_superman_v1_superman_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Superman.ProtoReflect.Descriptor instead.
+func (*Superman) Descriptor() ([]byte, []int) {
+	return file_superman_v1_superman_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Superman) GetCurrentGeo() *Geography {
+	if x != nil {
+		return x.CurrentGeo
+	}
+	return nil
+}
+
+func (x *Superman) GetTravelToCurrentGeoSuspicious() bool {
+	if x != nil {
+		return x.TravelToCurrentGeoSuspicious
+	}
+	return false
+}
+
+func (x *Superman) GetTravelFromCurrentGeoSuspicious() bool {
+	if x != nil {
+		return x.TravelFromCurrentGeoSuspicious
+	}
+	return false
+}
+
+func (x *Superman) GetPrecedingIpAccess() *IPAccess {
+	if x != nil {
+		return x.PrecedingIpAccess
+	}
+	return nil
+}
+
+func (x *Superman) GetSubsequentIpAccess() *IPAccess {
+	if x != nil {
+		return x.SubsequentIpAccess
+	}
+	return nil
+}
+
+func (x *Superman) GetSuspiciousPaths() []*TravelPath {
+	if x != nil {
+		return x.SuspiciousPaths
+	}
+	return nil
+}
+
+func (x *Superman) GetConcurrentSessions() *ConcurrentSessions {
+	if x != nil {
+		return x.ConcurrentSessions
+	}
+	return nil
+}
+
+func (x *Superman) GetUnfamiliarLocation() *UnfamiliarLocation {
+	if x != nil {
+		return x.UnfamiliarLocation
+	}
+	return nil
+}
+
+func (x *Superman) GetUnusualHour() *UnusualHour {
+	if x != nil {
+		return x.UnusualHour
+	}
+	return nil
+}
+
+// FieldViolation is a problem with a single field of an event.
+type FieldViolation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Field  string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Code   string `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Detail string `protobuf:"bytes,3,opt,name=detail,proto3" json:"detail,omitempty"`
+}
+
+func (x *FieldViolation) Reset() {
+	*x = FieldViolation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_superman_v1_superman_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FieldViolation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldViolation) ProtoMessage() {}
+
+func (x *FieldViolation) ProtoReflect() protoreflect.Message {
+	mi := &file_superman_v1_superman_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldViolation.ProtoReflect.Descriptor instead.
+func (*FieldViolation) Descriptor() ([]byte, []int) {
+	return file_superman_v1_superman_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *FieldViolation) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *FieldViolation) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *FieldViolation) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+// Error carries the stable error code of a failure, the same as the code of
+// the http problem details.
+type Error struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Code       string            `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Detail     string            `protobuf:"bytes,2,opt,name=detail,proto3" json:"detail,omitempty"`
+	Violations []*FieldViolation `protobuf:"bytes,3,rep,name=violations,proto3" json:"violations,omitempty"`
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_superman_v1_superman_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_superman_v1_superman_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_superman_v1_superman_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Error) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Error) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+func (x *Error) GetViolations() []*FieldViolation {
+	if x != nil {
+		return x.Violations
+	}
+	return nil
+}
+
+// AnalyzeEventsResult answers one event of an AnalyzeEvents stream with
+// either its analysis or the reason it was rejected.
+type AnalyzeEventsResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	EventUuid string `protobuf:"bytes,1,opt,name=event_uuid,json=eventUuid,proto3" json:"event_uuid,omitempty"`
+	// Types that are assignable to Result:
+	//	*AnalyzeEventsResult_Superman
+	//	*AnalyzeEventsResult_Error
+	Result isAnalyzeEventsResult_Result `protobuf_oneof:"result"`
+}
+
+func (x *AnalyzeEventsResult) Reset() {
+	*x = AnalyzeEventsResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_superman_v1_superman_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnalyzeEventsResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeEventsResult) ProtoMessage() {}
+
+func (x *AnalyzeEventsResult) ProtoReflect() protoreflect.Message {
+	mi := &file_superman_v1_superman_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeEventsResult.ProtoReflect.Descriptor instead.
+func (*AnalyzeEventsResult) Descriptor() ([]byte, []int) {
+	return file_superman_v1_superman_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AnalyzeEventsResult) GetEventUuid() string {
+	if x != nil {
+		return x.EventUuid
+	}
+	return ""
+}
+
+func (m *AnalyzeEventsResult) GetResult() isAnalyzeEventsResult_Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (x *AnalyzeEventsResult) GetSuperman() *Superman {
+	if x, ok := x.GetResult().(*AnalyzeEventsResult_Superman); ok {
+		return x.Superman
+	}
+	return nil
+}
+
+func (x *AnalyzeEventsResult) GetError() *Error {
+	if x, ok := x.GetResult().(*AnalyzeEventsResult_Error); ok {
+		return x.Error
+	}
+	return nil
+}
+
+type isAnalyzeEventsResult_Result interface {
+	isAnalyzeEventsResult_Result()
+}
+
+type AnalyzeEventsResult_Superman struct {
+	Superman *Superman `protobuf:"bytes,2,opt,name=superman,proto3,oneof"`
+}
+
+type AnalyzeEventsResult_Error struct {
+	Error *Error `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*AnalyzeEventsResult_Superman) isAnalyzeEventsResult_Result() {}
+
+func (*AnalyzeEventsResult_Error) isAnalyzeEventsResult_Result() {}
+
+// GetUserHistoryRequest selects up to limit of a user's events which
+// occurred before before_millis, or the most recent when zero.
+type GetUserHistoryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Username     string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Limit        int32  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	BeforeMillis int64  `protobuf:"varint,3,opt,name=before_millis,json=beforeMillis,proto3" json:"before_millis,omitempty"`
+}
+
+func (x *GetUserHistoryRequest) Reset() {
+	*x = GetUserHistoryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_superman_v1_superman_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetUserHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserHistoryRequest) ProtoMessage() {}
+
+func (x *GetUserHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_superman_v1_superman_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetUserHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_superman_v1_superman_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetUserHistoryRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *GetUserHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetUserHistoryRequest) GetBeforeMillis() int64 {
+	if x != nil {
+		return x.BeforeMillis
+	}
+	return 0
+}
+
+// UserProfile summarizes where and when a user usually logs in.
+type UserProfile struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Username        string             `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	LoginCount      int64              `protobuf:"varint,2,opt,name=login_count,json=loginCount,proto3" json:"login_count,omitempty"`
+	FirstSeenMillis int64              `protobuf:"varint,3,opt,name=first_seen_millis,json=firstSeenMillis,proto3" json:"first_seen_millis,omitempty"`
+	LastSeenMillis  int64              `protobuf:"varint,4,opt,name=last_seen_millis,json=lastSeenMillis,proto3" json:"last_seen_millis,omitempty"`
+	Locations       []*ProfileLocation `protobuf:"bytes,5,rep,name=locations,proto3" json:"locations,omitempty"`
+	Countries       map[string]int64   `protobuf:"bytes,6,rep,name=countries,proto3" json:"countries,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Asns            map[string]int64   `protobuf:"bytes,7,rep,name=asns,proto3" json:"asns,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	LocalHours      []int64            `protobuf:"varint,8,rep,packed,name=local_hours,json=localHours,proto3" json:"local_hours,omitempty"`
+}
+
+func (x *UserProfile) Reset() {
+	*x = UserProfile{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_superman_v1_superman_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UserProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserProfile) ProtoMessage() {}
+
+func (x *UserProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_superman_v1_superman_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserProfile.ProtoReflect.Descriptor instead.
+func (*UserProfile) Descriptor() ([]byte, []int) {
+	return file_superman_v1_superman_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *UserProfile) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *UserProfile) GetLoginCount() int64 {
+	if x != nil {
+		return x.LoginCount
+	}
+	return 0
+}
+
+func (x *UserProfile) GetFirstSeenMillis() int64 {
+	if x != nil {
+		return x.FirstSeenMillis
+	}
+	return 0
+}
+
+func (x *UserProfile) GetLastSeenMillis() int64 {
+	if x != nil {
+		return x.LastSeenMillis
+	}
+	return 0
+}
+
+func (x *UserProfile) GetLocations() []*ProfileLocation {
+	if x != nil {
+		return x.Locations
+	}
+	return nil
+}
+
+func (x *UserProfile) GetCountries() map[string]int64 {
+	if x != nil {
+		return x.Countries
+	}
+	return nil
+}
+
+func (x *UserProfile) GetAsns() map[string]int64 {
+	if x != nil {
+		return x.Asns
+	}
+	return nil
+}
+
+func (x *UserProfile) GetLocalHours() []int64 {
+	if x != nil {
+		return x.LocalHours
+	}
+	return nil
+}
+
+// UserHistory is a page of a user's login events and the user's profile.
+type UserHistory struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Events  []*UserIPAccessEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	Profile *UserProfile         `protobuf:"bytes,2,opt,name=profile,proto3" json:"profile,omitempty"`
+}
+
+func (x *UserHistory) Reset() {
+	*x = UserHistory{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_superman_v1_superman_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UserHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserHistory) ProtoMessage() {}
+
+func (x *UserHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_superman_v1_superman_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserHistory.ProtoReflect.Descriptor instead.
+func (*UserHistory) Descriptor() ([]byte, []int) {
+	return file_superman_v1_superman_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *UserHistory) GetEvents() []*UserIPAccessEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *UserHistory) GetProfile() *UserProfile {
+	if x != nil {
+		return x.Profile
+	}
+	return nil
+}
+
+var File_superman_v1_superman_proto protoreflect.FileDescriptor
+
+var file_superman_v1_superman_proto_rawDesc = []byte{
+	0x0a, 0x1a, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2f, 0x76, 0x31, 0x2f, 0x73, 0x75,
+	0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x73, 0x75,
+	0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x22, 0xb5, 0x01, 0x0a, 0x11, 0x55, 0x73,
+	0x65, 0x72, 0x49, 0x50, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12,
+	0x1d, 0x0a, 0x0a, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x75, 0x75, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x55, 0x75, 0x69, 0x64, 0x12, 0x1a,
+	0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x75, 0x6e,
+	0x69, 0x78, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0d, 0x75, 0x6e, 0x69, 0x78, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x12, 0x1f, 0x0a, 0x0b, 0x75, 0x6e, 0x69, 0x78, 0x5f, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x75, 0x6e, 0x69, 0x78, 0x4d, 0x69, 0x6c, 0x6c,
+	0x69, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x70, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x22, 0x90, 0x01, 0x0a, 0x09, 0x47, 0x65, 0x6f, 0x67, 0x72, 0x61, 0x70, 0x68, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6c, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x61,
+	0x74, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03,
+	0x6c, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x61, 0x64, 0x69, 0x75, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x06, 0x72, 0x61, 0x64, 0x69, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x7a, 0x6f,
+	0x6e, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x5a, 0x6f,
+	0x6e, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x61, 0x73, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x03, 0x61, 0x73, 0x6e, 0x22, 0xeb, 0x01, 0x0a, 0x08, 0x49, 0x50, 0x41, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x12, 0x34, 0x0a, 0x09, 0x67, 0x65, 0x6f, 0x67, 0x72, 0x61, 0x70, 0x68, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e,
+	0x76, 0x31, 0x2e, 0x47, 0x65, 0x6f, 0x67, 0x72, 0x61, 0x70, 0x68, 0x79, 0x52, 0x09, 0x67, 0x65,
+	0x6f, 0x67, 0x72, 0x61, 0x70, 0x68, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x70, 0x65, 0x65, 0x64,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x73, 0x70, 0x65, 0x65, 0x64, 0x12, 0x1a, 0x0a,
+	0x08, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x08, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x61, 0x73, 0x73,
+	0x65, 0x73, 0x73, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x61,
+	0x73, 0x73, 0x65, 0x73, 0x73, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x29, 0x0a, 0x10, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x5f, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x4d, 0x69, 0x6c, 0x6c,
+	0x69, 0x73, 0x22, 0x89, 0x01, 0x0a, 0x0a, 0x54, 0x72, 0x61, 0x76, 0x65, 0x6c, 0x50, 0x61, 0x74,
+	0x68, 0x12, 0x29, 0x0a, 0x04, 0x68, 0x6f, 0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x50,
+	0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x52, 0x04, 0x68, 0x6f, 0x70, 0x73, 0x12, 0x14, 0x0a, 0x05,
+	0x73, 0x70, 0x65, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x73, 0x70, 0x65,
+	0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x1e,
+	0x0a, 0x0a, 0x61, 0x73, 0x73, 0x65, 0x73, 0x73, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x61, 0x73, 0x73, 0x65, 0x73, 0x73, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x87,
+	0x02, 0x0a, 0x12, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x34, 0x0a, 0x09, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72,
+	0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x6f, 0x67, 0x72, 0x61, 0x70, 0x68, 0x79,
+	0x52, 0x09, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x61,
+	0x6c, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0c, 0x61, 0x6c, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12,
+	0x34, 0x0a, 0x16, 0x66, 0x69, 0x72, 0x73, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x5f, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x14, 0x66, 0x69, 0x72, 0x73, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x4d,
+	0x69, 0x6c, 0x6c, 0x69, 0x73, 0x12, 0x32, 0x0a, 0x15, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x5f, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x13, 0x6c, 0x61, 0x73, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x12, 0x2d, 0x0a, 0x06, 0x65, 0x76, 0x65,
+	0x6e, 0x74, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73, 0x75, 0x70, 0x65,
+	0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x50, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73,
+	0x52, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x22, 0xa7, 0x01, 0x0a, 0x0f, 0x50, 0x72, 0x6f,
+	0x66, 0x69, 0x6c, 0x65, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x10, 0x0a, 0x03,
+	0x6c, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x61, 0x74, 0x12, 0x10,
+	0x0a, 0x03, 0x6c, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x6f, 0x6e,
+	0x12, 0x16, 0x0a, 0x06, 0x72, 0x61, 0x64, 0x69, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x06, 0x72, 0x61, 0x64, 0x69, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x72, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x28, 0x0a, 0x10, 0x6c, 0x61, 0x73, 0x74,
+	0x5f, 0x73, 0x65, 0x65, 0x6e, 0x5f, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0e, 0x6c, 0x61, 0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x4d, 0x69, 0x6c, 0x6c,
+	0x69, 0x73, 0x22, 0xc1, 0x01, 0x0a, 0x12, 0x55, 0x6e, 0x66, 0x61, 0x6d, 0x69, 0x6c, 0x69, 0x61,
+	0x72, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x6e, 0x65, 0x61,
+	0x72, 0x65, 0x73, 0x74, 0x5f, 0x6d, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x0c, 0x6e, 0x65, 0x61, 0x72, 0x65, 0x73, 0x74, 0x4d, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x36,
+	0x0a, 0x07, 0x6e, 0x65, 0x61, 0x72, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1c, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x6e,
+	0x65, 0x61, 0x72, 0x65, 0x73, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x5f,
+	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12,
+	0x25, 0x0a, 0x0e, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x6c, 0x6f, 0x67, 0x69, 0x6e,
+	0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65,
+	0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x73, 0x22, 0xba, 0x01, 0x0a, 0x0b, 0x55, 0x6e, 0x75, 0x73, 0x75,
+	0x61, 0x6c, 0x48, 0x6f, 0x75, 0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x5f,
+	0x68, 0x6f, 0x75, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6c, 0x6f, 0x63, 0x61,
+	0x6c, 0x48, 0x6f, 0x75, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x7a, 0x6f,
+	0x6e, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x5a, 0x6f,
+	0x6e, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e,
+	0x63, 0x65, 0x12, 0x28, 0x0a, 0x10, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x73, 0x5f, 0x6e, 0x65, 0x61,
+	0x72, 0x5f, 0x68, 0x6f, 0x75, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x6c, 0x6f,
+	0x67, 0x69, 0x6e, 0x73, 0x4e, 0x65, 0x61, 0x72, 0x48, 0x6f, 0x75, 0x72, 0x12, 0x25, 0x0a, 0x0e,
+	0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x5f, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x73, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x4c, 0x6f, 0x67,
+	0x69, 0x6e, 0x73, 0x22, 0x8c, 0x05, 0x0a, 0x08, 0x53, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e,
+	0x12, 0x37, 0x0a, 0x0b, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x67, 0x65, 0x6f, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x6f, 0x67, 0x72, 0x61, 0x70, 0x68, 0x79, 0x52, 0x0a, 0x63,
+	0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x47, 0x65, 0x6f, 0x12, 0x46, 0x0a, 0x20, 0x74, 0x72, 0x61,
+	0x76, 0x65, 0x6c, 0x5f, 0x74, 0x6f, 0x5f, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x67,
+	0x65, 0x6f, 0x5f, 0x73, 0x75, 0x73, 0x70, 0x69, 0x63, 0x69, 0x6f, 0x75, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x1c, 0x74, 0x72, 0x61, 0x76, 0x65, 0x6c, 0x54, 0x6f, 0x43, 0x75, 0x72,
+	0x72, 0x65, 0x6e, 0x74, 0x47, 0x65, 0x6f, 0x53, 0x75, 0x73, 0x70, 0x69, 0x63, 0x69, 0x6f, 0x75,
+	0x73, 0x12, 0x4a, 0x0a, 0x22, 0x74, 0x72, 0x61, 0x76, 0x65, 0x6c, 0x5f, 0x66, 0x72, 0x6f, 0x6d,
+	0x5f, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x67, 0x65, 0x6f, 0x5f, 0x73, 0x75, 0x73,
+	0x70, 0x69, 0x63, 0x69, 0x6f, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x1e, 0x74,
+	0x72, 0x61, 0x76, 0x65, 0x6c, 0x46, 0x72, 0x6f, 0x6d, 0x43, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74,
+	0x47, 0x65, 0x6f, 0x53, 0x75, 0x73, 0x70, 0x69, 0x63, 0x69, 0x6f, 0x75, 0x73, 0x12, 0x45, 0x0a,
+	0x13, 0x70, 0x72, 0x65, 0x63, 0x65, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x69, 0x70, 0x5f, 0x61, 0x63,
+	0x63, 0x65, 0x73, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73, 0x75, 0x70,
+	0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x50, 0x41, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x52, 0x11, 0x70, 0x72, 0x65, 0x63, 0x65, 0x64, 0x69, 0x6e, 0x67, 0x49, 0x70, 0x41, 0x63,
+	0x63, 0x65, 0x73, 0x73, 0x12, 0x47, 0x0a, 0x14, 0x73, 0x75, 0x62, 0x73, 0x65, 0x71, 0x75, 0x65,
+	0x6e, 0x74, 0x5f, 0x69, 0x70, 0x5f, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x49, 0x50, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x52, 0x12, 0x73, 0x75, 0x62, 0x73, 0x65,
+	0x71, 0x75, 0x65, 0x6e, 0x74, 0x49, 0x70, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x42, 0x0a,
+	0x10, 0x73, 0x75, 0x73, 0x70, 0x69, 0x63, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x70, 0x61, 0x74, 0x68,
+	0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d,
+	0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x76, 0x65, 0x6c, 0x50, 0x61, 0x74, 0x68,
+	0x52, 0x0f, 0x73, 0x75, 0x73, 0x70, 0x69, 0x63, 0x69, 0x6f, 0x75, 0x73, 0x50, 0x61, 0x74, 0x68,
+	0x73, 0x12, 0x50, 0x0a, 0x13, 0x63, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f,
+	0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f,
+	0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e,
+	0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52,
+	0x12, 0x63, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x50, 0x0a, 0x13, 0x75, 0x6e, 0x66, 0x61, 0x6d, 0x69, 0x6c, 0x69, 0x61,
+	0x72, 0x5f, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1f, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x55,
+	0x6e, 0x66, 0x61, 0x6d, 0x69, 0x6c, 0x69, 0x61, 0x72, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x12, 0x75, 0x6e, 0x66, 0x61, 0x6d, 0x69, 0x6c, 0x69, 0x61, 0x72, 0x4c, 0x6f, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3b, 0x0a, 0x0c, 0x75, 0x6e, 0x75, 0x73, 0x75, 0x61, 0x6c,
+	0x5f, 0x68, 0x6f, 0x75, 0x72, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x75,
+	0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x6e, 0x75, 0x73, 0x75, 0x61,
+	0x6c, 0x48, 0x6f, 0x75, 0x72, 0x52, 0x0b, 0x75, 0x6e, 0x75, 0x73, 0x75, 0x61, 0x6c, 0x48, 0x6f,
+	0x75, 0x72, 0x22, 0x52, 0x0a, 0x0e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x56, 0x69, 0x6f, 0x6c, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f,
+	0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x16,
+	0x0a, 0x06, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x22, 0x70, 0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12,
+	0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63,
+	0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x12, 0x3b, 0x0a, 0x0a, 0x76,
+	0x69, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1b, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69,
+	0x65, 0x6c, 0x64, 0x56, 0x69, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x76, 0x69,
+	0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x9f, 0x01, 0x0a, 0x13, 0x41, 0x6e, 0x61,
+	0x6c, 0x79, 0x7a, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x75, 0x75, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x55, 0x75, 0x69, 0x64, 0x12,
+	0x33, 0x0a, 0x08, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x15, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x48, 0x00, 0x52, 0x08, 0x73, 0x75, 0x70, 0x65,
+	0x72, 0x6d, 0x61, 0x6e, 0x12, 0x2a, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76,
+	0x31, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x48, 0x00, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x42, 0x08, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x6e, 0x0a, 0x15, 0x47, 0x65,
+	0x74, 0x55, 0x73, 0x65, 0x72, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x5f,
+	0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x62, 0x65,
+	0x66, 0x6f, 0x72, 0x65, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x22, 0xf3, 0x03, 0x0a, 0x0b, 0x55,
+	0x73, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73,
+	0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73,
+	0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x5f,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x6c, 0x6f, 0x67,
+	0x69, 0x6e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x2a, 0x0a, 0x11, 0x66, 0x69, 0x72, 0x73, 0x74,
+	0x5f, 0x73, 0x65, 0x65, 0x6e, 0x5f, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0f, 0x66, 0x69, 0x72, 0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x4d, 0x69, 0x6c,
+	0x6c, 0x69, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x73, 0x65, 0x65, 0x6e,
+	0x5f, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x6c,
+	0x61, 0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x12, 0x3a, 0x0a,
+	0x09, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1c, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x50,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09,
+	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x45, 0x0a, 0x09, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x73,
+	0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x50,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x2e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x09, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73,
+	0x12, 0x36, 0x0a, 0x04, 0x61, 0x73, 0x6e, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22,
+	0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73, 0x65,
+	0x72, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x2e, 0x41, 0x73, 0x6e, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x04, 0x61, 0x73, 0x6e, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x6f, 0x63, 0x61,
+	0x6c, 0x5f, 0x68, 0x6f, 0x75, 0x72, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x03, 0x52, 0x0a, 0x6c,
+	0x6f, 0x63, 0x61, 0x6c, 0x48, 0x6f, 0x75, 0x72, 0x73, 0x1a, 0x3c, 0x0a, 0x0e, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x37, 0x0a, 0x09, 0x41, 0x73, 0x6e, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x22, 0x79, 0x0a, 0x0b, 0x55, 0x73, 0x65, 0x72, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12,
+	0x36, 0x0a, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1e, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73,
+	0x65, 0x72, 0x49, 0x50, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52,
+	0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x32, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x66, 0x69,
+	0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72,
+	0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x66, 0x69,
+	0x6c, 0x65, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x32, 0xff, 0x01, 0x0a, 0x0f,
+	0x53, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x45, 0x0a, 0x0c, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12,
+	0x1e, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73,
+	0x65, 0x72, 0x49, 0x50, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x1a,
+	0x15, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75,
+	0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x12, 0x55, 0x0a, 0x0d, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a,
+	0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1e, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d,
+	0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x49, 0x50, 0x41, 0x63, 0x63, 0x65,
+	0x73, 0x73, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x1a, 0x20, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d,
+	0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x28, 0x01, 0x30, 0x01, 0x12, 0x4e, 0x0a,
+	0x0e, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12,
+	0x22, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65,
+	0x74, 0x55, 0x73, 0x65, 0x72, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2e, 0x76,
+	0x31, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x42, 0x33, 0x5a,
+	0x31, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x78, 0x72, 0x6f,
+	0x73, 0x73, 0x31, 0x39, 0x39, 0x33, 0x2f, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e, 0x2d,
+	0x61, 0x70, 0x69, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x73, 0x75, 0x70, 0x65, 0x72, 0x6d, 0x61, 0x6e,
+	0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_superman_v1_superman_proto_rawDescOnce sync.Once
+	file_superman_v1_superman_proto_rawDescData = file_superman_v1_superman_proto_rawDesc
+)
+
+func file_superman_v1_superman_proto_rawDescGZIP() []byte {
+	file_superman_v1_superman_proto_rawDescOnce.Do(func() {
+		file_superman_v1_superman_proto_rawDescData = protoimpl.X.CompressGZIP(file_superman_v1_superman_proto_rawDescData)
+	})
+	return file_superman_v1_superman_proto_rawDescData
+}
+
+var file_superman_v1_superman_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_superman_v1_superman_proto_goTypes = []interface{}{
+	(*UserIPAccessEvent)(nil),     // 0: superman.v1.UserIPAccessEvent
+	(*Geography)(nil),             // 1: superman.v1.Geography
+	(*IPAccess)(nil),              // 2: superman.v1.IPAccess
+	(*TravelPath)(nil),            // 3: superman.v1.TravelPath
+	(*ConcurrentSessions)(nil),    // 4: superman.v1.ConcurrentSessions
+	(*ProfileLocation)(nil),       // 5: superman.v1.ProfileLocation
+	(*UnfamiliarLocation)(nil),    // 6: superman.v1.UnfamiliarLocation
+	(*UnusualHour)(nil),           // 7: superman.v1.UnusualHour
+	(*Superman)(nil),              // 8: superman.v1.Superman
+	(*FieldViolation)(nil),        // 9: superman.v1.FieldViolation
+	(*Error)(nil),                 // 10: superman.v1.Error
+	(*AnalyzeEventsResult)(nil),   // 11: superman.v1.AnalyzeEventsResult
+	(*GetUserHistoryRequest)(nil), // 12: superman.v1.GetUserHistoryRequest
+	(*UserProfile)(nil),           // 13: superman.v1.UserProfile
+	(*UserHistory)(nil),           // 14: superman.v1.UserHistory
+	nil,                           // 15: superman.v1.UserProfile.CountriesEntry
+	nil,                           // 16: superman.v1.UserProfile.AsnsEntry
+}
+var file_superman_v1_superman_proto_depIdxs = []int32{
+	1,  // 0: superman.v1.IPAccess.geography:type_name -> superman.v1.Geography
+	2,  // 1: superman.v1.TravelPath.hops:type_name -> superman.v1.IPAccess
+	1,  // 2: superman.v1.ConcurrentSessions.locations:type_name -> superman.v1.Geography
+	2,  // 3: superman.v1.ConcurrentSessions.events:type_name -> superman.v1.IPAccess
+	5,  // 4: superman.v1.UnfamiliarLocation.nearest:type_name -> superman.v1.ProfileLocation
+	1,  // 5: superman.v1.Superman.current_geo:type_name -> superman.v1.Geography
+	2,  // 6: superman.v1.Superman.preceding_ip_access:type_name -> superman.v1.IPAccess
+	2,  // 7: superman.v1.Superman.subsequent_ip_access:type_name -> superman.v1.IPAccess
+	3,  // 8: superman.v1.Superman.suspicious_paths:type_name -> superman.v1.TravelPath
+	4,  // 9: superman.v1.Superman.concurrent_sessions:type_name -> superman.v1.ConcurrentSessions
+	6,  // 10: superman.v1.Superman.unfamiliar_location:type_name -> superman.v1.UnfamiliarLocation
+	7,  // 11: superman.v1.Superman.unusual_hour:type_name -> superman.v1.UnusualHour
+	9,  // 12: superman.v1.Error.violations:type_name -> superman.v1.FieldViolation
+	8,  // 13: superman.v1.AnalyzeEventsResult.superman:type_name -> superman.v1.Superman
+	10, // 14: superman.v1.AnalyzeEventsResult.error:type_name -> superman.v1.Error
+	5,  // 15: superman.v1.UserProfile.locations:type_name -> superman.v1.ProfileLocation
+	15, // 16: superman.v1.UserProfile.countries:type_name -> superman.v1.UserProfile.CountriesEntry
+	16, // 17: superman.v1.UserProfile.asns:type_name -> superman.v1.UserProfile.AsnsEntry
+	0,  // 18: superman.v1.UserHistory.events:type_name -> superman.v1.UserIPAccessEvent
+	13, // 19: superman.v1.UserHistory.profile:type_name -> superman.v1.UserProfile
+	0,  // 20: superman.v1.SupermanService.AnalyzeEvent:input_type -> superman.v1.UserIPAccessEvent
+	0,  // 21: superman.v1.SupermanService.AnalyzeEvents:input_type -> superman.v1.UserIPAccessEvent
+	12, // 22: superman.v1.SupermanService.GetUserHistory:input_type -> superman.v1.GetUserHistoryRequest
+	8,  // 23: superman.v1.SupermanService.AnalyzeEvent:output_type -> superman.v1.Superman
+	11, // 24: superman.v1.SupermanService.AnalyzeEvents:output_type -> superman.v1.AnalyzeEventsResult
+	14, // 25: superman.v1.SupermanService.GetUserHistory:output_type -> superman.v1.UserHistory
+	23, // [23:26] is the sub-list for method output_type
+	20, // [20:23] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
+}
+
+func init() { file_superman_v1_superman_proto_init() }
+func file_superman_v1_superman_proto_init() {
+	if File_superman_v1_superman_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_superman_v1_superman_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UserIPAccessEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_superman_v1_superman_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Geography); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_superman_v1_superman_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IPAccess); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_superman_v1_superman_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TravelPath); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_superman_v1_superman_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConcurrentSessions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_superman_v1_superman_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProfileLocation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_superman_v1_superman_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UnfamiliarLocation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_superman_v1_superman_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UnusualHour); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_superman_v1_superman_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Superman); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_superman_v1_superman_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FieldViolation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_superman_v1_superman_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Error); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_superman_v1_superman_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AnalyzeEventsResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_superman_v1_superman_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetUserHistoryRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_superman_v1_superman_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UserProfile); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_superman_v1_superman_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UserHistory); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_superman_v1_superman_proto_msgTypes[11].OneofWrappers = []interface{}{
+		(*AnalyzeEventsResult_Superman)(nil),
+		(*AnalyzeEventsResult_Error)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_superman_v1_superman_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_superman_v1_superman_proto_goTypes,
+		DependencyIndexes: file_superman_v1_superman_proto_depIdxs,
+		MessageInfos:      file_superman_v1_superman_proto_msgTypes,
+	}.Build()
+	File_superman_v1_superman_proto = out.File
+	file_superman_v1_superman_proto_rawDesc = nil
+	file_superman_v1_superman_proto_goTypes = nil
+	file_superman_v1_superman_proto_depIdxs = nil
+}
@@ -0,0 +1,220 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package supermanpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// SupermanServiceClient is the client API for SupermanService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SupermanServiceClient interface {
+	// AnalyzeEvent stores the login event and compares it to the user's
+	// neighboring logins and profile.
+	AnalyzeEvent(ctx context.Context, in *UserIPAccessEvent, opts ...grpc.CallOption) (*Superman, error)
+	// AnalyzeEvents analyzes a stream of login events in the order received,
+	// answering each with its result. A rejected event does not end the
+	// stream.
+	AnalyzeEvents(ctx context.Context, opts ...grpc.CallOption) (SupermanService_AnalyzeEventsClient, error)
+	// GetUserHistory lists a user's stored login events, most recent first,
+	// along with the user's profile.
+	GetUserHistory(ctx context.Context, in *GetUserHistoryRequest, opts ...grpc.CallOption) (*UserHistory, error)
+}
+
+type supermanServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSupermanServiceClient(cc grpc.ClientConnInterface) SupermanServiceClient {
+	return &supermanServiceClient{cc}
+}
+
+func (c *supermanServiceClient) AnalyzeEvent(ctx context.Context, in *UserIPAccessEvent, opts ...grpc.CallOption) (*Superman, error) {
+	out := new(Superman)
+	err := c.cc.Invoke(ctx, "/superman.v1.SupermanService/AnalyzeEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *supermanServiceClient) AnalyzeEvents(ctx context.Context, opts ...grpc.CallOption) (SupermanService_AnalyzeEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SupermanService_ServiceDesc.Streams[0], "/superman.v1.SupermanService/AnalyzeEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &supermanServiceAnalyzeEventsClient{stream}
+	return x, nil
+}
+
+type SupermanService_AnalyzeEventsClient interface {
+	Send(*UserIPAccessEvent) error
+	Recv() (*AnalyzeEventsResult, error)
+	grpc.ClientStream
+}
+
+type supermanServiceAnalyzeEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *supermanServiceAnalyzeEventsClient) Send(m *UserIPAccessEvent) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *supermanServiceAnalyzeEventsClient) Recv() (*AnalyzeEventsResult, error) {
+	m := new(AnalyzeEventsResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *supermanServiceClient) GetUserHistory(ctx context.Context, in *GetUserHistoryRequest, opts ...grpc.CallOption) (*UserHistory, error) {
+	out := new(UserHistory)
+	err := c.cc.Invoke(ctx, "/superman.v1.SupermanService/GetUserHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SupermanServiceServer is the server API for SupermanService service.
+// All implementations must embed UnimplementedSupermanServiceServer
+// for forward compatibility
+type SupermanServiceServer interface {
+	// AnalyzeEvent stores the login event and compares it to the user's
+	// neighboring logins and profile.
+	AnalyzeEvent(context.Context, *UserIPAccessEvent) (*Superman, error)
+	// AnalyzeEvents analyzes a stream of login events in the order received,
+	// answering each with its result. A rejected event does not end the
+	// stream.
+	AnalyzeEvents(SupermanService_AnalyzeEventsServer) error
+	// GetUserHistory lists a user's stored login events, most recent first,
+	// along with the user's profile.
+	GetUserHistory(context.Context, *GetUserHistoryRequest) (*UserHistory, error)
+	mustEmbedUnimplementedSupermanServiceServer()
+}
+
+// UnimplementedSupermanServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedSupermanServiceServer struct {
+}
+
+func (UnimplementedSupermanServiceServer) AnalyzeEvent(context.Context, *UserIPAccessEvent) (*Superman, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnalyzeEvent not implemented")
+}
+func (UnimplementedSupermanServiceServer) AnalyzeEvents(SupermanService_AnalyzeEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method AnalyzeEvents not implemented")
+}
+func (UnimplementedSupermanServiceServer) GetUserHistory(context.Context, *GetUserHistoryRequest) (*UserHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserHistory not implemented")
+}
+func (UnimplementedSupermanServiceServer) mustEmbedUnimplementedSupermanServiceServer() {}
+
+// UnsafeSupermanServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SupermanServiceServer will
+// result in compilation errors.
+type UnsafeSupermanServiceServer interface {
+	mustEmbedUnimplementedSupermanServiceServer()
+}
+
+func RegisterSupermanServiceServer(s grpc.ServiceRegistrar, srv SupermanServiceServer) {
+	s.RegisterService(&SupermanService_ServiceDesc, srv)
+}
+
+func _SupermanService_AnalyzeEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserIPAccessEvent)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SupermanServiceServer).AnalyzeEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/superman.v1.SupermanService/AnalyzeEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SupermanServiceServer).AnalyzeEvent(ctx, req.(*UserIPAccessEvent))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SupermanService_AnalyzeEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SupermanServiceServer).AnalyzeEvents(&supermanServiceAnalyzeEventsServer{stream})
+}
+
+type SupermanService_AnalyzeEventsServer interface {
+	Send(*AnalyzeEventsResult) error
+	Recv() (*UserIPAccessEvent, error)
+	grpc.ServerStream
+}
+
+type supermanServiceAnalyzeEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *supermanServiceAnalyzeEventsServer) Send(m *AnalyzeEventsResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *supermanServiceAnalyzeEventsServer) Recv() (*UserIPAccessEvent, error) {
+	m := new(UserIPAccessEvent)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _SupermanService_GetUserHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SupermanServiceServer).GetUserHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/superman.v1.SupermanService/GetUserHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SupermanServiceServer).GetUserHistory(ctx, req.(*GetUserHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SupermanService_ServiceDesc is the grpc.ServiceDesc for SupermanService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SupermanService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "superman.v1.SupermanService",
+	HandlerType: (*SupermanServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AnalyzeEvent",
+			Handler:    _SupermanService_AnalyzeEvent_Handler,
+		},
+		{
+			MethodName: "GetUserHistory",
+			Handler:    _SupermanService_GetUserHistory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "AnalyzeEvents",
+			Handler:       _SupermanService_AnalyzeEvents_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "superman/v1/superman.proto",
+}
@@ -0,0 +1,140 @@
+package stream
+
+import (
+	"context"
+	"expvar"
+	"sync"
+
+	"github.com/txross1993/superman-api/models"
+)
+
+var metrics = expvar.NewMap("stream")
+
+// DefaultBuffer is the number of verdicts held for a subscriber unless
+// configured
+const DefaultBuffer = 64
+
+// Filter selects the verdicts a subscriber receives. Verdicts are only ever
+// received by subscribers of the same tenant; the zero Filter selects every
+// verdict of the default tenant
+type Filter struct {
+	Tenant         string
+	Username       string
+	SuspiciousOnly bool
+	MinScore       float64
+}
+
+// Match reports whether the verdict passes the filter
+func (f Filter) Match(verdict *models.Verdict) bool {
+	if verdict.Tenant != f.Tenant {
+		return false
+	}
+	if f.Username != "" && verdict.Username != f.Username {
+		return false
+	}
+	if f.SuspiciousOnly && !verdict.Suspicious {
+		return false
+	}
+	return verdict.Score >= f.MinScore
+}
+
+// Subscription receives the verdicts matching its filter on C. C is closed
+// when the subscription ends, either by Unsubscribe or because the
+// subscriber fell a full buffer behind, which Dropped then reports
+type Subscription struct {
+	C <-chan *models.Verdict
+
+	c       chan *models.Verdict
+	filter  Filter
+	mu      sync.Mutex
+	dropped bool
+}
+
+// Dropped reports whether the subscription was ended for falling behind
+func (s *Subscription) Dropped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// Broker fans verdicts out to subscribers without ever blocking the
+// publisher
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	buffer      int
+}
+
+// NewBroker creates a broker holding up to buffer verdicts for each
+// subscriber
+func NewBroker(buffer int) *Broker {
+	if buffer < 1 {
+		buffer = DefaultBuffer
+	}
+	return &Broker{
+		subscribers: map[*Subscription]struct{}{},
+		buffer:      buffer,
+	}
+}
+
+// Subscribe starts a subscription to the verdicts matching the filter
+func (b *Broker) Subscribe(filter Filter) *Subscription {
+	c := make(chan *models.Verdict, b.buffer)
+	sub := &Subscription{C: c, c: c, filter: filter}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	metrics.Add("subscribers", 1)
+	return sub
+}
+
+// Unsubscribe ends the subscription, closing its channel. Ending a
+// subscription which already ended does nothing
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.remove(sub)
+}
+
+// Notify publishes the verdicts to every subscriber whose filter they match.
+// A subscriber whose buffer is full is dropped rather than waited for
+func (b *Broker) Notify(ctx context.Context, verdicts []*models.Verdict) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers {
+		for _, verdict := range verdicts {
+			if !sub.filter.Match(verdict) {
+				continue
+			}
+
+			select {
+			case sub.c <- verdict:
+				metrics.Add("published", 1)
+				continue
+			default:
+			}
+
+			sub.mu.Lock()
+			sub.dropped = true
+			sub.mu.Unlock()
+			b.remove(sub)
+			metrics.Add("dropped_subscribers", 1)
+			break
+		}
+	}
+
+	return nil
+}
+
+// remove ends the subscription, expecting the broker lock to be held
+func (b *Broker) remove(sub *Subscription) {
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	close(sub.c)
+	metrics.Add("subscribers", -1)
+}
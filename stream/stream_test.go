@@ -0,0 +1,70 @@
+package stream
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/txross1993/superman-api/models"
+)
+
+func TestFilter(t *testing.T) {
+	verdict := &models.Verdict{Username: "bob", Suspicious: true, Score: 0.6}
+
+	tests := map[string]struct {
+		filter   Filter
+		expected bool
+	}{
+		"Zero Filter":         {filter: Filter{}, expected: true},
+		"Same Username":       {filter: Filter{Username: "bob"}, expected: true},
+		"Other Username":      {filter: Filter{Username: "alice"}},
+		"Suspicious Only":     {filter: Filter{SuspiciousOnly: true}, expected: true},
+		"Score At Minimum":    {filter: Filter{MinScore: 0.6}, expected: true},
+		"Score Below Minimum": {filter: Filter{MinScore: 0.7}},
+		"Other Tenant":        {filter: Filter{Tenant: "acme"}},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		assert.Equal(t, test.expected, test.filter.Match(verdict))
+	}
+
+	assert.False(t, Filter{SuspiciousOnly: true}.Match(&models.Verdict{Username: "bob"}))
+	assert.True(t, Filter{Tenant: "acme"}.Match(&models.Verdict{Tenant: "acme", Username: "bob"}))
+	assert.False(t, Filter{}.Match(&models.Verdict{Tenant: "acme", Username: "bob"}))
+}
+
+func TestBroker(t *testing.T) {
+	broker := NewBroker(2)
+	everything := broker.Subscribe(Filter{})
+	bob := broker.Subscribe(Filter{Username: "bob"})
+	slow := broker.Subscribe(Filter{})
+
+	verdicts := []*models.Verdict{{EventUUID: "a", Username: "bob"}, {EventUUID: "b", Username: "alice"}}
+	assert.NoError(t, broker.Notify(context.Background(), verdicts))
+
+	assert.Equal(t, "a", (<-everything.C).EventUUID)
+	assert.Equal(t, "b", (<-everything.C).EventUUID)
+	assert.Equal(t, "a", (<-bob.C).EventUUID)
+
+	// the slow subscriber's buffer is full, so it is dropped rather than
+	// blocking the publisher
+	assert.NoError(t, broker.Notify(context.Background(), []*models.Verdict{{EventUUID: "c", Username: "bob"}}))
+	assert.Equal(t, "c", (<-everything.C).EventUUID)
+	assert.Equal(t, "c", (<-bob.C).EventUUID)
+
+	received := 0
+	for range slow.C {
+		received++
+	}
+	assert.Equal(t, 2, received)
+	assert.True(t, slow.Dropped())
+	assert.False(t, everything.Dropped())
+
+	broker.Unsubscribe(everything)
+	broker.Unsubscribe(everything)
+	broker.Unsubscribe(slow)
+	_, open := <-everything.C
+	assert.False(t, open)
+	assert.False(t, everything.Dropped())
+}
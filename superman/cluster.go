@@ -0,0 +1,61 @@
+package superman
+
+import "github.com/txross1993/superman-api/models"
+
+// cluster is a group of nearby locations summarized by their centroid
+type cluster struct {
+	center *models.Geography
+	size   int
+}
+
+// add moves the centroid toward the new member location
+func (c *cluster) add(geo *models.Geography) {
+	c.size++
+	n := float64(c.size)
+	c.center.Latitude += (geo.Latitude - c.center.Latitude) / n
+	c.center.Longitude += (geo.Longitude - c.center.Longitude) / n
+	if geo.Radius > c.center.Radius {
+		c.center.Radius = geo.Radius
+	}
+}
+
+// clusterLocations assigns each location to the nearest cluster whose
+// centroid lies within radiusMiles, or within the location's own accuracy
+// radius if larger, starting a new cluster otherwise. Locations which are
+// nil are labelled -1
+func clusterLocations(geos []*models.Geography, radiusMiles float64) ([]int, []*cluster) {
+	labels := make([]int, len(geos))
+	var clusters []*cluster
+
+	for i, geo := range geos {
+		labels[i] = -1
+		if geo == nil {
+			continue
+		}
+
+		limit := radiusMiles
+		if accuracy := geo.AccuracyMiles(); accuracy > limit {
+			limit = accuracy
+		}
+
+		nearest, nearestMiles := -1, 0.0
+		for k, c := range clusters {
+			miles := c.center.MilesFrom(geo)
+			if miles <= limit && (nearest < 0 || miles < nearestMiles) {
+				nearest, nearestMiles = k, miles
+			}
+		}
+
+		if nearest < 0 {
+			center := *geo
+			clusters = append(clusters, &cluster{center: &center, size: 1})
+			labels[i] = len(clusters) - 1
+			continue
+		}
+
+		clusters[nearest].add(geo)
+		labels[i] = nearest
+	}
+
+	return labels, clusters
+}
@@ -0,0 +1,81 @@
+package superman
+
+import (
+	"context"
+	"sync"
+
+	"github.com/txross1993/superman-api/errors"
+	"github.com/txross1993/superman-api/models"
+)
+
+// maxConcurrentLookups bounds the geolocations made at once for one event
+const maxConcurrentLookups = 8
+
+// concurrently runs the tasks in parallel and waits for all of them,
+// returning the failures aggregated in the order of the tasks
+func concurrently(tasks ...func() error) error {
+	errs := make([]error, len(tasks))
+	var wg sync.WaitGroup
+	wg.Add(len(tasks))
+	for i, task := range tasks {
+		go func(i int, task func() error) {
+			defer wg.Done()
+			errs[i] = task()
+		}(i, task)
+	}
+	wg.Wait()
+
+	var multiple errors.Multiple
+	for _, err := range errs {
+		if err != nil {
+			multiple.Add(err)
+		}
+	}
+	return multiple.ErrorOrNil()
+}
+
+// geoencodeNeighbors geoencodes the neighboring ip access events not yet
+// located, looking up each distinct ip address once and at most
+// maxConcurrentLookups at a time. Neighbors sharing the address of the
+// current event or of a located neighbor take its geography, and the
+// current event is left untouched
+func (s *Service) geoencodeNeighbors(ctx context.Context, current *models.IPAccess, neighbors []*models.IPAccess) error {
+	located := map[string]*models.Geography{}
+	pending := map[string][]*models.IPAccess{}
+	var ips []string
+	for _, access := range append([]*models.IPAccess{current}, neighbors...) {
+		if access.Geography != nil {
+			located[access.IP] = access.Geography
+		}
+	}
+	for _, access := range neighbors {
+		if access.Geography != nil {
+			continue
+		}
+		if geo, ok := located[access.IP]; ok {
+			access.Geography = geo
+			continue
+		}
+		if _, ok := pending[access.IP]; !ok {
+			ips = append(ips, access.IP)
+		}
+		pending[access.IP] = append(pending[access.IP], access)
+	}
+
+	sem := make(chan struct{}, maxConcurrentLookups)
+	tasks := make([]func() error, len(ips))
+	for i, ip := range ips {
+		waiting := pending[ip]
+		tasks[i] = func() error {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			located, err := s.geoencode(ctx, &models.IPAccess{IP: waiting[0].IP})
+			for _, access := range waiting {
+				access.Geography = located.Geography
+			}
+			return err
+		}
+	}
+	return concurrently(tasks...)
+}
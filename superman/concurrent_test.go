@@ -0,0 +1,185 @@
+package superman
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/txross1993/superman-api/errors"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/testdata"
+)
+
+// TestSupermanConcurrentFailures tests that the failures of lookups made
+// concurrently are all reported, the first deciding the error code
+func TestSupermanConcurrentFailures(t *testing.T) {
+	start := testdata.TestCurrentTimestmap * 1000
+	history := []models.UserIPAccessEvent{
+		event("a", "10.0.0.1", start),
+		event("c", "10.0.0.3", start+2000),
+	}
+
+	tests := map[string]struct {
+		geo           geoservice
+		failNeighbors bool
+		expectedCode  errors.Code
+		expectedMsgs  []string
+	}{
+		"Storage And Geolocation": {
+			geo:           failingGeo{"10.0.0.2": true},
+			failNeighbors: true,
+			expectedCode:  errors.CodeGeolocationFailed,
+			expectedMsgs:  []string{"geolocation failed for 10.0.0.2", "find neighboring events"},
+		},
+		"Every Neighbor": {
+			geo:          failingGeo{"10.0.0.1": true, "10.0.0.3": true},
+			expectedCode: errors.CodeGeolocationFailed,
+			expectedMsgs: []string{"geolocation failed for 10.0.0.1", "geolocation failed for 10.0.0.3"},
+		},
+		"Single Failure Unwrapped": {
+			geo:          failingGeo{"10.0.0.3": true},
+			expectedCode: errors.CodeGeolocationFailed,
+			expectedMsgs: []string{"geolocation failed for 10.0.0.3"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		db := &failingDB{historyDB: &historyDB{events: append([]models.UserIPAccessEvent{}, history...)}, failNeighbors: test.failNeighbors}
+		superman := NewService(test.geo, db)
+
+		current := event("b", "10.0.0.2", start+1000)
+		_, err := superman.AnalyzeEvent(context.Background(), &current)
+		if assert.Error(t, err) {
+			assert.Equal(t, test.expectedCode, errors.CodeOf(err))
+			for _, msg := range test.expectedMsgs {
+				assert.Contains(t, err.Error(), msg)
+			}
+			_, aggregated := err.(*errors.Multiple)
+			assert.Equal(t, len(test.expectedMsgs) > 1, aggregated)
+		}
+	}
+}
+
+// BenchmarkAnalyzeEvent compares the analysis of an event with a full
+// window of neighbors when its storage and geolocation calls may overlap to
+// when every call waits for the previous one, as the sequential pipeline did
+func BenchmarkAnalyzeEvent(b *testing.B) {
+	const (
+		// storageLatency is a round trip to sqlite on local disk
+		storageLatency = 500 * time.Microsecond
+		// geoLatency is a lookup in a geolocation database shared with
+		// other processes
+		geoLatency = 250 * time.Microsecond
+	)
+
+	start := testdata.TestCurrentTimestmap * 1000
+	geo := mapGeo{}
+	var history []models.UserIPAccessEvent
+	for i := -DefaultPolicy().Window; i <= DefaultPolicy().Window; i++ {
+		ip := fmt.Sprintf("10.0.%d.1", i+10)
+		geo[ip] = &models.Geography{Latitude: 40 + float64(i)/10, Longitude: -100}
+		history = append(history, event(fmt.Sprintf("event-%d", i), ip, start+int64(i)*3600*1000))
+	}
+	current := history[DefaultPolicy().Window]
+
+	for name, serialized := range map[string]bool{"Concurrent": false, "Sequential": true} {
+		b.Run(name, func(b *testing.B) {
+			l := &latency{}
+			if serialized {
+				l.lock = &sync.Mutex{}
+			}
+			superman := NewService(
+				slowGeo{geo: geo, latency: l, delay: geoLatency},
+				&slowDB{historyDB: &historyDB{events: history}, latency: l, delay: storageLatency},
+			)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				replayed := current
+				if _, err := superman.AnalyzeEvent(context.Background(), &replayed); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// failingGeo fails to geoencode the ip addresses it holds
+type failingGeo map[string]bool
+
+func (f failingGeo) GetCoordinatesFromIP(ctx context.Context, ip string) (*models.Geography, error) {
+	if f[ip] {
+		return nil, fmt.Errorf("lookup %s: corrupt database", ip)
+	}
+	return &models.Geography{Latitude: 40, Longitude: -100}, nil
+}
+
+// failingDB fails neighbor queries when failNeighbors is set
+type failingDB struct {
+	*historyDB
+	failNeighbors bool
+}
+
+func (f *failingDB) FindNeighboringIPAccessEvents(ctx context.Context, e *models.UserIPAccessEvent, limit int) ([]models.UserIPAccessEvent, []models.UserIPAccessEvent, error) {
+	if f.failNeighbors {
+		return nil, nil, fmt.Errorf("disk I/O error")
+	}
+	return f.historyDB.FindNeighboringIPAccessEvents(ctx, e, limit)
+}
+
+// latency delays calls, holding lock across each call when set so that no
+// two calls overlap
+type latency struct {
+	lock *sync.Mutex
+}
+
+func (l *latency) wait(delay time.Duration) {
+	if l.lock != nil {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+	}
+	time.Sleep(delay)
+}
+
+// slowGeo geoencodes from a mapGeo after a delay
+type slowGeo struct {
+	geo     mapGeo
+	latency *latency
+	delay   time.Duration
+}
+
+func (s slowGeo) GetCoordinatesFromIP(ctx context.Context, ip string) (*models.Geography, error) {
+	s.latency.wait(s.delay)
+	return s.geo.GetCoordinatesFromIP(ctx, ip)
+}
+
+// slowDB serves every storage call from a historyDB after a delay
+type slowDB struct {
+	*historyDB
+	latency *latency
+	delay   time.Duration
+}
+
+func (s *slowDB) FindOrCreateUserIPAccessEvent(ctx context.Context, e *models.UserIPAccessEvent) (bool, error) {
+	s.latency.wait(s.delay)
+	return s.historyDB.FindOrCreateUserIPAccessEvent(ctx, e)
+}
+
+func (s *slowDB) FindUserProfile(ctx context.Context, tenant, username string) (*models.UserProfile, error) {
+	s.latency.wait(s.delay)
+	return s.historyDB.FindUserProfile(ctx, tenant, username)
+}
+
+func (s *slowDB) SaveUserProfile(ctx context.Context, profile *models.UserProfile) error {
+	s.latency.wait(s.delay)
+	return s.historyDB.SaveUserProfile(ctx, profile)
+}
+
+func (s *slowDB) FindNeighboringIPAccessEvents(ctx context.Context, e *models.UserIPAccessEvent, limit int) ([]models.UserIPAccessEvent, []models.UserIPAccessEvent, error) {
+	s.latency.wait(s.delay)
+	return s.historyDB.FindNeighboringIPAccessEvents(ctx, e, limit)
+}
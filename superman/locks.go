@@ -0,0 +1,45 @@
+package superman
+
+import (
+	"context"
+	"hash/fnv"
+
+	"github.com/txross1993/superman-api/errors"
+)
+
+// lockShards is the number of locks the usernames of every tenant are
+// spread over. Usernames sharing a shard are analyzed one at a time
+const lockShards = 256
+
+// userLocks serializes the analyses of each tenant's username, so that an
+// analysis finds every event of the user stored by the analyses before it
+// and profiles are updated one event at a time
+type userLocks struct {
+	shards []chan struct{}
+}
+
+func newUserLocks(n int) *userLocks {
+	l := &userLocks{shards: make([]chan struct{}, n)}
+	for i := range l.shards {
+		l.shards[i] = make(chan struct{}, 1)
+	}
+	return l
+}
+
+// lock waits for the analyses of the tenant's username to finish and
+// returns the function ending the caller's turn. Waiting ends with a timeout
+// once the context is done
+func (l *userLocks) lock(ctx context.Context, tenant, username string) (func(), error) {
+	h := fnv.New32a()
+	h.Write([]byte(tenant))
+	h.Write([]byte{0})
+	h.Write([]byte(username))
+	shard := l.shards[h.Sum32()%uint32(len(l.shards))]
+
+	select {
+	case shard <- struct{}{}:
+		return func() { <-shard }, nil
+	case <-ctx.Done():
+		return nil, &errors.Timeout{Op: "wait for analyses of the user", Err: ctx.Err()}
+	}
+}
@@ -0,0 +1,190 @@
+package superman
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/txross1993/superman-api/errors"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/testdata"
+)
+
+// TestSupermanSerializedAnalysis posts the interleaved events of several
+// users from many goroutines and checks that each analysis found the events
+// of every analysis of the user before it, and that no login went uncounted
+// in the user's profile
+func TestSupermanSerializedAnalysis(t *testing.T) {
+	const (
+		users      = 4
+		events     = 50
+		goroutines = 16
+	)
+
+	start := testdata.TestCurrentTimestmap * 1000
+	random := rand.New(rand.NewSource(1))
+	geo := mapGeo{}
+	var posted []models.UserIPAccessEvent
+	for u := 0; u < users; u++ {
+		for i, offset := range random.Perm(events) {
+			ip := fmt.Sprintf("10.0.%d.%d", u, i)
+			geo[ip] = &models.Geography{Latitude: 40 + float64(i)/100, Longitude: -100}
+			e := event(fmt.Sprintf("user-%d-event-%d", u, i), ip, start+int64(offset)*60*1000)
+			e.Username = fmt.Sprintf("user-%d", u)
+			posted = append(posted, e)
+		}
+	}
+	random.Shuffle(len(posted), func(i, j int) { posted[i], posted[j] = posted[j], posted[i] })
+
+	db := &lockedDB{historyDB: &historyDB{}}
+	order := &orderingNotifier{analyzed: map[string][]models.UserIPAccessEvent{}, events: map[string]models.UserIPAccessEvent{}}
+	for _, e := range posted {
+		order.events[e.EventUUID] = e
+	}
+	superman := NewService(slowGeo{geo: geo, latency: &latency{}, delay: 100 * time.Microsecond}, db, WithNotifier(order))
+
+	queue := make(chan models.UserIPAccessEvent, len(posted))
+	for _, e := range posted {
+		queue <- e
+	}
+	close(queue)
+
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for g := 0; g < goroutines; g++ {
+		go func() {
+			defer wg.Done()
+			for e := range queue {
+				if _, err := superman.AnalyzeEvent(context.Background(), &e); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Empty(t, order.violations)
+	for u := 0; u < users; u++ {
+		profile, err := superman.Profile(context.Background(), models.DefaultTenant, fmt.Sprintf("user-%d", u))
+		if assert.NoError(t, err) && assert.NotNil(t, profile) {
+			assert.Equal(t, int64(events), profile.LoginCount)
+		}
+	}
+}
+
+// TestUserLocksTimeout tests that waiting for the analyses of a user ends
+// with a timeout once the context is done
+func TestUserLocksTimeout(t *testing.T) {
+	locks := newUserLocks(1)
+	unlock, err := locks.lock(context.Background(), models.DefaultTenant, "alice")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = locks.lock(ctx, models.DefaultTenant, "bob")
+	assert.Equal(t, errors.CodeTimeout, errors.CodeOf(err))
+
+	unlock()
+	unlock, err = locks.lock(context.Background(), models.DefaultTenant, "bob")
+	if assert.NoError(t, err) {
+		unlock()
+	}
+}
+
+// lockedDB guards a historyDB so it may be called from many goroutines
+type lockedDB struct {
+	sync.Mutex
+	*historyDB
+}
+
+func (l *lockedDB) FindOrCreateUserIPAccessEvent(ctx context.Context, e *models.UserIPAccessEvent) (bool, error) {
+	l.Lock()
+	defer l.Unlock()
+	return l.historyDB.FindOrCreateUserIPAccessEvent(ctx, e)
+}
+
+func (l *lockedDB) FindUserProfile(ctx context.Context, tenant, username string) (*models.UserProfile, error) {
+	l.Lock()
+	defer l.Unlock()
+	return l.historyDB.FindUserProfile(ctx, tenant, username)
+}
+
+func (l *lockedDB) SaveUserProfile(ctx context.Context, profile *models.UserProfile) error {
+	l.Lock()
+	defer l.Unlock()
+	return l.historyDB.SaveUserProfile(ctx, profile)
+}
+
+func (l *lockedDB) ListUserIPAccessEvents(ctx context.Context, tenant, username string, beforeMillis int64, limit int) ([]models.UserIPAccessEvent, error) {
+	l.Lock()
+	defer l.Unlock()
+	return l.historyDB.ListUserIPAccessEvents(ctx, tenant, username, beforeMillis, limit)
+}
+
+func (l *lockedDB) FindNeighboringIPAccessEvents(ctx context.Context, e *models.UserIPAccessEvent, limit int) ([]models.UserIPAccessEvent, []models.UserIPAccessEvent, error) {
+	l.Lock()
+	defer l.Unlock()
+	return l.historyDB.FindNeighboringIPAccessEvents(ctx, e, limit)
+}
+
+// orderingNotifier checks, in the order analyses are notified, that the
+// neighbors of each analyzed event are the nearest events of the user
+// analyzed before it
+type orderingNotifier struct {
+	sync.Mutex
+	events     map[string]models.UserIPAccessEvent
+	analyzed   map[string][]models.UserIPAccessEvent
+	violations []string
+}
+
+func (o *orderingNotifier) Notify(ctx context.Context, verdicts []*models.Verdict) error {
+	o.Lock()
+	defer o.Unlock()
+
+	for _, verdict := range verdicts {
+		if verdict.Reevaluated() {
+			continue
+		}
+		current := o.events[verdict.EventUUID]
+
+		var preceding, subsequent *models.UserIPAccessEvent
+		for i := range o.analyzed[current.Username] {
+			e := &o.analyzed[current.Username][i]
+			if e.Precedes(&current) && (preceding == nil || preceding.Precedes(e)) {
+				preceding = e
+			}
+			if current.Precedes(e) && (subsequent == nil || e.Precedes(subsequent)) {
+				subsequent = e
+			}
+		}
+
+		if got, expected := accessUUID(verdict.Analysis.PrecedingIPAccess), eventUUID(preceding); got != expected {
+			o.violations = append(o.violations, fmt.Sprintf("%s preceded by %q, expected %q", current.EventUUID, got, expected))
+		}
+		if got, expected := accessUUID(verdict.Analysis.SubsequentIPAccess), eventUUID(subsequent); got != expected {
+			o.violations = append(o.violations, fmt.Sprintf("%s followed by %q, expected %q", current.EventUUID, got, expected))
+		}
+		o.analyzed[current.Username] = append(o.analyzed[current.Username], current)
+	}
+	return nil
+}
+
+func accessUUID(access *models.IPAccess) string {
+	if access == nil {
+		return ""
+	}
+	return access.EventUUID
+}
+
+func eventUUID(e *models.UserIPAccessEvent) string {
+	if e == nil {
+		return ""
+	}
+	return e.EventUUID
+}
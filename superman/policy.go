@@ -0,0 +1,289 @@
+package superman
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/txross1993/superman-api/models"
+)
+
+// Policy configures how the distance and time between two login events
+// are judged
+type Policy struct {
+	// MaxSpeedMPH is the travel speed at or above which a pair of logins
+	// is suspicious
+	MaxSpeedMPH int64 `json:"maxSpeedMph"`
+	// MinTimeWindow is the time between logins below which a speed is not
+	// computed and distance alone decides
+	MinTimeWindow time.Duration `json:"minTimeWindow"`
+	// MaxWindowDistanceMiles is the distance beyond which logins closer
+	// together in time than MinTimeWindow are suspicious
+	MaxWindowDistanceMiles float64 `json:"maxWindowDistanceMiles"`
+	// Window is the number of preceding and of subsequent events examined
+	// for pairs which violate the policy
+	Window int `json:"window"`
+	// ConcurrentSessionPeriod is the period within which logins alternating
+	// between distinct locations indicate concurrent sessions
+	ConcurrentSessionPeriod time.Duration `json:"concurrentSessionPeriod"`
+	// ClusterRadiusMiles is the distance within which logins are treated as
+	// the same location when looking for concurrent sessions
+	ClusterRadiusMiles float64 `json:"clusterRadiusMiles"`
+	// MinAlternations is the number of switches between locations within
+	// the period needed to report concurrent sessions
+	MinAlternations int `json:"minAlternations"`
+	// MinProfileLogins is the number of logins a user profile needs before
+	// logins far from every known location are flagged
+	MinProfileLogins int64 `json:"minProfileLogins"`
+	// FamiliarRadiusMiles is the distance from a known location within which
+	// a login is familiar
+	FamiliarRadiusMiles float64 `json:"familiarRadiusMiles"`
+	// MinHourHistory is the number of logins a user profile needs before
+	// logins at unusual local hours are flagged
+	MinHourHistory int64 `json:"minHourHistory"`
+	// MinHourConfidence is the confidence, between 0 and 1, that the user
+	// does not log in near an hour at which a login is flagged as unusual
+	MinHourConfidence float64 `json:"minHourConfidence"`
+	// AllowedNetworks are networks, such as vpn egresses, whose logins are
+	// never judged suspicious as their location says nothing about where
+	// the user is
+	AllowedNetworks []*net.IPNet `json:"-"`
+}
+
+// UnmarshalJSON decodes the fields present over the policy, reading
+// durations as strings such as "90s" and allowed networks as CIDR blocks
+// or single addresses. Unknown fields are rejected so that a misspelled
+// field is not silently left at its previous value
+func (p *Policy) UnmarshalJSON(data []byte) error {
+	type plain Policy
+	aux := struct {
+		*plain
+		MinTimeWindow           *duration `json:"minTimeWindow"`
+		ConcurrentSessionPeriod *duration `json:"concurrentSessionPeriod"`
+		AllowedNetworks         []string  `json:"allowedNetworks"`
+	}{plain: (*plain)(p)}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&aux); err != nil {
+		return err
+	}
+
+	if aux.MinTimeWindow != nil {
+		p.MinTimeWindow = time.Duration(*aux.MinTimeWindow)
+	}
+	if aux.ConcurrentSessionPeriod != nil {
+		p.ConcurrentSessionPeriod = time.Duration(*aux.ConcurrentSessionPeriod)
+	}
+	if aux.AllowedNetworks != nil {
+		networks, err := ParseNetworks(aux.AllowedNetworks)
+		if err != nil {
+			return err
+		}
+		p.AllowedNetworks = networks
+	}
+
+	return nil
+}
+
+// duration decodes a duration string
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(parsed)
+	return nil
+}
+
+// ParseNetworks parses CIDR blocks and single addresses into networks
+func ParseNetworks(values []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid network %q", value)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q: %v", value, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// allows reports whether the ip address lies in an allowed network
+func (p Policy) allows(ip string) bool {
+	if len(p.AllowedNetworks) == 0 {
+		return false
+	}
+
+	addr := net.ParseIP(ip)
+	for _, network := range p.AllowedNetworks {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultPolicy returns the policy applied unless configured. 500 MPH is
+// about the speed of a commercial airplane in flight
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxSpeedMPH:            500,
+		MinTimeWindow:          time.Minute,
+		MaxWindowDistanceMiles: 100,
+		Window:                 5,
+
+		ConcurrentSessionPeriod: time.Hour,
+		ClusterRadiusMiles:      50,
+		MinAlternations:         3,
+
+		MinProfileLogins:    10,
+		FamiliarRadiusMiles: 100,
+
+		MinHourHistory:    20,
+		MinHourConfidence: 0.95,
+	}
+}
+
+// Problems lists every field of the policy which is out of range, named by
+// its json name
+func (p Policy) Problems() []string {
+	var problems []string
+	check := func(ok bool, field, problem string) {
+		if !ok {
+			problems = append(problems, field+": "+problem)
+		}
+	}
+
+	check(p.MaxSpeedMPH > 0, "maxSpeedMph", "must be positive")
+	check(p.MinTimeWindow >= 0, "minTimeWindow", "must not be negative")
+	check(p.MaxWindowDistanceMiles >= 0, "maxWindowDistanceMiles", "must not be negative")
+	check(p.Window >= 1, "window", "must be at least 1")
+	check(p.ConcurrentSessionPeriod >= 0, "concurrentSessionPeriod", "must not be negative")
+	check(p.ClusterRadiusMiles > 0, "clusterRadiusMiles", "must be positive")
+	check(p.MinAlternations >= 1, "minAlternations", "must be at least 1")
+	check(p.MinProfileLogins >= 0, "minProfileLogins", "must not be negative")
+	check(p.FamiliarRadiusMiles > 0, "familiarRadiusMiles", "must be positive")
+	check(p.MinHourHistory >= 0, "minHourHistory", "must not be negative")
+	check(p.MinHourConfidence >= 0 && p.MinHourConfidence <= 1, "minHourConfidence", "must be between 0 and 1")
+	return problems
+}
+
+// window returns the number of neighboring events to examine on each side,
+// always at least the nearest
+func (p Policy) window() int {
+	if p.Window < 1 {
+		return 1
+	}
+	return p.Window
+}
+
+// ServiceOpt represents a functional option for configuring a Service
+type ServiceOpt func(s *Service)
+
+// WithPolicy provides the functional option for the base policy applied to
+// the events of every tenant without its own
+func WithPolicy(p Policy) ServiceOpt {
+	return func(s *Service) {
+		s.policies.base = p
+	}
+}
+
+// WithTenantPolicy provides the functional option for the policy applied to
+// the events of a tenant in place of the base policy
+func WithTenantPolicy(tenant string, p Policy) ServiceOpt {
+	return func(s *Service) {
+		if s.policies.tenants == nil {
+			s.policies.tenants = map[string]Policy{}
+		}
+		s.policies.tenants[tenant] = p
+	}
+}
+
+// policies holds the base policy and the policies of tenants overriding it,
+// which may be replaced while events are analyzed
+type policies struct {
+	mu      sync.RWMutex
+	base    Policy
+	tenants map[string]Policy
+}
+
+// forTenant returns the policy applied to the events of the tenant
+func (p *policies) forTenant(tenant string) Policy {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if policy, ok := p.tenants[tenant]; ok {
+		return policy
+	}
+	return p.base
+}
+
+// set replaces the base and tenant policies
+func (p *policies) set(base Policy, tenants map[string]Policy) {
+	copied := make(map[string]Policy, len(tenants))
+	for tenant, policy := range tenants {
+		copied[tenant] = policy
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.base = base
+	p.tenants = copied
+}
+
+// LoadTenantPolicies reads a json object mapping tenants to the policy
+// fields they override, each applied over the base policy. Fields which are
+// not policy fields, and overrides leaving a policy out of range, are
+// rejected
+func LoadTenantPolicies(r io.Reader, base Policy) (map[string]Policy, error) {
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	policies := make(map[string]Policy, len(raw))
+	for tenant, overrides := range raw {
+		if !models.ValidTenant(tenant) {
+			return nil, fmt.Errorf("invalid tenant %q", tenant)
+		}
+
+		policy := base
+		if err := json.Unmarshal(overrides, &policy); err != nil {
+			return nil, fmt.Errorf("tenant %q: %v", tenant, err)
+		}
+		if problems := policy.Problems(); len(problems) > 0 {
+			return nil, fmt.Errorf("tenant %q: %s", tenant, strings.Join(problems, "; "))
+		}
+		policies[tenant] = policy
+	}
+
+	return policies, nil
+}
@@ -0,0 +1,152 @@
+package superman
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/testdata"
+)
+
+func TestLoadTenantPolicies(t *testing.T) {
+	base := DefaultPolicy()
+
+	tests := map[string]struct {
+		input       string
+		expectedErr bool
+		check       func(t *testing.T, policies map[string]Policy)
+	}{
+		"Overrides Over Base": {
+			input: `{"acme": {"maxSpeedMph": 300, "minTimeWindow": "90s", "allowedNetworks": ["10.1.0.0/16", "192.0.2.7"]}, "globex": {}}`,
+			check: func(t *testing.T, policies map[string]Policy) {
+				acme := policies["acme"]
+				assert.Equal(t, int64(300), acme.MaxSpeedMPH)
+				assert.Equal(t, 90*time.Second, acme.MinTimeWindow)
+				assert.Equal(t, base.Window, acme.Window)
+				assert.Equal(t, 2, len(acme.AllowedNetworks))
+				assert.True(t, acme.allows("10.1.2.3"))
+				assert.True(t, acme.allows("192.0.2.7"))
+				assert.False(t, acme.allows("192.0.2.8"))
+
+				assert.Equal(t, base.MaxSpeedMPH, policies["globex"].MaxSpeedMPH)
+				assert.Nil(t, policies["globex"].AllowedNetworks)
+			},
+		},
+		"Invalid Tenant": {
+			input:       `{"no spaces": {}}`,
+			expectedErr: true,
+		},
+		"Invalid Network": {
+			input:       `{"acme": {"allowedNetworks": ["10.1.0.0/33"]}}`,
+			expectedErr: true,
+		},
+		"Invalid Duration": {
+			input:       `{"acme": {"minTimeWindow": "soon"}}`,
+			expectedErr: true,
+		},
+		"Unknown Field": {
+			input:       `{"acme": {"maxSped": 300}}`,
+			expectedErr: true,
+		},
+		"Out Of Range": {
+			input:       `{"acme": {"maxSpeedMph": 0}}`,
+			expectedErr: true,
+		},
+		"Negative Window": {
+			input:       `{"acme": {"window": -1}}`,
+			expectedErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		policies, err := LoadTenantPolicies(strings.NewReader(test.input), base)
+		if test.expectedErr {
+			assert.Error(t, err)
+			continue
+		}
+		if assert.NoError(t, err) {
+			test.check(t, policies)
+		}
+	}
+}
+
+// TestSupermanAllowedNetworks tests that logins from allowed networks are
+// neither judged nor learned
+func TestSupermanAllowedNetworks(t *testing.T) {
+	start := testdata.TestCurrentTimestmap * 1000
+	hour := int64(3600 * 1000)
+	geo := mapGeo{
+		"10.0.0.4": {Latitude: 45.4998, Longitude: -122.9586},
+		"10.0.0.5": {Latitude: 34.7725, Longitude: 113.7266},
+	}
+
+	policy := DefaultPolicy()
+	networks, err := ParseNetworks([]string{"10.0.0.5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy.AllowedNetworks = networks
+
+	db := &historyDB{events: []models.UserIPAccessEvent{event("a", "10.0.0.4", start)}}
+	superman := NewService(geo, db, WithPolicy(policy))
+
+	current := event("b", "10.0.0.5", start+hour)
+	resp, err := superman.AnalyzeEvent(context.Background(), &current)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.False(t, resp.TravelToSuspicious)
+	assert.Empty(t, resp.SuspiciousPaths)
+	if assert.NotNil(t, resp.PrecedingIPAccess) {
+		assert.Equal(t, models.AssessmentAllowed, resp.PrecedingIPAccess.Assessment)
+	}
+
+	profile, err := superman.Profile(context.Background(), models.DefaultTenant, testdata.TestUser)
+	assert.NoError(t, err)
+	assert.Nil(t, profile)
+}
+
+// TestSupermanSetPolicies tests that replaced policies apply to events
+// analyzed afterwards
+func TestSupermanSetPolicies(t *testing.T) {
+	start := testdata.TestCurrentTimestmap * 1000
+	hour := int64(3600 * 1000)
+	geo := mapGeo{
+		"10.0.0.4": {Latitude: 45.4998, Longitude: -122.9586},
+		"10.0.0.5": {Latitude: 34.7725, Longitude: 113.7266},
+	}
+
+	lenient := DefaultPolicy()
+	lenient.MaxSpeedMPH = 10000
+
+	db := &historyDB{events: []models.UserIPAccessEvent{event("a", "10.0.0.4", start)}}
+	superman := NewService(geo, db)
+
+	current := event("b", "10.0.0.5", start+hour)
+	resp, err := superman.AnalyzeEvent(context.Background(), &current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, resp.TravelToSuspicious)
+
+	superman.SetPolicies(lenient, nil)
+	current = event("b", "10.0.0.5", start+hour)
+	resp, err = superman.AnalyzeEvent(context.Background(), &current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, resp.TravelToSuspicious)
+
+	superman.SetPolicies(DefaultPolicy(), map[string]Policy{models.DefaultTenant: lenient})
+	current = event("b", "10.0.0.5", start+hour)
+	resp, err = superman.AnalyzeEvent(context.Background(), &current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, resp.TravelToSuspicious)
+}
@@ -0,0 +1,145 @@
+package superman
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/testdata"
+)
+
+// TestSupermanProfile tests that the user profile is built from analyzed
+// events and flags logins far from every known location
+func TestSupermanProfile(t *testing.T) {
+	start := testdata.TestCurrentTimestmap * 1000
+	day := int64(24 * 3600 * 1000)
+	geo := mapGeo{
+		"10.0.0.1": {Latitude: 40.0, Longitude: -100.0, Country: "US", TimeZone: "America/Chicago", ASN: 7018},
+		"10.0.0.2": {Latitude: 40.868, Longitude: -100.0, Country: "US", TimeZone: "America/Chicago", ASN: 7018},
+		"10.0.0.5": {Latitude: 34.7725, Longitude: 113.7266, Country: "CN", TimeZone: "Asia/Shanghai", ASN: 4134},
+	}
+
+	tests := map[string]struct {
+		historyLogins      int
+		currentIP          string
+		expectedUnfamiliar bool
+	}{
+		"Nearby Login Familiar": {
+			historyLogins: 10,
+			currentIP:     "10.0.0.2",
+		},
+		"Distant Login Unfamiliar": {
+			historyLogins:      10,
+			currentIP:          "10.0.0.5",
+			expectedUnfamiliar: true,
+		},
+		"Too Little History": {
+			historyLogins: 3,
+			currentIP:     "10.0.0.5",
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		db := &historyDB{}
+		superman := NewService(geo, db)
+
+		for i := 0; i < test.historyLogins; i++ {
+			past := event(fmt.Sprintf("history-%d", i), "10.0.0.1", start+int64(i)*day)
+			if _, err := superman.AnalyzeEvent(context.Background(), &past); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		current := event("current", test.currentIP, start+int64(test.historyLogins)*day)
+		resp, err := superman.AnalyzeEvent(context.Background(), &current)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if test.expectedUnfamiliar {
+			if assert.NotNil(t, resp.UnfamiliarLocation) {
+				assert.Equal(t, 1, resp.UnfamiliarLocation.KnownLocations)
+				assert.Equal(t, int64(test.historyLogins), resp.UnfamiliarLocation.ProfileLogins)
+				assert.True(t, resp.UnfamiliarLocation.NearestMiles > 5000)
+			}
+		} else {
+			assert.Nil(t, resp.UnfamiliarLocation)
+		}
+
+		profile, err := superman.Profile(context.Background(), models.DefaultTenant, testdata.TestUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, int64(test.historyLogins+1), profile.LoginCount)
+	}
+}
+
+// TestSupermanProfileReplay tests that an event analyzed more than once is
+// counted once in the profile
+func TestSupermanProfileReplay(t *testing.T) {
+	geo := mapGeo{"10.0.0.1": {Latitude: 40.0, Longitude: -100.0, Country: "US"}}
+	db := &historyDB{}
+	superman := NewService(geo, db)
+
+	for i := 0; i < 3; i++ {
+		replayed := event("replayed", "10.0.0.1", testdata.TestCurrentTimestmap*1000)
+		if _, err := superman.AnalyzeEvent(context.Background(), &replayed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	profile, err := superman.Profile(context.Background(), models.DefaultTenant, testdata.TestUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(1), profile.LoginCount)
+	assert.Equal(t, models.Counts{"US": 1}, profile.Countries)
+}
+
+// TestSupermanUnusualHour tests that a login at a local hour the user has
+// not logged in near is flagged once the history is large enough
+func TestSupermanUnusualHour(t *testing.T) {
+	start := testdata.TestCurrentTimestmap * 1000
+	hour := int64(3600 * 1000)
+	geo := mapGeo{"10.0.0.1": {Latitude: 40.0, Longitude: -100.0, TimeZone: "America/Chicago"}}
+
+	tests := map[string]struct {
+		historyLogins   int
+		offset          int64
+		expectedUnusual bool
+	}{
+		"Usual Hour":         {historyLogins: 25, offset: 0},
+		"Unusual Hour":       {historyLogins: 25, offset: 12 * hour, expectedUnusual: true},
+		"Too Little History": {historyLogins: 5, offset: 12 * hour},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		superman := NewService(geo, &historyDB{})
+
+		for i := 0; i < test.historyLogins; i++ {
+			past := event(fmt.Sprintf("history-%d", i), "10.0.0.1", start+int64(i)*24*hour)
+			if _, err := superman.AnalyzeEvent(context.Background(), &past); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		current := event("current", "10.0.0.1", start+int64(test.historyLogins)*24*hour+test.offset)
+		resp, err := superman.AnalyzeEvent(context.Background(), &current)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if test.expectedUnusual {
+			if assert.NotNil(t, resp.UnusualHour) {
+				assert.Equal(t, "America/Chicago", resp.UnusualHour.TimeZone)
+				assert.True(t, resp.UnusualHour.Confidence >= DefaultPolicy().MinHourConfidence)
+			}
+		} else {
+			assert.Nil(t, resp.UnusualHour)
+		}
+	}
+}
@@ -1,41 +1,128 @@
 package superman
 
 import (
+	"context"
 	"math"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/txross1993/superman-api/errors"
+	"github.com/txross1993/superman-api/logging"
 	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/tracing"
 )
 
 type database interface {
-	FindOrCreateUserIPAccessEvent(*models.UserIPAccessEvent) error
-	FindPrecedingIPAccessEvent(*models.UserIPAccessEvent) (*models.UserIPAccessEvent, error)
-	FindSubsequentIPAccessEvent(*models.UserIPAccessEvent) (*models.UserIPAccessEvent, error)
+	FindOrCreateUserIPAccessEvent(context.Context, *models.UserIPAccessEvent) (bool, error)
+	FindNeighboringIPAccessEvents(context.Context, *models.UserIPAccessEvent, int) ([]models.UserIPAccessEvent, []models.UserIPAccessEvent, error)
+	ListUserIPAccessEvents(context.Context, string, string, int64, int) ([]models.UserIPAccessEvent, error)
+	FindUserProfile(context.Context, string, string) (*models.UserProfile, error)
+	SaveUserProfile(context.Context, *models.UserProfile) error
 }
 
 type geoservice interface {
-	GetCoordinatesFromIP(string) (*models.Geography, error)
+	GetCoordinatesFromIP(context.Context, string) (*models.Geography, error)
 }
 
 // Service uses an ip geoencoder service and a persistence mechanism
-// to store, query, and analyze user ip access events
+// to store, query, and analyze user ip access events. Events are only ever
+// compared to events of the same tenant, under that tenant's policy, and the
+// events of each user are analyzed one at a time
 type Service struct {
-	geoSvc geoservice
-	db     database
+	geoSvc    geoservice
+	db        database
+	policies  *policies
+	policy    Policy
+	notifiers []notifier
+	locks     *userLocks
 }
 
 // NewService creates a new service instance to process user ip access
 // login events
-func NewService(geo geoservice, db database) *Service {
-	return &Service{
-		geoSvc: geo,
-		db:     db,
+func NewService(geo geoservice, db database, opts ...ServiceOpt) *Service {
+	s := &Service{
+		geoSvc:   geo,
+		db:       db,
+		policies: &policies{base: DefaultPolicy()},
+		locks:    newUserLocks(lockShards),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	s.policy = s.policies.base
+
+	return s
 }
 
 // AnalyzeEvent inspects the current user ip access login event and compares
 // the login event to prior and subsequent login events for the same user
-// to evaluate suspicious login activity
-func (s *Service) AnalyzeEvent(event *models.UserIPAccessEvent) (*models.Superman, error) {
+// to evaluate suspicious login activity. The verdicts are handed to every
+// configured notifier. Analyses of the same user wait for each other, so
+// each verdict reflects every event of the user analyzed before it
+func (s *Service) AnalyzeEvent(ctx context.Context, event *models.UserIPAccessEvent) (*models.Superman, error) {
+	ctx, span := tracing.Start(ctx, "superman.AnalyzeEvent",
+		attribute.String("event.uuid", event.EventUUID),
+		attribute.String("event.tenant", event.Tenant),
+		attribute.String("user.hash", tracing.HashUsername(event.Tenant, event.Username)),
+	)
+
+	var superman *models.Superman
+	unlock, err := s.locks.lock(ctx, event.Tenant, event.Username)
+	if err == nil {
+		superman, err = s.forTenant(event.Tenant).analyzeEvent(ctx, event)
+		unlock()
+	}
+	if err != nil {
+		span.SetAttributes(attribute.String("error.code", string(errors.CodeOf(err))))
+		tracing.End(span, err)
+		logAnalysisError(ctx, event, err)
+		return superman, err
+	}
+
+	span.SetAttributes(verdictAttributes(superman)...)
+	tracing.End(span, nil)
+	logging.FromContext(ctx).Debug("event analyzed", "event_uuid", event.EventUUID, "reasons", superman.Reasons())
+	return superman, nil
+}
+
+// verdictAttributes describes the verdict and the travel speeds it was
+// decided on for the span of an analysis
+func verdictAttributes(superman *models.Superman) []attribute.KeyValue {
+	reasons := superman.Reasons()
+	names := make([]string, len(reasons))
+	for i, reason := range reasons {
+		names[i] = string(reason)
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.Bool("verdict.suspicious", len(reasons) > 0),
+		attribute.Float64("verdict.score", models.Score(reasons)),
+		attribute.StringSlice("verdict.reasons", names),
+	}
+	if superman.PrecedingIPAccess != nil {
+		attrs = append(attrs, attribute.Int64("travel.preceding_speed_mph", superman.PrecedingIPAccess.Speed))
+	}
+	if superman.SubsequentIPAccess != nil {
+		attrs = append(attrs, attribute.Int64("travel.subsequent_speed_mph", superman.SubsequentIPAccess.Speed))
+	}
+	return attrs
+}
+
+// SetPolicies replaces the base and tenant policies applied to events
+// analyzed from now on
+func (s *Service) SetPolicies(base Policy, tenants map[string]Policy) {
+	s.policies.set(base, tenants)
+}
+
+// forTenant returns the service applying the tenant's current policy
+func (s *Service) forTenant(tenant string) *Service {
+	scoped := *s
+	scoped.policy = s.policies.forTenant(tenant)
+	return &scoped
+}
+
+func (s *Service) analyzeEvent(ctx context.Context, event *models.UserIPAccessEvent) (*models.Superman, error) {
 	var superman *models.Superman
 	var supermanOpts []models.SupermanOpt
 
@@ -44,106 +131,394 @@ func (s *Service) AnalyzeEvent(event *models.UserIPAccessEvent) (*models.Superma
 	}
 
 	// Inspect current event
-	if err := s.db.FindOrCreateUserIPAccessEvent(event); err != nil {
+	created, err := s.db.FindOrCreateUserIPAccessEvent(ctx, event)
+	if err != nil {
 		applyOpts()
-		return superman, err
+		if errors.CodeOf(err) != errors.CodeInternal {
+			return superman, err
+		}
+		return superman, storageError(ctx, "store event", err)
 	}
 	currentAccess := event.AsIPAccess()
-	currentGeoOpt, err := s.inspectCurrent(currentAccess)
+
+	// Geoencode the current event while looking up its neighbors
+	var preceding, subsequent []models.UserIPAccessEvent
+	err = concurrently(
+		func() error {
+			_, err := s.geoencode(ctx, currentAccess)
+			return err
+		},
+		func() (err error) {
+			if preceding, subsequent, err = s.db.FindNeighboringIPAccessEvents(ctx, event, s.policy.window()); err != nil {
+				return storageError(ctx, "find neighboring events", err)
+			}
+			return nil
+		},
+	)
 	if err != nil {
 		applyOpts()
 		return superman, err
 	}
-	supermanOpts = append(supermanOpts, currentGeoOpt)
+	supermanOpts = append(supermanOpts, models.WithCurrentGeo(currentAccess.Geography))
 
-	// Inspect preceding event
-	preceding, err := s.db.FindPrecedingIPAccessEvent(event)
+	// Compare the current event to the user's profile while geoencoding
+	// the neighbors
+	var profileOpts []models.SupermanOpt
+	precedingAccesses, subsequentAccesses := asIPAccesses(preceding), asIPAccesses(subsequent)
+	err = concurrently(
+		func() (err error) {
+			profileOpts, err = s.inspectProfile(ctx, currentAccess, event.Tenant, event.Username, created)
+			return err
+		},
+		func() error {
+			neighbors := append(append([]*models.IPAccess{}, precedingAccesses...), subsequentAccesses...)
+			return s.geoencodeNeighbors(ctx, currentAccess, neighbors)
+		},
+	)
 	if err != nil {
 		applyOpts()
 		return superman, err
 	}
+	supermanOpts = append(supermanOpts, profileOpts...)
 
-	if preceding != nil {
-		precedingIPAccess := preceding.AsIPAccess()
-		precedingOpt, err := s.inspectPreceding(currentAccess, precedingIPAccess)
-		if err != nil {
-			applyOpts()
-			return superman, err
+	// Compare the current event to its nearest neighbors
+	if len(precedingAccesses) > 0 {
+		supermanOpts = append(supermanOpts, models.WithPrecedingEvent(s.analyzeEventSequence(currentAccess, precedingAccesses[0])))
+	}
+	if len(subsequentAccesses) > 0 {
+		supermanOpts = append(supermanOpts, models.WithSubsequentEvent(s.analyzeEventSequence(currentAccess, subsequentAccesses[0])))
+	}
+
+	// Inspect every pair of events in the surrounding window
+	supermanOpts = append(supermanOpts, s.inspectWindow(currentAccess, precedingAccesses, subsequentAccesses)...)
+
+	applyOpts()
+
+	// Alert on suspicious verdicts, including any neighbor they changed. The
+	// event is stored by now, so a failure is not reported as storage
+	if err := s.notify(ctx, verdicts(event, superman)); err != nil {
+		if ctx.Err() != nil {
+			return superman, &errors.Timeout{Op: "publish verdicts", Err: ctx.Err()}
 		}
-		supermanOpts = append(supermanOpts, precedingOpt)
+		return superman, &errors.PublishFailed{Op: "publish verdicts", Err: err}
 	}
 
-	// Inspect subsequent event
-	subsequent, err := s.db.FindSubsequentIPAccessEvent(event)
+	return superman, nil
+}
+
+// inspectProfile flags the current ip access event if it lies far from every
+// location in the user's profile or falls at an unusual local hour, and
+// records newly stored events in the profile so that replayed events are
+// counted once. Events from allowed networks say nothing about the user's
+// habits so are neither flagged nor recorded
+func (s *Service) inspectProfile(ctx context.Context, current *models.IPAccess, tenant, username string, created bool) ([]models.SupermanOpt, error) {
+	if s.policy.allows(current.IP) {
+		return nil, nil
+	}
+
+	profile, err := s.db.FindUserProfile(ctx, tenant, username)
 	if err != nil {
-		applyOpts()
-		return superman, err
+		return nil, storageError(ctx, "find user profile", err)
+	}
+	if profile == nil {
+		profile = models.NewUserProfile(tenant, username)
 	}
 
-	if subsequent != nil {
-		subsequentAccess := subsequent.AsIPAccess()
-		subsequentOpt, err := s.inspectSubsequentEvent(currentAccess, subsequentAccess)
-		if err != nil {
-			applyOpts()
-			return superman, err
+	unfamiliar := profile.Unfamiliar(current.Geography, s.policy.MinProfileLogins, s.policy.FamiliarRadiusMiles)
+	unusual := profile.UnusualHour(current.Geography, current.TimestampMillis, s.policy.MinHourHistory, s.policy.MinHourConfidence)
+
+	if created {
+		profile.Record(current.Geography, current.TimestampMillis, s.policy.ClusterRadiusMiles)
+		if err := s.db.SaveUserProfile(ctx, profile); err != nil {
+			return nil, storageError(ctx, "save user profile", err)
 		}
-		supermanOpts = append(supermanOpts, subsequentOpt)
+	}
 
+	return []models.SupermanOpt{
+		models.WithUnfamiliarLocation(unfamiliar),
+		models.WithUnusualHour(unusual),
+	}, nil
+}
+
+// Profile retrieves the location and habit baseline for the tenant's
+// username, or nil if no events have been analyzed for it
+func (s *Service) Profile(ctx context.Context, tenant, username string) (*models.UserProfile, error) {
+	profile, err := s.db.FindUserProfile(ctx, tenant, username)
+	if err != nil {
+		return nil, storageError(ctx, "find user profile", err)
 	}
+	return profile, nil
+}
 
-	applyOpts()
-	return superman, nil
+// History retrieves up to limit of the stored events of the tenant's
+// username which occurred before beforeMillis, most recent first, or the
+// most recent events when beforeMillis is zero
+func (s *Service) History(ctx context.Context, tenant, username string, beforeMillis int64, limit int) ([]models.UserIPAccessEvent, error) {
+	events, err := s.db.ListUserIPAccessEvents(ctx, tenant, username, beforeMillis, limit)
+	if err != nil {
+		return nil, storageError(ctx, "list events", err)
+	}
+	return events, nil
 }
 
-// inspectCurrent geoencodes the current ip access event and provides the
-// builder option for the Superman response
-func (s *Service) inspectCurrent(current *models.IPAccess) (models.SupermanOpt, error) {
-	current, err := s.geoencode(current)
-	return models.WithCurrentGeo(current.Geography), err
+// inspectWindow finds the paths through the current event in the time
+// ordered window in which any pair of located events violates the travel
+// policy, and any concurrent sessions from distinct locations
+func (s *Service) inspectWindow(current *models.IPAccess, preceding, subsequent []*models.IPAccess) []models.SupermanOpt {
+	sequence := make([]*models.IPAccess, 0, len(preceding)+len(subsequent)+1)
+	for i := len(preceding) - 1; i >= 0; i-- {
+		sequence = append(sequence, preceding[i])
+	}
+	sequence = append(sequence, current)
+	sequence = append(sequence, subsequent...)
+
+	return []models.SupermanOpt{
+		models.WithSuspiciousPaths(s.suspiciousPaths(sequence, len(preceding))),
+		models.WithConcurrentSessions(s.concurrentSessions(sequence, len(preceding))),
+	}
 }
 
-// inspectPreceding geoencodes the preceding ip access event and determines
-// the distance and speed from the current ip access event
-func (s *Service) inspectPreceding(current, preceding *models.IPAccess) (models.SupermanOpt, error) {
-	preceding, err := s.geoencode(preceding)
-	preceding = s.analyzeEventSequence(current, preceding)
-	return models.WithPrecedingEvent(preceding), err
+// suspiciousPaths assesses every pair of events in the time ordered
+// sequence and merges the overlapping spans of violating pairs into paths.
+// Only the paths through the current event are reported, so a violation
+// among earlier events doesn't flag each later login which reaches it
+func (s *Service) suspiciousPaths(sequence []*models.IPAccess, current int) []*models.TravelPath {
+	type span struct {
+		from, to int
+		worst    travel
+	}
+
+	var spans []*span
+	for i := 0; i < len(sequence); i++ {
+		for j := i + 1; j < len(sequence); j++ {
+			t := s.assessTravel(sequence[i], sequence[j])
+			if !t.suspicious {
+				continue
+			}
+
+			// pairs arrive ordered by their first event so a pair either
+			// extends the latest span or starts a new one
+			if n := len(spans); n > 0 && i <= spans[n-1].to {
+				last := spans[n-1]
+				if j > last.to {
+					last.to = j
+				}
+				if t.severity > last.worst.severity {
+					last.worst = t
+				}
+				continue
+			}
+			spans = append(spans, &span{from: i, to: j, worst: t})
+		}
+	}
+
+	paths := make([]*models.TravelPath, 0, len(spans))
+	for _, sp := range spans {
+		if current < sp.from || current > sp.to {
+			continue
+		}
+		path := &models.TravelPath{
+			Speed:      sp.worst.speed,
+			Distance:   sp.worst.distance,
+			Assessment: sp.worst.assessment,
+		}
+		for k := sp.from; k <= sp.to; k++ {
+			hop := *sequence[k]
+			hop.Speed, hop.Distance, hop.Assessment, hop.Suspicious = 0, 0, "", false
+			if k > sp.from {
+				t := s.assessTravel(sequence[k-1], sequence[k])
+				hop.Speed, hop.Distance, hop.Assessment, hop.Suspicious = t.speed, t.distance, t.assessment, t.suspicious
+			}
+			path.Hops = append(path.Hops, &hop)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths
 }
 
-// inspectSubsequentEvent geoencodes the subsequent ip access event and determines
-// the distance and speed from the current ip access event
-func (s *Service) inspectSubsequentEvent(current, subsequent *models.IPAccess) (models.SupermanOpt, error) {
-	subsequent, err := s.geoencode(subsequent)
-	subsequent = s.analyzeEventSequence(current, subsequent)
-	return models.WithSubsequentEvent(subsequent), err
+// concurrentSessions clusters the locations of the time ordered sequence
+// and finds the span including the current event, no longer than the
+// concurrent session period, in which the user alternates most often
+// between clusters. A span qualifies when it alternates at least the
+// policy minimum and returns to a location it left, distinguishing
+// concurrent sessions from a user travelling onward
+func (s *Service) concurrentSessions(sequence []*models.IPAccess, current int) *models.ConcurrentSessions {
+	if s.policy.MinAlternations < 1 || s.policy.ConcurrentSessionPeriod <= 0 {
+		return nil
+	}
+
+	// events from allowed networks are left unclustered
+	geos := make([]*models.Geography, len(sequence))
+	for i, access := range sequence {
+		if !s.policy.allows(access.IP) {
+			geos[i] = access.Geography
+		}
+	}
+	labels, clusters := clusterLocations(geos, s.policy.ClusterRadiusMiles)
+	if len(clusters) < 2 {
+		return nil
+	}
+
+	period := int64(s.policy.ConcurrentSessionPeriod / time.Millisecond)
+	bestFrom, bestTo, bestAlternations := -1, -1, 0
+	for i := 0; i <= current; i++ {
+		alternations, last := 0, -1
+		seen := map[int]bool{}
+		for j := i; j < len(sequence) && sequence[j].TimestampMillis-sequence[i].TimestampMillis <= period; j++ {
+			if labels[j] < 0 {
+				continue
+			}
+			if last >= 0 && labels[j] != last {
+				alternations++
+			}
+			last = labels[j]
+			seen[labels[j]] = true
+
+			returned := alternations+1 > len(seen)
+			if j >= current && returned && alternations >= s.policy.MinAlternations && alternations > bestAlternations {
+				bestFrom, bestTo, bestAlternations = i, j, alternations
+			}
+		}
+	}
+
+	if bestFrom < 0 {
+		return nil
+	}
+
+	sessions := &models.ConcurrentSessions{
+		Alternations:         bestAlternations,
+		FirstTimestampMillis: sequence[bestFrom].TimestampMillis,
+		LastTimestampMillis:  sequence[bestTo].TimestampMillis,
+	}
+	included := map[int]bool{}
+	for k := bestFrom; k <= bestTo; k++ {
+		event := *sequence[k]
+		event.Speed, event.Distance, event.Assessment, event.Suspicious = 0, 0, "", false
+		sessions.Events = append(sessions.Events, &event)
+
+		if label := labels[k]; label >= 0 && !included[label] {
+			included[label] = true
+			sessions.Locations = append(sessions.Locations, clusters[label].center)
+		}
+	}
+
+	return sessions
 }
 
 // analyzeEventSequence compares the current event to an alternate event
-// to determine the distance and speed of access between events
+// to determine the distance and speed of access between events and whether
+// the travel between them is suspicious under the service policy
 func (s *Service) analyzeEventSequence(current, alt *models.IPAccess) *models.IPAccess {
 	if alt == nil {
 		return nil
 	}
 
-	var distanceMiles float64
-	if alt.Geography != nil {
-		distanceMiles = current.Geography.MilesFrom(alt.Geography)
+	t := s.assessTravel(current, alt)
+	alt.Speed = t.speed
+	alt.Distance = t.distance
+	alt.Assessment = t.assessment
+	alt.Suspicious = t.suspicious
+	return alt
+}
+
+// travel holds the judgement of the travel between two ip access events.
+// severity is the ratio of the deciding measure to its policy threshold
+type travel struct {
+	speed      int64
+	distance   float64
+	assessment models.TravelAssessment
+	suspicious bool
+	severity   float64
+}
+
+// assessTravel judges the travel between two ip access events under the
+// service policy
+func (s *Service) assessTravel(from, to *models.IPAccess) travel {
+	var t travel
+	if s.policy.allows(from.IP) || s.policy.allows(to.IP) {
+		t.assessment = models.AssessmentAllowed
+		return t
+	}
+	if from.Geography == nil || to.Geography == nil {
+		t.assessment = models.AssessmentUnknown
+		return t
+	}
+
+	distanceMiles := from.Geography.MilesFrom(to.Geography)
+	t.distance = math.Round(distanceMiles)
+
+	timedelta := calculateTimedelta(from.TimestampMillis, to.TimestampMillis)
+	switch {
+	case from.IP == to.IP || t.distance == 0:
+		t.assessment = models.AssessmentCoLocated
+	case timedelta == 0 || time.Duration(timedelta)*time.Millisecond < s.policy.MinTimeWindow:
+		t.assessment = models.AssessmentDistance
+		t.suspicious = t.distance > s.policy.MaxWindowDistanceMiles
+		t.severity = ratio(t.distance, s.policy.MaxWindowDistanceMiles)
+	default:
+		t.assessment = models.AssessmentSpeed
+		t.speed = calculateSpeedMPH(distanceMiles, timedelta)
+		t.suspicious = t.speed >= s.policy.MaxSpeedMPH
+		t.severity = ratio(float64(t.speed), float64(s.policy.MaxSpeedMPH))
 	}
 
-	timedelta := calculateTimedelta(current.Timestamp, alt.Timestamp)
-	alt.Speed = calculateSpeedMPH(distanceMiles, timedelta)
-	return alt
+	return t
 }
 
-// geoencode applies the geoencoding service to the ip access event ip address
-func (s *Service) geoencode(event *models.IPAccess) (*models.IPAccess, error) {
-	geo, err := s.geoSvc.GetCoordinatesFromIP(event.IP)
+// geoencode applies the geoencoding service to the ip access event ip address.
+// Failures which do not already carry an error code are reported as
+// geolocation failures, or as timeouts once the context is done
+func (s *Service) geoencode(ctx context.Context, event *models.IPAccess) (*models.IPAccess, error) {
+	geo, err := s.geoSvc.GetCoordinatesFromIP(ctx, event.IP)
 	event.Geography = geo
+	if err != nil && errors.CodeOf(err) == errors.CodeInternal {
+		if ctx.Err() != nil {
+			err = &errors.Timeout{Op: "geolocate " + event.IP, Err: ctx.Err()}
+		} else {
+			err = &errors.GeolocationFailed{IP: event.IP, Err: err}
+		}
+	}
 	return event, err
 }
 
-// calculateTimedelta expects two unix epoch timestamps and returns the absolute
-// value of the difference
+// storageError reports a failed persistence operation, or a timeout when
+// the context ended it
+func storageError(ctx context.Context, op string, err error) error {
+	if ctx.Err() != nil {
+		return &errors.Timeout{Op: op, Err: ctx.Err()}
+	}
+	return &errors.StorageUnavailable{Op: op, Err: err}
+}
+
+// logAnalysisError logs the failed analysis of the event. Failures of the
+// service are errors while rejected events are warnings
+func logAnalysisError(ctx context.Context, event *models.UserIPAccessEvent, err error) {
+	level := logging.LevelWarn
+	switch errors.CodeOf(err) {
+	case errors.CodeInternal, errors.CodeStorageUnavailable, errors.CodePublishFailed, errors.CodeGeolocationFailed:
+		level = logging.LevelError
+	}
+
+	logging.FromContext(ctx).Log(level, "analysis failed",
+		"event_uuid", event.EventUUID,
+		"tenant", event.Tenant,
+		"code", errors.CodeOf(err),
+		"error", err,
+	)
+}
+
+// asIPAccesses translates stored events to ip access events
+func asIPAccesses(events []models.UserIPAccessEvent) []*models.IPAccess {
+	accesses := make([]*models.IPAccess, len(events))
+	for i := range events {
+		accesses[i] = events[i].AsIPAccess()
+	}
+	return accesses
+}
+
+// calculateTimedelta expects two unix epoch timestamps of the same resolution
+// and returns the absolute value of the difference
 func calculateTimedelta(t1, t2 int64) int64 {
 	delta := t1 - t2
 	if delta < 0 {
@@ -152,12 +527,21 @@ func calculateTimedelta(t1, t2 int64) int64 {
 	return delta
 }
 
-// calculateSpeedMPH expects distance in miles and timedelta in seconds to
+// calculateSpeedMPH expects distance in miles and timedelta in milliseconds to
 // calculate miles per hour
 func calculateSpeedMPH(distance float64, timedelta int64) int64 {
 	if timedelta == 0 {
 		return math.MaxInt64
 	}
-	milesPerSecond := math.Round(distance) / float64(timedelta)
-	return int64(milesPerSecond * 3600)
+	milesPerMilli := math.Round(distance) / float64(timedelta)
+	return int64(milesPerMilli * float64(time.Hour/time.Millisecond))
+}
+
+// ratio divides the measure by its threshold, treating a zero threshold as
+// infinitely exceeded
+func ratio(measure, threshold float64) float64 {
+	if threshold <= 0 {
+		return math.Inf(1)
+	}
+	return measure / threshold
 }
@@ -1,8 +1,11 @@
 package superman
 
 import (
+	"context"
 	"math"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/txross1993/superman-api/models"
@@ -14,6 +17,7 @@ func TestSuperman(t *testing.T) {
 
 	tests := map[string]struct {
 		testParams             testParams
+		policy                 Policy
 		expectedFromSupsicious bool
 		expectedToSupsicious   bool
 	}{
@@ -48,6 +52,26 @@ func TestSuperman(t *testing.T) {
 			expectedFromSupsicious: true,
 			expectedToSupsicious:   true,
 		},
+		"Same Timestamp Same Location": {
+			testParams: testParams{
+				sameTimestamp: true,
+				coLocated:     true,
+			},
+			expectedFromSupsicious: false,
+			expectedToSupsicious:   false,
+		},
+		"Same Timestamp Nearby": {
+			testParams: testParams{
+				sameTimestamp: true,
+			},
+			policy: Policy{
+				MaxSpeedMPH:            500,
+				MinTimeWindow:          time.Minute,
+				MaxWindowDistanceMiles: 1000,
+			},
+			expectedFromSupsicious: false,
+			expectedToSupsicious:   true,
+		},
 		"Suspicious Preceding": {
 			testParams: testParams{
 				suspiciousPreceding: true,
@@ -71,9 +95,13 @@ func TestSuperman(t *testing.T) {
 	for name, test := range tests {
 		t.Logf("Running test case: %s", name)
 		db := &mockDB{test.testParams}
-		superman := NewService(geo, db)
+		policy := test.policy
+		if reflect.DeepEqual(policy, Policy{}) {
+			policy = DefaultPolicy()
+		}
+		superman := NewService(geo, db, WithPolicy(policy))
 
-		resp, err := superman.AnalyzeEvent(testEvent)
+		resp, err := superman.AnalyzeEvent(context.Background(), testEvent)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -85,6 +113,87 @@ func TestSuperman(t *testing.T) {
 		t.Logf("Response: %v", resp)
 		assert.Equal(t, test.expectedFromSupsicious, resp.TravelFromSuspicious)
 		assert.Equal(t, test.expectedToSupsicious, resp.TravelToSuspicious)
+		for _, access := range []*models.IPAccess{resp.PrecedingIPAccess, resp.SubsequentIPAccess} {
+			if access != nil {
+				assert.NotEqual(t, int64(math.MaxInt64), access.Speed)
+				if test.testParams.sameTimestamp {
+					assert.NotEqual(t, models.AssessmentSpeed, access.Assessment)
+				}
+			}
+		}
+	}
+}
+
+// TestSupermanTenants tests that a username's events are compared only to
+// events of the same tenant, under that tenant's policy
+func TestSupermanTenants(t *testing.T) {
+	start := testdata.TestCurrentTimestmap * 1000
+	hour := int64(3600 * 1000)
+	geo := mapGeo{
+		"10.0.0.4": {Latitude: 45.4998, Longitude: -122.9586},
+		"10.0.0.5": {Latitude: 34.7725, Longitude: 113.7266},
+	}
+
+	tenantEvent := func(tenant, uuid, ip string, ms int64) models.UserIPAccessEvent {
+		e := event(uuid, ip, ms)
+		e.Tenant = tenant
+		return e
+	}
+
+	lenient := DefaultPolicy()
+	lenient.MaxSpeedMPH = 10000
+
+	tests := map[string]struct {
+		history            []models.UserIPAccessEvent
+		current            models.UserIPAccessEvent
+		expectedPreceding  string
+		expectedSuspicious bool
+	}{
+		"Same Tenant": {
+			history:            []models.UserIPAccessEvent{tenantEvent("acme", "a", "10.0.0.4", start)},
+			current:            tenantEvent("acme", "b", "10.0.0.5", start+hour),
+			expectedPreceding:  "10.0.0.4",
+			expectedSuspicious: true,
+		},
+		"Other Tenant": {
+			history: []models.UserIPAccessEvent{tenantEvent("acme", "a", "10.0.0.4", start)},
+			current: tenantEvent("globex", "b", "10.0.0.5", start+hour),
+		},
+		"Default Tenant": {
+			history: []models.UserIPAccessEvent{tenantEvent("acme", "a", "10.0.0.4", start)},
+			current: tenantEvent(models.DefaultTenant, "b", "10.0.0.5", start+hour),
+		},
+		"Tenant Policy": {
+			history:           []models.UserIPAccessEvent{tenantEvent("lenient", "a", "10.0.0.4", start)},
+			current:           tenantEvent("lenient", "b", "10.0.0.5", start+hour),
+			expectedPreceding: "10.0.0.4",
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		db := &historyDB{events: test.history}
+		superman := NewService(geo, db, WithTenantPolicy("lenient", lenient))
+
+		current := test.current
+		resp, err := superman.AnalyzeEvent(context.Background(), &current)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, test.expectedSuspicious, resp.TravelToSuspicious)
+		if test.expectedPreceding == "" {
+			assert.Nil(t, resp.PrecedingIPAccess)
+		} else if assert.NotNil(t, resp.PrecedingIPAccess) {
+			assert.Equal(t, test.expectedPreceding, resp.PrecedingIPAccess.IP)
+		}
+
+		profile, err := superman.Profile(context.Background(), current.Tenant, current.Username)
+		assert.NoError(t, err)
+		if assert.NotNil(t, profile) {
+			assert.Equal(t, current.Tenant, profile.Tenant)
+			assert.Equal(t, int64(1), profile.LoginCount)
+		}
 	}
 }
 
@@ -104,9 +213,14 @@ func TestSupermanUtils(t *testing.T) {
 			},
 			"default": {
 				distance:  3600,
-				timedelta: 3600,
+				timedelta: 3600000,
 				want:      3600,
 			},
+			"sub-second": {
+				distance:  1,
+				timedelta: 500,
+				want:      7200,
+			},
 		}
 		for name, test := range tests {
 			t.Logf("Running test case: %s", name)
@@ -147,7 +261,7 @@ func TestSupermanUtils(t *testing.T) {
 
 type mockGeo struct{}
 
-func (m *mockGeo) GetCoordinatesFromIP(ip string) (*models.Geography, error) {
+func (m *mockGeo) GetCoordinatesFromIP(ctx context.Context, ip string) (*models.Geography, error) {
 	switch ip {
 	case testdata.TestCurrentIP:
 		return &models.Geography{
@@ -177,6 +291,7 @@ type testParams struct {
 	noPreceding          bool
 	noSubsequent         bool
 	sameTimestamp        bool
+	coLocated            bool
 	suspiciousPreceding  bool
 	suspiciousSubsequent bool
 }
@@ -185,16 +300,43 @@ type mockDB struct {
 	testParams
 }
 
-func (m *mockDB) FindOrCreateUserIPAccessEvent(e *models.UserIPAccessEvent) error {
+func (m *mockDB) FindOrCreateUserIPAccessEvent(ctx context.Context, e *models.UserIPAccessEvent) (bool, error) {
+	return true, nil
+}
+
+func (m *mockDB) FindUserProfile(ctx context.Context, tenant, username string) (*models.UserProfile, error) {
+	return nil, nil
+}
+
+func (m *mockDB) SaveUserProfile(ctx context.Context, profile *models.UserProfile) error {
 	return nil
 }
 
+func (m *mockDB) ListUserIPAccessEvents(ctx context.Context, tenant, username string, beforeMillis int64, limit int) ([]models.UserIPAccessEvent, error) {
+	return nil, nil
+}
+
+func (m *mockDB) FindNeighboringIPAccessEvents(ctx context.Context, e *models.UserIPAccessEvent, limit int) ([]models.UserIPAccessEvent, []models.UserIPAccessEvent, error) {
+	var preceding, subsequent []models.UserIPAccessEvent
+	if event, _ := m.FindPrecedingIPAccessEvent(e); event != nil {
+		preceding = append(preceding, *event)
+	}
+	if event, _ := m.FindSubsequentIPAccessEvent(e); event != nil {
+		subsequent = append(subsequent, *event)
+	}
+	return preceding, subsequent, nil
+}
+
 func (m *mockDB) FindPrecedingIPAccessEvent(e *models.UserIPAccessEvent) (*models.UserIPAccessEvent, error) {
 	switch {
 	case m.validPreceding:
 		return testdata.GeneratePreviousEvent(false, false), nil
 	case m.noPreceding:
 		return nil, nil
+	case m.sameTimestamp && m.coLocated:
+		preceding := testdata.GeneratePreviousEvent(false, true)
+		preceding.IPAddress = testdata.TestCurrentIP
+		return preceding, nil
 	case m.sameTimestamp:
 		return testdata.GeneratePreviousEvent(false, true), nil
 	case m.suspiciousPreceding:
@@ -209,6 +351,10 @@ func (m *mockDB) FindSubsequentIPAccessEvent(e *models.UserIPAccessEvent) (*mode
 		return testdata.GenerateSubsequentEvent(false, false), nil
 	case m.noSubsequent:
 		return nil, nil
+	case m.sameTimestamp && m.coLocated:
+		subsequent := testdata.GenerateSubsequentEvent(false, true)
+		subsequent.IPAddress = testdata.TestCurrentIP
+		return subsequent, nil
 	case m.sameTimestamp:
 		return testdata.GenerateSubsequentEvent(false, true), nil
 	case m.suspiciousSubsequent:
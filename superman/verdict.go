@@ -0,0 +1,66 @@
+package superman
+
+import (
+	"context"
+
+	"github.com/txross1993/superman-api/models"
+)
+
+type notifier interface {
+	Notify(context.Context, []*models.Verdict) error
+}
+
+// WithNotifier provides the functional option adding to Service.notifiers,
+// which are each handed the verdicts of every analysis
+func WithNotifier(n notifier) ServiceOpt {
+	return func(s *Service) {
+		s.notifiers = append(s.notifiers, n)
+	}
+}
+
+// verdicts returns the verdict on the analyzed event followed by the verdict
+// on its subsequent event when the analyzed event makes travel to the
+// subsequent event suspicious. Travel from the preceding event is already
+// judged by the verdict on the analyzed event
+func verdicts(event *models.UserIPAccessEvent, resp *models.Superman) []*models.Verdict {
+	reasons := resp.Reasons()
+	current := &models.Verdict{
+		EventUUID:       event.EventUUID,
+		Tenant:          event.Tenant,
+		Username:        event.Username,
+		IP:              event.IPAddress,
+		TimestampMillis: event.Millis(),
+		Suspicious:      len(reasons) > 0,
+		Score:           models.Score(reasons),
+		Reasons:         reasons,
+		Analysis:        resp,
+	}
+	result := []*models.Verdict{current}
+
+	if subsequent := resp.SubsequentIPAccess; subsequent != nil && subsequent.Suspicious {
+		reasons := []models.Reason{models.ReasonTravelTo}
+		result = append(result, &models.Verdict{
+			EventUUID:        subsequent.EventUUID,
+			Tenant:           event.Tenant,
+			Username:         event.Username,
+			IP:               subsequent.IP,
+			TimestampMillis:  subsequent.TimestampMillis,
+			Suspicious:       true,
+			Score:            models.Score(reasons),
+			Reasons:          reasons,
+			TriggerEventUUID: event.EventUUID,
+		})
+	}
+
+	return result
+}
+
+// notify hands the verdicts to every configured notifier
+func (s *Service) notify(ctx context.Context, verdicts []*models.Verdict) error {
+	for _, n := range s.notifiers {
+		if err := n.Notify(ctx, verdicts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
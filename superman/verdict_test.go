@@ -0,0 +1,89 @@
+package superman
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/testdata"
+)
+
+// TestSupermanNotifications tests that verdicts, including those on
+// neighbors changed by an out of order event, reach the notifier
+func TestSupermanNotifications(t *testing.T) {
+	start := testdata.TestCurrentTimestmap * 1000
+	hour := int64(3600 * 1000)
+	geo := mapGeo{
+		"10.0.0.4": {Latitude: 45.4998, Longitude: -122.9586},
+		"10.0.0.5": {Latitude: 34.7725, Longitude: 113.7266},
+		"10.0.0.6": {Latitude: 45.5, Longitude: -122.95},
+	}
+
+	tests := map[string]struct {
+		history          []models.UserIPAccessEvent
+		current          models.UserIPAccessEvent
+		expectedVerdicts map[string][]models.Reason
+		expectedTriggers map[string]string
+	}{
+		"In Order": {
+			history: []models.UserIPAccessEvent{event("a", "10.0.0.4", start)},
+			current: event("b", "10.0.0.5", start+hour),
+			expectedVerdicts: map[string][]models.Reason{
+				"b": {models.ReasonTravelTo, models.ReasonSuspiciousPath},
+			},
+		},
+		"Out Of Order": {
+			history: []models.UserIPAccessEvent{event("b", "10.0.0.5", start+hour)},
+			current: event("a", "10.0.0.4", start),
+			expectedVerdicts: map[string][]models.Reason{
+				"a": {models.ReasonTravelFrom, models.ReasonSuspiciousPath},
+				"b": {models.ReasonTravelTo},
+			},
+			expectedTriggers: map[string]string{"b": "a"},
+		},
+		"Not Suspicious": {
+			history: []models.UserIPAccessEvent{event("a", "10.0.0.4", start)},
+			current: event("b", "10.0.0.6", start+hour),
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		notified := &recordingNotifier{}
+		superman := NewService(geo, &historyDB{events: test.history}, WithNotifier(notified))
+
+		current := test.current
+		if _, err := superman.AnalyzeEvent(context.Background(), &current); err != nil {
+			t.Fatal(err)
+		}
+
+		if assert.NotEmpty(t, notified.verdicts) {
+			assert.Equal(t, current.EventUUID, notified.verdicts[0].EventUUID)
+			assert.NotNil(t, notified.verdicts[0].Analysis)
+		}
+
+		suspicious := 0
+		for _, verdict := range notified.verdicts {
+			if !verdict.Suspicious {
+				assert.Equal(t, 0.0, verdict.Score)
+				continue
+			}
+			suspicious++
+			assert.Equal(t, test.expectedVerdicts[verdict.EventUUID], verdict.Reasons)
+			assert.Equal(t, test.expectedTriggers[verdict.EventUUID], verdict.TriggerEventUUID)
+			assert.Equal(t, models.Score(verdict.Reasons), verdict.Score)
+		}
+		assert.Equal(t, len(test.expectedVerdicts), suspicious)
+	}
+}
+
+// recordingNotifier keeps every verdict it is handed
+type recordingNotifier struct {
+	verdicts []*models.Verdict
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, verdicts []*models.Verdict) error {
+	r.verdicts = append(r.verdicts, verdicts...)
+	return nil
+}
@@ -0,0 +1,296 @@
+package superman
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/txross1993/superman-api/models"
+	"github.com/txross1993/superman-api/testdata"
+)
+
+// TestSupermanWindow tests detection of policy violations between events
+// which are not adjacent to each other
+func TestSupermanWindow(t *testing.T) {
+	start := testdata.TestCurrentTimestmap * 1000
+	geo := mapGeo{
+		// points 60 miles apart along a meridian
+		"10.0.0.1": {Latitude: 40.0, Longitude: -100.0},
+		"10.0.0.2": {Latitude: 40.868, Longitude: -100.0},
+		"10.0.0.3": {Latitude: 41.736, Longitude: -100.0},
+		// oregon and china, 5858 miles apart
+		"10.0.0.4": {Latitude: 45.4998, Longitude: -122.9586},
+		"10.0.0.5": {Latitude: 34.7725, Longitude: 113.7266},
+	}
+
+	tests := map[string]struct {
+		history      []models.UserIPAccessEvent
+		current      models.UserIPAccessEvent
+		policy       Policy
+		expectedHops [][]string
+	}{
+		"Chain Within Time Window": {
+			history: []models.UserIPAccessEvent{
+				event("a", "10.0.0.1", start),
+				event("c", "10.0.0.3", start+40*1000),
+			},
+			current:      event("b", "10.0.0.2", start+20*1000),
+			expectedHops: [][]string{{"10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+		},
+		"Ungeolocated Event Between": {
+			history: []models.UserIPAccessEvent{
+				event("a", "10.0.0.4", start),
+				event("c", "10.0.0.5", start+2*3600*1000),
+			},
+			current:      event("b", "10.0.0.9", start+3600*1000),
+			expectedHops: [][]string{{"10.0.0.4", "10.0.0.9", "10.0.0.5"}},
+		},
+		"Outside Window": {
+			history: []models.UserIPAccessEvent{
+				event("a", "10.0.0.4", start),
+				event("b", "10.0.0.6", start+1000),
+				event("d", "10.0.0.5", start+2*3600*1000),
+			},
+			current: event("c", "10.0.0.7", start+3600*1000),
+			policy: Policy{
+				MaxSpeedMPH:            500,
+				MinTimeWindow:          time.Minute,
+				MaxWindowDistanceMiles: 100,
+				Window:                 1,
+			},
+		},
+		"Overlapping Pairs Merged": {
+			history: []models.UserIPAccessEvent{
+				event("a", "10.0.0.4", start),
+				event("b", "10.0.0.5", start+3600*1000),
+				event("d", "10.0.0.5", start+3*3600*1000),
+			},
+			current:      event("c", "10.0.0.4", start+2*3600*1000),
+			expectedHops: [][]string{{"10.0.0.4", "10.0.0.5", "10.0.0.4", "10.0.0.5"}},
+		},
+		"Earlier Violation Behind Clean Login": {
+			history: []models.UserIPAccessEvent{
+				event("a", "10.0.0.4", start),
+				event("b", "10.0.0.5", start+3600*1000),
+			},
+			current: event("c", "10.0.0.5", start+49*3600*1000),
+		},
+		"Unrelated Events": {
+			history: []models.UserIPAccessEvent{
+				event("a", "10.0.0.4", start),
+			},
+			current: event("b", "10.0.0.5", start+24*3600*1000),
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		policy := test.policy
+		if reflect.DeepEqual(policy, Policy{}) {
+			policy = DefaultPolicy()
+		}
+		superman := NewService(geo, &historyDB{events: test.history}, WithPolicy(policy))
+
+		current := test.current
+		resp, err := superman.AnalyzeEvent(context.Background(), &current)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var gotHops [][]string
+		for _, path := range resp.SuspiciousPaths {
+			var ips []string
+			for _, hop := range path.Hops {
+				ips = append(ips, hop.IP)
+			}
+			gotHops = append(gotHops, ips)
+		}
+		assert.Equal(t, test.expectedHops, gotHops)
+		assert.Equal(t, len(test.expectedHops) > 0, containsReason(resp.Reasons(), models.ReasonSuspiciousPath))
+	}
+}
+
+func containsReason(reasons []models.Reason, reason models.Reason) bool {
+	for _, r := range reasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// TestSupermanConcurrentSessions tests detection of logins alternating
+// between distinct locations
+func TestSupermanConcurrentSessions(t *testing.T) {
+	start := testdata.TestCurrentTimestmap * 1000
+	minute := int64(60 * 1000)
+	geo := mapGeo{
+		// points 60 miles apart along a meridian
+		"10.0.0.1": {Latitude: 40.0, Longitude: -100.0},
+		"10.0.0.2": {Latitude: 40.868, Longitude: -100.0},
+		"10.0.0.3": {Latitude: 41.736, Longitude: -100.0},
+		// a few miles from 10.0.0.1
+		"10.0.0.4": {Latitude: 40.02, Longitude: -100.02},
+	}
+
+	tests := map[string]struct {
+		history              []models.UserIPAccessEvent
+		current              models.UserIPAccessEvent
+		expectedAlternations int
+		expectedLocations    int
+	}{
+		"Alternating Locations": {
+			history: []models.UserIPAccessEvent{
+				event("a", "10.0.0.1", start),
+				event("b", "10.0.0.2", start+15*minute),
+				event("c", "10.0.0.4", start+30*minute),
+			},
+			current:              event("d", "10.0.0.2", start+45*minute),
+			expectedAlternations: 3,
+			expectedLocations:    2,
+		},
+		"Travelling Onward": {
+			history: []models.UserIPAccessEvent{
+				event("a", "10.0.0.1", start),
+				event("b", "10.0.0.2", start+15*minute),
+			},
+			current: event("c", "10.0.0.3", start+30*minute),
+		},
+		"Alternating Slowly": {
+			history: []models.UserIPAccessEvent{
+				event("a", "10.0.0.1", start),
+				event("b", "10.0.0.2", start+60*minute),
+				event("c", "10.0.0.1", start+120*minute),
+			},
+			current: event("d", "10.0.0.2", start+180*minute),
+		},
+		"Single Location": {
+			history: []models.UserIPAccessEvent{
+				event("a", "10.0.0.1", start),
+				event("b", "10.0.0.4", start+15*minute),
+			},
+			current: event("c", "10.0.0.1", start+30*minute),
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		superman := NewService(geo, &historyDB{events: test.history})
+
+		current := test.current
+		resp, err := superman.AnalyzeEvent(context.Background(), &current)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if test.expectedAlternations == 0 {
+			assert.Nil(t, resp.ConcurrentSessions)
+			continue
+		}
+
+		if assert.NotNil(t, resp.ConcurrentSessions) {
+			assert.Equal(t, test.expectedAlternations, resp.ConcurrentSessions.Alternations)
+			assert.Equal(t, test.expectedLocations, len(resp.ConcurrentSessions.Locations))
+			assert.False(t, resp.TravelToSuspicious)
+		}
+	}
+}
+
+// event creates a test user ip access event at the epoch millisecond
+func event(uuid, ip string, ms int64) models.UserIPAccessEvent {
+	e := models.UserIPAccessEvent{
+		EventUUID: uuid,
+		Username:  testdata.TestUser,
+		IPAddress: ip,
+	}
+	e.SetMillis(ms)
+	return e
+}
+
+// mapGeo geoencodes the ip addresses it holds and no others
+type mapGeo map[string]*models.Geography
+
+func (m mapGeo) GetCoordinatesFromIP(ctx context.Context, ip string) (*models.Geography, error) {
+	if geo, ok := m[ip]; ok {
+		copied := *geo
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+// historyDB serves neighbor queries from an in memory user history
+type historyDB struct {
+	events   []models.UserIPAccessEvent
+	profiles map[string]models.UserProfile
+}
+
+func (h *historyDB) FindOrCreateUserIPAccessEvent(ctx context.Context, e *models.UserIPAccessEvent) (bool, error) {
+	for _, existing := range h.events {
+		if existing.Tenant == e.Tenant && existing.EventUUID == e.EventUUID {
+			*e = existing
+			return false, nil
+		}
+	}
+	h.events = append(h.events, *e)
+	sort.Slice(h.events, func(i, j int) bool { return h.events[i].Precedes(&h.events[j]) })
+	return true, nil
+}
+
+func (h *historyDB) FindUserProfile(ctx context.Context, tenant, username string) (*models.UserProfile, error) {
+	profile, ok := h.profiles[tenant+"/"+username]
+	if !ok {
+		return nil, nil
+	}
+	return &profile, nil
+}
+
+func (h *historyDB) SaveUserProfile(ctx context.Context, profile *models.UserProfile) error {
+	if h.profiles == nil {
+		h.profiles = map[string]models.UserProfile{}
+	}
+	h.profiles[profile.Tenant+"/"+profile.Username] = *profile
+	return nil
+}
+
+func (h *historyDB) ListUserIPAccessEvents(ctx context.Context, tenant, username string, beforeMillis int64, limit int) ([]models.UserIPAccessEvent, error) {
+	var events []models.UserIPAccessEvent
+	for i := len(h.events) - 1; i >= 0 && len(events) < limit; i-- {
+		if h.events[i].Tenant == tenant && h.events[i].Username == username && (beforeMillis == 0 || h.events[i].Millis() < beforeMillis) {
+			events = append(events, h.events[i])
+		}
+	}
+	return events, nil
+}
+
+func (h *historyDB) FindNeighboringIPAccessEvents(ctx context.Context, e *models.UserIPAccessEvent, limit int) ([]models.UserIPAccessEvent, []models.UserIPAccessEvent, error) {
+	return h.findPreceding(e, limit), h.findSubsequent(e, limit), nil
+}
+
+func (h *historyDB) findPreceding(e *models.UserIPAccessEvent, limit int) []models.UserIPAccessEvent {
+	var preceding []models.UserIPAccessEvent
+	for i := len(h.events) - 1; i >= 0 && len(preceding) < limit; i-- {
+		if h.sameUser(h.events[i], e) && h.events[i].Precedes(e) {
+			preceding = append(preceding, h.events[i])
+		}
+	}
+	return preceding
+}
+
+func (h *historyDB) findSubsequent(e *models.UserIPAccessEvent, limit int) []models.UserIPAccessEvent {
+	var subsequent []models.UserIPAccessEvent
+	for i := 0; i < len(h.events) && len(subsequent) < limit; i++ {
+		if h.sameUser(h.events[i], e) && e.Precedes(&h.events[i]) {
+			subsequent = append(subsequent, h.events[i])
+		}
+	}
+	return subsequent
+}
+
+// sameUser reports whether the events belong to the same username of the
+// same tenant
+func (h *historyDB) sameUser(a models.UserIPAccessEvent, b *models.UserIPAccessEvent) bool {
+	return a.Tenant == b.Tenant && a.Username == b.Username
+}
@@ -4,6 +4,7 @@ import (
 	crand "crypto/rand"
 	"fmt"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/txross1993/superman-api/models"
@@ -17,7 +18,10 @@ const (
 	TestCurrentTimestmap = int64(1514764800)
 )
 
-var r *rand.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+var (
+	r   *rand.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	rMu sync.Mutex
+)
 
 // GenerateCurrentEvent creates a current test user ip access event
 func GenerateCurrentEvent() *models.UserIPAccessEvent {
@@ -83,7 +87,11 @@ func subsequentTS(currentTS int64, suspicious bool, instantaneous bool) int64 {
 	return currentTS + (supsiciousThreshold + timeDelta)
 }
 
+// getRandTimeDelta is safe for concurrent use as neighbors are generated
+// by concurrent lookups
 func getRandTimeDelta(max int64) int64 {
+	rMu.Lock()
+	defer rMu.Unlock()
 	return r.Int63n(max)
 }
 
@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestSetup(t *testing.T) {
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+
+	tests := map[string]struct {
+		exporter    string
+		expectedErr string
+		exported    bool
+	}{
+		"None":    {exporter: ExporterNone},
+		"Stdout":  {exporter: ExporterStdout, exported: true},
+		"Unknown": {exporter: "zipkin", expectedErr: `unknown trace exporter "zipkin"`},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+		var out bytes.Buffer
+		shutdown, err := Setup(context.Background(), Options{Exporter: test.exporter, SampleRatio: 1, ServiceName: "superman-api", Stdout: &out})
+		if test.expectedErr != "" {
+			if assert.Error(t, err) {
+				assert.Equal(t, test.expectedErr, err.Error())
+			}
+			continue
+		}
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		_, span := Start(context.Background(), "test.span")
+		End(span, errors.New("boom"))
+		assert.NoError(t, shutdown(context.Background()))
+
+		assert.Equal(t, test.exported, strings.Contains(out.String(), `"Name":"test.span"`))
+		assert.Equal(t, test.exported, strings.Contains(out.String(), "boom"))
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	attrs := Attributes("event_uuid", "abc", "found", 2, "created", true, "odd")
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("event_uuid", "abc"),
+		attribute.Int("found", 2),
+		attribute.Bool("created", true),
+	}, attrs)
+}
+
+func TestHashUsername(t *testing.T) {
+	hash := HashUsername("", "bob")
+	assert.Equal(t, hash, HashUsername("", "bob"))
+	assert.NotEqual(t, hash, HashUsername("acme", "bob"))
+	assert.NotContains(t, hash, "bob")
+	assert.Equal(t, 16, len(hash))
+}